@@ -0,0 +1,373 @@
+// Package analyzer provides a go/analysis analyzer checking the code of named
+// users: accessors generated by generate-named that no longer match the
+// struct tags, annotated structs without generated code, and Field structs
+// never linked. It runs with go vet through cmd/named-vet:
+//
+//	go install github.com/alvarolm/named/cmd/named-vet@latest
+//	go vet -vettool=$(which named-vet) ./...
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const namedPath = "github.com/alvarolm/named"
+
+// Analyzer reports, in each package:
+//   - the generated accessor methods returning another name than the tag of
+//     their field (the named tag, or else the tag of the TagKey of the
+//     directive, the first one naming it of a chain like json|yaml|field, or
+//     its Rename option), without the fallback names of untagged fields
+//   - the structs with a GENERATE-NAMED comment directive but no generated
+//     code in the package (directives of the configuration file, or
+//     generating into another package, are not checked)
+//   - the unexported structs with named.Field members never linked by
+//     named.LoadLink or named.RegisterSchema, nor nested in another one
+var Analyzer = &analysis.Analyzer{
+	Name: "named",
+	Doc:  "check the accessors generated by generate-named and the linking of named.Field structs",
+	URL:  "https://pkg.go.dev/github.com/alvarolm/named/analyzer",
+	Run:  run,
+}
+
+// directive holds the options of a GENERATE-NAMED directive checked by the
+// analyzer
+type directive struct {
+	tagKey    string
+	tagFormat string
+	output    string
+	varSuffix string
+	exclude   []string          // of a wildcard directive
+	rename    map[string]string // names of the top level fields, by Go name
+}
+
+// parseDirective parses the options of a directive, e.g.
+// "StructName:User,TagKey:db", returning its struct name
+func parseDirective(text string) (string, directive) {
+	var structName string
+	d := directive{tagKey: "json", varSuffix: "Named"}
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "StructName":
+			structName = value
+		case "TagKey":
+			d.tagKey = value
+		case "TagFormat":
+			d.tagFormat = value
+		case "Output":
+			d.output = value
+		case "VarSuffix":
+			d.varSuffix = value
+		case "Exclude":
+			d.exclude = strings.Split(value, "|")
+		case "Rename":
+			d.rename = make(map[string]string)
+			for _, rename := range strings.Split(value, "|") {
+				if goName, name, ok := strings.Cut(rename, "="); ok {
+					d.rename[goName] = name
+				}
+			}
+		}
+	}
+	return structName, d
+}
+
+// directives returns the comment directives of the files, by struct name (or
+// "*" for the wildcard one)
+func directives(files []*ast.File) map[string]directive {
+	dirs := make(map[string]directive)
+	for _, file := range files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+				if rest, ok := strings.CutPrefix(text, "GENERATE-NAMED="); ok {
+					if structName, d := parseDirective(rest); structName != "" {
+						dirs[structName] = d
+					}
+				}
+			}
+		}
+
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil && len(genDecl.Specs) == 1 {
+					doc = genDecl.Doc
+				}
+				if doc == nil {
+					continue
+				}
+				for _, comment := range doc.List {
+					text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+					rest, ok := strings.CutPrefix(text, "GENERATE-NAMED")
+					if ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+						_, dirs[typeSpec.Name.Name] = parseDirective(rest)
+					}
+				}
+			}
+		}
+	}
+	return dirs
+}
+
+// lookup returns the directive of the struct structName, its own one or the
+// wildcard one
+func lookup(dirs map[string]directive, structName string) (directive, bool) {
+	if d, ok := dirs[structName]; ok {
+		return d, true
+	}
+	d, ok := dirs["*"]
+	if !ok || !ast.IsExported(structName) {
+		return directive{}, false
+	}
+	for _, excluded := range d.exclude {
+		if strings.TrimSpace(excluded) == structName {
+			return directive{}, false
+		}
+	}
+	return d, true
+}
+
+// isGenerated reports whether file, named filename, is generated by
+// generate-named: by its default suffix, or else its header (other -suffix
+// or Output file name)
+func isGenerated(filename string, file *ast.File) bool {
+	if strings.HasSuffix(filename, "_named_generated.go") || strings.HasSuffix(filename, "_named_generated_test.go") {
+		return true
+	}
+	return len(file.Comments) > 0 && file.Comments[0].List[0].Text == "// Code generated by generate-named. DO NOT EDIT."
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	dirs := directives(pass.Files)
+
+	// identifiers declared by the generated files
+	generated := make(map[string]bool)
+	generatedFiles := make(map[string]bool)
+	for _, file := range pass.Files {
+		filename := pass.Fset.Position(file.Package).Filename
+		if !isGenerated(filename, file) {
+			continue
+		}
+		generatedFiles[filename] = true
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							generated[name.Name] = true
+						}
+					case *ast.TypeSpec:
+						generated[spec.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					generated[decl.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	scope := pass.Pkg.Scope()
+	for _, structName := range scope.Names() {
+		typeName, ok := scope.Lookup(structName).(*types.TypeName)
+		if !ok || generatedFiles[pass.Fset.Position(typeName.Pos()).Filename] {
+			continue
+		}
+		structType, ok := typeName.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		d, ok := lookup(dirs, structName)
+		if !ok || strings.Contains(d.output, "/") {
+			continue
+		}
+
+		if !hasGenerated(generated, structName, d) {
+			pass.Reportf(typeName.Pos(), "struct %s has a GENERATE-NAMED directive but no generated code, run generate-named", structName)
+			continue
+		}
+		checkAccessors(pass, structName, structType, d)
+	}
+
+	checkLinked(pass)
+	return nil, nil
+}
+
+// hasGenerated reports whether the generated identifiers include the
+// accessor of the struct structName (e.g. UserNamed) or its constants (e.g.
+// UserName_Email)
+func hasGenerated(generated map[string]bool, structName string, d directive) bool {
+	if generated[structName+d.varSuffix] {
+		return true
+	}
+	for name := range generated {
+		if strings.HasPrefix(name, structName+"Name_") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccessors reports the accessor methods of the struct structName
+// returning another name than the one of the tag of their field
+func checkAccessors(pass *analysis.Pass, structName string, structType *types.Struct, d directive) {
+	keys := strings.Split(d.tagKey, "|") // a chain like json|yaml|field
+	if d.tagFormat != "" && d.tagFormat != "comma" || slices.ContainsFunc(keys, func(key string) bool { return key == "gorm" || key == "xorm" || key == "protobuf" }) {
+		return // other formats than name,option,...
+	}
+	accessor, ok := pass.Pkg.Scope().Lookup(structName + d.varSuffix).(*types.Var)
+	if !ok {
+		return // consts output
+	}
+	named, ok := types.Unalias(accessor.Type()).(*types.Named)
+	if !ok {
+		return
+	}
+
+	tags := make(map[string]string) // Go name -> expected name
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		tag := reflect.StructTag(structType.Tag(i))
+		name, _, _ := strings.Cut(tag.Get("named"), ",")
+		if rename, ok := d.rename[field.Name()]; ok {
+			name = rename
+		}
+		for _, key := range keys {
+			if name != "" {
+				break
+			}
+			name, _, _ = strings.Cut(tag.Get(key), ",")
+		}
+		if name != "" && name != "-" {
+			tags[field.Name()] = name
+		}
+	}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil || len(fn.Body.List) != 1 {
+				continue
+			}
+			if recv, ok := fn.Recv.List[0].Type.(*ast.Ident); !ok || recv.Name != named.Obj().Name() {
+				continue
+			}
+			ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				continue
+			}
+			lit, ok := ret.Results[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				continue
+			}
+			got, err := strconv.Unquote(lit.Value)
+			want, tagged := tags[fn.Name.Name]
+			if err == nil && tagged && got != want {
+				pass.Reportf(lit.Pos(), "accessor %s.%s returns %q but the field is named %q, run generate-named",
+					structName+d.varSuffix, fn.Name.Name, got, want)
+			}
+		}
+	}
+}
+
+// checkLinked reports the unexported structs of the package with
+// named.Field members, neither linked nor the Value of another named.Field
+func checkLinked(pass *analysis.Pass) {
+	linked := make(map[*types.TypeName]bool)
+	for ident, instance := range pass.TypesInfo.Instances {
+		fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != namedPath || instance.TypeArgs.Len() == 0 {
+			continue
+		}
+		switch fn.Name() {
+		case "LoadLink", "RegisterSchema":
+		default:
+			continue
+		}
+		if named, ok := types.Unalias(instance.TypeArgs.At(0)).(*types.Named); ok {
+			linked[named.Obj()] = true
+		}
+	}
+
+	scope := pass.Pkg.Scope()
+	var candidates []*types.TypeName
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		structType, ok := typeName.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		hasField := false
+		for i := 0; i < structType.NumFields(); i++ {
+			if values, ok := fieldValues(structType.Field(i).Type()); ok {
+				hasField = true
+				for _, value := range values {
+					markNested(linked, value) // linked through its parent
+				}
+			}
+		}
+		if hasField && !typeName.Exported() {
+			candidates = append(candidates, typeName)
+		}
+	}
+
+	for _, typeName := range candidates {
+		if !linked[typeName] {
+			pass.Reportf(typeName.Pos(), "struct %s has named.Field members but is never linked by named.LoadLink or named.RegisterSchema",
+				typeName.Name())
+		}
+	}
+}
+
+// fieldValues returns the type arguments of t, if a named.Field or
+// named.FieldSlice
+func fieldValues(t types.Type) ([]types.Type, bool) {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != namedPath {
+		return nil, false
+	}
+	switch named.Obj().Name() {
+	case "Field", "FieldSlice":
+		return slices.Collect(named.TypeArgs().Types()), true
+	}
+	return nil, false
+}
+
+// markNested marks as linked the named type of the value t of a named.Field,
+// or of its elements
+func markNested(linked map[*types.TypeName]bool, t types.Type) {
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		linked[t.Obj()] = true
+	case *types.Pointer:
+		markNested(linked, t.Elem())
+	case *types.Slice:
+		markNested(linked, t.Elem())
+	case *types.Array:
+		markNested(linked, t.Elem())
+	}
+}
@@ -0,0 +1,61 @@
+package a
+
+import "github.com/alvarolm/named"
+
+// GENERATE-NAMED TagKey:json
+type User struct {
+	Email string `json:"email_address"`
+	Name  string `json:"name"`
+	Alias string `json:"alias" named:"nick"`
+}
+
+// GENERATE-NAMED TagKey:db,Rename:Password=pwd_hash
+type Account struct {
+	Login    string `db:"login"`
+	Password string `db:"password"`
+}
+
+// GENERATE-NAMED TagKey:db
+type Order struct { // want `struct Order has a GENERATE-NAMED directive but no generated code, run generate-named`
+	ID int `db:"id"`
+}
+
+// GENERATE-NAMED TagKey:json,Output:consts
+type Product struct {
+	SKU string `json:"sku"`
+}
+
+type linked struct {
+	Address named.Field[address] `json:"address"`
+}
+
+type address struct {
+	City named.Field[string] `json:"city"`
+}
+
+type registered struct {
+	Phone named.Field[string] `json:"phone"`
+}
+
+type forgotten struct { // want `struct forgotten has named.Field members but is never linked by named.LoadLink or named.RegisterSchema`
+	Phones named.Field[string] `json:"phones"`
+}
+
+type items struct {
+	Items named.FieldSlice[[]item, item] `json:"items"`
+}
+
+type item struct {
+	SKU named.Field[string] `json:"sku"`
+}
+
+// Exported structs may be linked by other packages
+type Contact struct {
+	Phone named.Field[string] `json:"phone"`
+}
+
+func init() {
+	named.LoadLink[linked]("json")
+	named.RegisterSchema[registered](named.Schema{})
+	named.LoadLink[items]("json")
+}
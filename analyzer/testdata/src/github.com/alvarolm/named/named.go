@@ -0,0 +1,16 @@
+// Package named is a stub of github.com/alvarolm/named for the analyzer tests.
+package named
+
+type Field[T comparable] struct{ Value T }
+
+type Slice[E any] interface{ ~[]E }
+
+type FieldSlice[T Slice[E], E any] struct{ Value T }
+
+type LinkOption func()
+
+type Schema struct{}
+
+func LoadLink[T any](tagKey string, opts ...LinkOption) error { return nil }
+
+func RegisterSchema[T any](s Schema, opts ...LinkOption) error { return nil }
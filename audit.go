@@ -0,0 +1,48 @@
+package named
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// AuditEntry records the change of a field, see AuditChanges.
+type AuditEntry struct {
+	Time  time.Time `json:"time"`
+	Actor string    `json:"actor"`
+	Path  string    `json:"path"` // full path, joined as by FieldPaths
+	Old   any       `json:"old"`
+	New   any       `json:"new"`
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+// AuditChanges returns an entry per field changed from old to new (see Diff),
+// all stamped with the current time and actor. The values of sensitive fields
+// (and of the fields nested in them) are replaced with RedactedValue.
+// Returns nil if T was not registered with LoadLink.
+func AuditChanges[T any](actor string, old, new *T) []AuditEntry {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil
+	}
+
+	ts := now()
+
+	var entries []AuditEntry
+	sch.diff(unsafe.Pointer(old), unsafe.Pointer(new), func(field *fieldInfo, oldV, newV reflect.Value, sensitive bool) {
+		entry := AuditEntry{
+			Time:  ts,
+			Actor: actor,
+			Path:  sch.fullName(field),
+			Old:   oldV.Interface(),
+			New:   newV.Interface(),
+		}
+		if sensitive {
+			entry.Old, entry.New = RedactedValue, RedactedValue
+		}
+		entries = append(entries, entry)
+	})
+	return entries
+}
@@ -0,0 +1,47 @@
+package named
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAuditChanges(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	now = func() time.Time { return ts }
+	defer func() { now = time.Now }()
+
+	a, b := newDiffPair()
+
+	expected := []AuditEntry{
+		{Time: ts, Actor: "alice", Path: "password", Old: RedactedValue, New: RedactedValue},
+		{Time: ts, Actor: "alice", Path: "address.city", Old: "X", New: "Y"},
+	}
+	if entries := AuditChanges("alice", a, b); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+
+	// the plain members of sensitive nested values are redacted too
+	type secret struct {
+		Key   string
+		Label Field[string] `json:"label"`
+	}
+	type vault struct {
+		Secret Field[secret] `json:"secret" sensitive:"true"`
+	}
+	LoadLink[vault]("json")
+
+	va, vb := &vault{}, &vault{}
+	va.Secret.Value.Key, vb.Secret.Value.Key = "k1", "k2"
+	expected = []AuditEntry{
+		{Time: ts, Actor: "alice", Path: "secret", Old: RedactedValue, New: RedactedValue},
+	}
+	if entries := AuditChanges("alice", va, vb); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+
+	type unknown struct{}
+	if entries := AuditChanges("alice", &unknown{}, &unknown{}); entries != nil {
+		t.Errorf("Expected nil for unregistered types, got %+v", entries)
+	}
+}
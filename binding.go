@@ -0,0 +1,183 @@
+package named
+
+import (
+	"encoding"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	fileHeaderPtrType   = reflect.TypeOf((*multipart.FileHeader)(nil))
+)
+
+// BindForm populates the Field members of s from form values, matching each
+// key against the full path of the field (nested fields use "parent.child").
+// Slice values receive every value of the key, other values only the first one.
+// Conversion failures are returned as FieldErrors.
+// T must be registered with LoadLink.
+func BindForm[T any](values url.Values, s *T) error {
+	return bindValues(s, values, nil)
+}
+
+// BindMultipart works like BindForm and additionally populates file parts:
+// fields whose value is []byte or *multipart.FileHeader receive the first file
+// sent under their name, [][]byte or []*multipart.FileHeader receive all of them.
+func BindMultipart[T any](form *multipart.Form, s *T) error {
+	return bindValues(s, form.Value, form.File)
+}
+
+func bindValues[T any](s *T, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return ErrSchemaNotLoaded
+	}
+
+	ptr := unsafe.Pointer(s)
+
+	var errs FieldErrors
+	for i := range sch.fields {
+		field := &sch.fields[i]
+		name := strings.Join(field.wirePath, DefaultFullNameSeparator)
+
+		var err error
+		if fhs := files[name]; len(fhs) > 0 && isFileType(field.value(ptr).Type()) {
+			err = setFromFiles(field.value(ptr), fhs)
+		} else if vals := values[name]; len(vals) > 0 {
+			err = setFromStrings(field.value(ptr), vals)
+		}
+
+		if err != nil {
+			errs = append(errs, field.error(err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func isFileType(t reflect.Type) bool {
+	switch {
+	case t == fileHeaderPtrType:
+		return true
+	case t.Kind() != reflect.Slice:
+		return false
+	case t.Elem().Kind() == reflect.Uint8, t.Elem() == fileHeaderPtrType:
+		return true
+	default:
+		return t.Elem().Kind() == reflect.Slice && t.Elem().Elem().Kind() == reflect.Uint8
+	}
+}
+
+func setFromFiles(v reflect.Value, fhs []*multipart.FileHeader) error {
+	t := v.Type()
+
+	switch {
+	case t == fileHeaderPtrType:
+		v.Set(reflect.ValueOf(fhs[0]))
+		return nil
+	case t.Elem() == fileHeaderPtrType:
+		v.Set(reflect.ValueOf(fhs).Convert(t))
+		return nil
+	case t.Elem().Kind() == reflect.Uint8:
+		data, err := readFile(fhs[0])
+		if err != nil {
+			return err
+		}
+		v.SetBytes(data)
+		return nil
+	}
+
+	// [][]byte
+	out := reflect.MakeSlice(t, len(fhs), len(fhs))
+	for i, fh := range fhs {
+		data, err := readFile(fh)
+		if err != nil {
+			return err
+		}
+		out.Index(i).SetBytes(data)
+	}
+	v.Set(out)
+	return nil
+}
+
+func readFile(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func setFromStrings(v reflect.Value, vals []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 && !v.Addr().Type().Implements(textUnmarshalerType) {
+		out := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setFromString(out.Index(i), val); err != nil {
+				return err
+			}
+		}
+		v.Set(out)
+		return nil
+	}
+	return setFromString(v, vals[0])
+}
+
+// setFromString converts text into the addressable value v.
+func setFromString(v reflect.Value, text string) error {
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return errors.New("unsupported slice type " + v.Type().String())
+		}
+		v.SetBytes([]byte(text))
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setFromString(elem.Elem(), text); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return TextUnmarshaler([]byte(text), v.Addr().Interface())
+	}
+	return nil
+}
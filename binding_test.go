@@ -0,0 +1,87 @@
+package named
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+type sampleBindAddress struct {
+	City Field[string] `json:"city"`
+}
+
+type sampleBind struct {
+	Name    Field[string]                `json:"name"`
+	Age     Field[int]                   `json:"age"`
+	Tags    FieldSlice[[]string, string] `json:"tags"`
+	Avatar  FieldSlice[[]byte, byte]     `json:"avatar"`
+	Address Field[sampleBindAddress]     `json:"address"`
+}
+
+func init() {
+	LoadLink[sampleBind]("json")
+}
+
+func TestBindForm(t *testing.T) {
+	s := sampleBind{}
+	err := BindForm(url.Values{
+		"name":         {"bob"},
+		"age":          {"42"},
+		"tags":         {"a", "b"},
+		"address.city": {"x"},
+	}, &s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.Name.Value != "bob" || s.Age.Value != 42 || s.Address.Value.City.Value != "x" {
+		t.Errorf("Unexpected bound values: %+v", s)
+	}
+	if len(s.Tags.Value) != 2 || s.Tags.Value[0] != "a" || s.Tags.Value[1] != "b" {
+		t.Errorf("Expected tags [a b], got %v", s.Tags.Value)
+	}
+
+	t.Run("ErrorsKeyedByPath", func(t *testing.T) {
+		err := BindForm(url.Values{"age": {"old"}}, &sampleBind{})
+
+		var errs FieldErrors
+		if !errors.As(err, &errs) || len(errs) != 1 {
+			t.Fatalf("Expected one FieldError, got %v", err)
+		}
+		if errs[0].Path[0] != "age" {
+			t.Errorf("Expected error path 'age', got %v", errs[0].Path)
+		}
+		if !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("Expected wrapped strconv.ErrSyntax, got %v", err)
+		}
+	})
+}
+
+func TestBindMultipart(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("name", "bob")
+	fw, _ := w.CreateFormFile("avatar", "avatar.png")
+	fw.Write([]byte("png-data"))
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("Unexpected error reading form: %v", err)
+	}
+
+	s := sampleBind{}
+	if err := BindMultipart(form, &s); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.Name.Value != "bob" {
+		t.Errorf("Expected Name to be 'bob', got '%s'", s.Name.Value)
+	}
+	if string(s.Avatar.Value) != "png-data" {
+		t.Errorf("Expected Avatar to hold the file content, got '%s'", s.Avatar.Value)
+	}
+}
@@ -0,0 +1,88 @@
+package named
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+var cacheKeyEscaper = strings.NewReplacer("%", "%25", ":", "%3A", "=", "%3D")
+
+// CacheKey returns a stable cache key for s built from the fields at paths,
+// e.g. "user:email=x:tenant=y". The prefix is the lower cased type name,
+// fields are written in schema order (regardless of the order of paths) and
+// ':', '=' and '%' in values are escaped.
+// Returns ErrSchemaNotLoaded if T was not registered with LoadLink, or an
+// error wrapping ErrUnknownPath if a path is unknown.
+func CacheKey[T any](s *T, paths ...string) (string, error) {
+	prefix, rest, err := cacheKeyParts(s, paths)
+	if err != nil {
+		return "", err
+	}
+	return prefix + rest, nil
+}
+
+// HashedCacheKey works like CacheKey but replaces the fields part with its
+// hex encoded SHA-256, e.g. "user:3a7bd3e2...", for values that are long or private.
+func HashedCacheKey[T any](s *T, paths ...string) (string, error) {
+	prefix, rest, err := cacheKeyParts(s, paths)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(rest))
+	return prefix + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+func cacheKeyParts[T any](s *T, paths []string) (string, string, error) {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return "", "", ErrSchemaNotLoaded
+	}
+	if err := sch.checkPaths(paths); err != nil {
+		return "", "", err
+	}
+
+	selected := make(map[*fieldInfo]bool, len(paths))
+	for _, p := range paths {
+		selected[sch.fieldByPath(p)] = true
+	}
+
+	ptr := unsafe.Pointer(s)
+
+	var b strings.Builder
+	for i := range sch.fields {
+		field := &sch.fields[i]
+		if !selected[field] {
+			continue
+		}
+		b.WriteByte(':')
+		b.WriteString(cacheKeyEscaper.Replace(sch.fullName(field)))
+		b.WriteByte('=')
+		b.WriteString(cacheKeyEscaper.Replace(formatValue(field.value(ptr))))
+	}
+
+	return strings.ToLower(reflect.TypeFor[T]().Name()), b.String(), nil
+}
+
+// formatValue returns a canonical text form of v, using its MarshalText
+// method if available (e.g. RFC 3339 for time.Time).
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
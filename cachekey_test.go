@@ -0,0 +1,60 @@
+package named
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sampleCacheKey struct {
+	Email   Field[string]    `json:"email"`
+	Tenant  Field[string]    `json:"tenant"`
+	Created Field[time.Time] `json:"created"`
+}
+
+func init() {
+	LoadLink[sampleCacheKey]("json")
+}
+
+func TestCacheKey(t *testing.T) {
+	s := sampleCacheKey{}
+	s.Email.Value = "a:b=c"
+	s.Tenant.Value = "y"
+	s.Created.Value = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		paths    []string
+		expected string
+	}{
+		{[]string{"email", "tenant"}, "samplecachekey:email=a%3Ab%3Dc:tenant=y"},
+		{[]string{"tenant", "email"}, "samplecachekey:email=a%3Ab%3Dc:tenant=y"},
+		{[]string{"created"}, "samplecachekey:created=2024-01-02T03%3A04%3A05Z"},
+	}
+
+	for _, tt := range tests {
+		if got, err := CacheKey(&s, tt.paths...); err != nil || got != tt.expected {
+			t.Errorf("CacheKey(%v): Expected %q, got %q (%v)", tt.paths, tt.expected, got, err)
+		}
+	}
+
+	hashed, err := HashedCacheKey(&s, "email", "tenant")
+	if err != nil || !strings.HasPrefix(hashed, "samplecachekey:") || len(hashed) != len("samplecachekey:")+64 {
+		t.Errorf("Unexpected hashed key %q (%v)", hashed, err)
+	}
+	if again, _ := HashedCacheKey(&s, "tenant", "email"); hashed != again {
+		t.Error("Expected hashed keys to be stable")
+	}
+
+	if _, err := CacheKey(&s, "phone"); !errors.Is(err, ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+	if _, err := HashedCacheKey(&s, "phone"); !errors.Is(err, ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+
+	type unregistered struct{}
+	if _, err := CacheKey(&unregistered{}); !errors.Is(err, ErrSchemaNotLoaded) {
+		t.Errorf("Expected ErrSchemaNotLoaded, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package named
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{`{"b":1, "a":{"d":[1.0, 2.50, 1e2], "c":"<x>"}}`, `{"a":{"c":"<x>","d":[1,2.5,100]},"b":1}`},
+		{`-0`, `0`},
+		{`[12345678901234567890123, 1.5e300, -0.000001]`, `[12345678901234567890123,1.5e+300,-1e-06]`},
+		{` null `, `null`},
+	}
+
+	for _, tt := range tests {
+		got, err := Canonicalize([]byte(tt.in))
+		if err != nil {
+			t.Fatalf("Canonicalize(%s): Unexpected error: %v", tt.in, err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("Canonicalize(%s): Expected %s, got %s", tt.in, tt.expected, got)
+		}
+	}
+
+	if _, err := Canonicalize([]byte(`{} {}`)); err == nil {
+		t.Error("Expected an error for trailing data")
+	}
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	data, err := MarshalCanonical(newSampleMarshal())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"address":{"city":"x","zip":"123"},"id":1,"name":"bob","note":""}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
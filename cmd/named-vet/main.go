@@ -0,0 +1,11 @@
+// Command named-vet runs the named analyzer as a go vet tool:
+//
+//	go vet -vettool=$(which named-vet) ./...
+package main
+
+import (
+	"github.com/alvarolm/named/analyzer"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() { unitchecker.Main(analyzer.Analyzer) }
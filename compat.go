@@ -0,0 +1,121 @@
+package named
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrIncompatibleSchema is returned by CheckCompatibility for the fields
+// breaking the baseline.
+var ErrIncompatibleSchema = errors.New("named: incompatible schema")
+
+// Baseline is a snapshot of the wire names of a type, stored (as JSON) to
+// detect breaking changes later with CheckCompatibility.
+type Baseline struct {
+	TagKey string          `json:"tagKey"`
+	Fields []BaselineField `json:"fields"`
+}
+
+// BaselineField is a field of a Baseline.
+type BaselineField struct {
+	Wire   string `json:"wire"`           // wire path joined with DefaultFullNameSeparator
+	GoPath string `json:"goPath"`         // Go names of the field and its parents, e.g. "Address.City"
+	Type   string `json:"type,omitempty"` // type of the Value
+}
+
+// BaselineOf returns the baseline of the current schema of T.
+func BaselineOf[T any]() (Baseline, error) {
+	s, ok := SchemaOf[T]()
+	if !ok {
+		return Baseline{}, ErrSchemaNotLoaded
+	}
+
+	b := Baseline{
+		TagKey: s.TagKey,
+		Fields: make([]BaselineField, len(s.Fields)),
+	}
+	var goPath []string
+	for i, f := range s.Fields {
+		// parents precede their nested fields
+		goPath = append(goPath[:len(f.Path)-1], f.GoName)
+
+		b.Fields[i] = BaselineField{
+			Wire:   strings.Join(f.WirePath, DefaultFullNameSeparator),
+			GoPath: strings.Join(goPath, "."),
+		}
+		if f.Type != nil {
+			b.Fields[i].Type = f.Type.Field(2).Type.String() // Value is at index 2
+		}
+	}
+	return b, nil
+}
+
+// WriteBaseline stores the baseline of the current schema of T in the file at path.
+func WriteBaseline[T any](path string) error {
+	b, err := BaselineOf[T]()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(b, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ReadBaseline reads a baseline stored with WriteBaseline.
+func ReadBaseline(path string) (Baseline, error) {
+	var b Baseline
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	return b, json.Unmarshal(data, &b)
+}
+
+// CheckCompatibility compares the current schema of T with baseline, returning
+// FieldErrors wrapping ErrIncompatibleSchema for every wire name of the
+// baseline that was removed, renamed (same Go field, different wire name) or
+// whose type changed. New fields are compatible.
+func CheckCompatibility[T any](baseline Baseline) error {
+	current, err := BaselineOf[T]()
+	if err != nil {
+		return err
+	}
+
+	byWire := make(map[string]*BaselineField, len(current.Fields))
+	byGoPath := make(map[string]*BaselineField, len(current.Fields))
+	for i := range current.Fields {
+		f := &current.Fields[i]
+		byWire[f.Wire] = f
+		byGoPath[f.GoPath] = f
+	}
+
+	var errs FieldErrors
+	for _, old := range baseline.Fields {
+		var err error
+		if f, ok := byWire[old.Wire]; ok {
+			if old.Type != "" && f.Type != "" && old.Type != f.Type {
+				err = fmt.Errorf("%w: type changed from %s to %s", ErrIncompatibleSchema, old.Type, f.Type)
+			}
+		} else if f, ok := byGoPath[old.GoPath]; ok {
+			err = fmt.Errorf("%w: renamed to %q", ErrIncompatibleSchema, f.Wire)
+		} else {
+			err = fmt.Errorf("%w: removed", ErrIncompatibleSchema)
+		}
+
+		if err != nil {
+			errs = append(errs, &FieldError{Path: strings.Split(old.Wire, DefaultFullNameSeparator), Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
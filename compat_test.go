@@ -0,0 +1,65 @@
+package named
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type compatAddress struct {
+	City Field[string] `json:"city"`
+}
+
+type compatV1 struct {
+	ID      Field[int]           `json:"id"`
+	Email   Field[string]        `json:"email"`
+	Age     Field[int]           `json:"age"`
+	Note    Field[string]        `json:"note"`
+	Address Field[compatAddress] `json:"address"`
+}
+
+type compatV2 struct {
+	ID      Field[int]           `json:"id" named:"identifier"` // logical rename only
+	Email   Field[string]        `json:"email_address"`         // renamed
+	Age     Field[string]        `json:"age"`                   // type changed
+	Address Field[compatAddress] `json:"address"`
+	Phone   Field[string]        `json:"phone"` // added
+	// Note removed
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	LoadLink[compatV1]("json")
+	LoadLink[compatV2]("json")
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := WriteBaseline[compatV1](path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	baseline, err := ReadBaseline(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := CheckCompatibility[compatV1](baseline); err != nil {
+		t.Errorf("Expected the same schema to be compatible, got %v", err)
+	}
+
+	err = CheckCompatibility[compatV2](baseline)
+	if !errors.Is(err, ErrIncompatibleSchema) {
+		t.Fatalf("Expected ErrIncompatibleSchema, got %v", err)
+	}
+
+	expected := []string{
+		`email: named: incompatible schema: renamed to "email_address"`,
+		`age: named: incompatible schema: type changed from int to string`,
+		`note: named: incompatible schema: removed`,
+	}
+	if err.Error() != strings.Join(expected, "; ") {
+		t.Errorf("Expected %s, got %v", strings.Join(expected, "; "), err)
+	}
+
+	if baseline.Fields[5].GoPath != "Address.City" || baseline.Fields[5].Wire != "address.city" {
+		t.Errorf("Unexpected nested field %+v", baseline.Fields[5])
+	}
+}
@@ -0,0 +1,34 @@
+package named
+
+import "context"
+
+type parentPathKey struct{}
+
+// ContextWithParent returns a copy of ctx carrying path as the parent path used
+// by LinkFromContext. If ctx already carries a parent path, path is appended to it,
+// so nested layers can extend the prefix established by outer ones.
+func ContextWithParent(ctx context.Context, path []string) context.Context {
+	parent := ParentFromContext(ctx)
+
+	combined := make([]string, len(parent)+len(path))
+	copy(combined, parent)
+	copy(combined[len(parent):], path)
+
+	return context.WithValue(ctx, parentPathKey{}, &combined)
+}
+
+// ParentFromContext returns the parent path carried by ctx, or nil if none.
+func ParentFromContext(ctx context.Context) []string {
+	if p, ok := ctx.Value(parentPathKey{}).(*[]string); ok {
+		return *p
+	}
+	return nil
+}
+
+// LinkFromContext links s like LinkWithPath, using the parent path carried by ctx.
+// Without a parent path in ctx it behaves like Link.
+// returns true if linking was successful, false otherwise.
+func LinkFromContext[T any](ctx context.Context, s *T) bool {
+	path, _ := ctx.Value(parentPathKey{}).(*[]string)
+	return LinkWithPath(s, path)
+}
@@ -0,0 +1,40 @@
+package named
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLinkFromContext(t *testing.T) {
+	type Inner struct {
+		A Field[int] `json:"a"`
+	}
+
+	LoadLink[Inner]("json")
+
+	t.Run("NoParent", func(t *testing.T) {
+		s := Inner{}
+		if !LinkFromContext(context.Background(), &s) {
+			t.Fatal("LinkFromContext failed")
+		}
+		if s.A.FullName("") != "a" {
+			t.Errorf("Expected A.FullName() to be 'a', got '%s'", s.A.FullName(""))
+		}
+	})
+
+	t.Run("NestedParents", func(t *testing.T) {
+		ctx := ContextWithParent(context.Background(), []string{"data"})
+		ctx = ContextWithParent(ctx, []string{"user"})
+
+		s := Inner{}
+		if !LinkFromContext(ctx, &s) {
+			t.Fatal("LinkFromContext failed")
+		}
+		if s.A.Name() != "a" {
+			t.Errorf("Expected A.Name() to be 'a', got '%s'", s.A.Name())
+		}
+		if s.A.FullName("") != "data.user.a" {
+			t.Errorf("Expected A.FullName() to be 'data.user.a', got '%s'", s.A.FullName(""))
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package named
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConvertKeys translates m, keyed by the names of the tagKey from of T, into a
+// map keyed by the names of the tag key to, e.g. from an API shaped map ("json")
+// into a DB shaped one ("db"). Nested maps are converted for fields holding
+// nested fields. Fields without a name for to (tag "-") are dropped.
+// T must be registered with LoadLink for both tag keys.
+// Returns an error wrapping ErrUnknownPath for keys that are not fields of from.
+func ConvertKeys[T any](m map[string]any, from, to string) (map[string]any, error) {
+	fromSch, ok := loadTagSchema[T](from)
+	if !ok {
+		return nil, fmt.Errorf("%w: tag key %q", ErrSchemaNotLoaded, from)
+	}
+	toSch, ok := loadTagSchema[T](to)
+	if !ok {
+		return nil, fmt.Errorf("%w: tag key %q", ErrSchemaNotLoaded, to)
+	}
+
+	// the same Go field has the same offset and type in both schemas
+	type fieldKey struct {
+		offset uintptr
+		depth  int
+	}
+	toIndex := make(map[fieldKey]int, len(toSch.fields))
+	for i := range toSch.fields {
+		toIndex[fieldKey{toSch.fields[i].offset, len(toSch.fields[i].wirePath)}] = i
+	}
+
+	var convert func(m map[string]any, start, end, depth int) (map[string]any, error)
+	convert = func(m map[string]any, start, end, depth int) (map[string]any, error) {
+		out := make(map[string]any, len(m))
+
+		for key, value := range m {
+			i := fromSch.childByName(start, end, depth, key)
+			if i == -1 {
+				prefix := ""
+				if start > 0 {
+					prefix = strings.Join(fromSch.fields[start-1].wirePath, DefaultFullNameSeparator) + DefaultFullNameSeparator
+				}
+				return nil, fmt.Errorf("%w: %q", ErrUnknownPath, prefix+key)
+			}
+
+			field := &fromSch.fields[i]
+			j, ok := toIndex[fieldKey{field.offset, depth + 1}]
+			if !ok {
+				continue
+			}
+
+			if nested, ok := value.(map[string]any); ok && fromSch.subtreeEnd(i) > i+1 {
+				converted, err := convert(nested, i+1, fromSch.subtreeEnd(i), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				value = converted
+			}
+
+			out[toSch.fields[j].wirePath[depth]] = value
+		}
+
+		return out, nil
+	}
+
+	return convert(m, 0, len(fromSch.fields), 0)
+}
+
+// childByName returns the index of the field with the wire name name at depth
+// within sch.fields[start:end], -1 if none.
+func (sch *schema) childByName(start, end, depth int, name string) int {
+	for i := start; i < end; i = sch.subtreeEnd(i) {
+		if sch.fields[i].wirePath[depth] == name {
+			return i
+		}
+	}
+	return -1
+}
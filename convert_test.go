@@ -0,0 +1,58 @@
+package named
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type sampleConvertAddress struct {
+	City Field[string] `json:"city" db:"city_name"`
+}
+
+type sampleConvert struct {
+	ID       Field[int]                  `json:"id" db:"user_id"`
+	Password Field[string]               `json:"password" db:"-"`
+	Address  Field[sampleConvertAddress] `json:"address" db:"addr"`
+}
+
+func init() {
+	LoadLink[sampleConvert]("db")
+	LoadLink[sampleConvert]("json")
+}
+
+func TestConvertKeys(t *testing.T) {
+	in := map[string]any{
+		"id":       1,
+		"password": "x",
+		"address":  map[string]any{"city": "y"},
+	}
+
+	out, err := ConvertKeys[sampleConvert](in, "json", "db")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]any{
+		"user_id": 1,
+		"addr":    map[string]any{"city_name": "y"},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Expected %v, got %v", expected, out)
+	}
+
+	back, err := ConvertKeys[sampleConvert](out, "db", "json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(back, map[string]any{"id": 1, "address": map[string]any{"city": "y"}}) {
+		t.Errorf("Unexpected round trip %v", back)
+	}
+
+	if _, err := ConvertKeys[sampleConvert](map[string]any{"address": map[string]any{"zip": 1}}, "json", "db"); !errors.Is(err, ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+	if _, err := ConvertKeys[sampleConvert](in, "json", "yaml"); !errors.Is(err, ErrSchemaNotLoaded) {
+		t.Errorf("Expected ErrSchemaNotLoaded, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package named
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+var debugLogger *slog.Logger
+
+// SetDebugLogger makes LoadLink (and the other functions building schemas)
+// log at debug level why struct members were skipped and where the fields
+// were found, to diagnose fields left without a name. nil disables it.
+// not async safe, should be called before any LoadLink calls.
+func SetDebugLogger(l *slog.Logger) {
+	debugLogger = l
+}
+
+// debugSkipped logs why field of tVal is not part of the schema.
+func debugSkipped(tVal reflect.Type, field reflect.StructField, reason string) {
+	if debugLogger == nil {
+		return
+	}
+	debugLogger.LogAttrs(context.Background(), slog.LevelDebug, "named: field skipped",
+		slog.String("type", tVal.String()),
+		slog.String("field", field.Name),
+		slog.String("reason", reason),
+	)
+}
+
+// debugCollected logs a field added to the schema.
+func debugCollected(tVal reflect.Type, field *fieldInfo) {
+	if debugLogger == nil {
+		return
+	}
+	debugLogger.LogAttrs(context.Background(), slog.LevelDebug, "named: field linked",
+		slog.String("type", tVal.String()),
+		slog.String("field", field.goName),
+		slog.String("path", field.fullName),
+		slog.String("wire", strings.Join(field.wirePath, DefaultFullNameSeparator)),
+		slog.Uint64("offset", uint64(field.offset)),
+	)
+}
@@ -0,0 +1,39 @@
+package named
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type debugSample struct {
+	A Field[int] `json:"a"`
+	B Field[int] `json:"-"`
+	C string     `json:"c"`
+	d Field[int]
+	E Field[int] `named:"-"`
+}
+
+func TestSetDebugLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetDebugLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetDebugLogger(nil)
+
+	if err := LoadLink[debugSample]("json"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, expected := range []string{
+		`msg="named: field linked" type=named.debugSample field=A path=a wire=a offset=0`,
+		`field=B reason="json tag is \"-\""`,
+		`field=C reason="not a Field or FieldSlice layout: string"`,
+		`field=d reason=unexported`,
+		`field=E reason="named tag is \"-\""`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected the log to contain %s, got:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package named
+
+import (
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// Change is a difference between two values of a type, see Diff.
+type Change struct {
+	Path []string // full path of the field
+	Old  any
+	New  any
+}
+
+// Diff returns the changes of the Field members from old to new, in schema
+// order, comparing their values with reflect.DeepEqual.
+// Fields holding nested fields are compared through their nested fields, the
+// changes of their other exported members being reported under their own path
+// as maps of these members by Go name.
+// Returns nil if T was not registered with LoadLink.
+func Diff[T any](old, new *T) []Change {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil
+	}
+
+	var changes []Change
+	sch.diff(unsafe.Pointer(old), unsafe.Pointer(new), func(field *fieldInfo, oldV, newV reflect.Value, _ bool) {
+		changes = append(changes, Change{
+			Path: slices.Clone(*field.pathPtr),
+			Old:  oldV.Interface(),
+			New:  newV.Interface(),
+		})
+	})
+	return changes
+}
+
+// diff calls fn for every leaf field whose value differs between the structs
+// at a and b, and for every field holding nested fields whose other members
+// differ (with the maps of plainMembers). sensitive reports whether the field
+// or one of its parents is sensitive.
+func (sch *schema) diff(a, b unsafe.Pointer, fn func(field *fieldInfo, oldV, newV reflect.Value, sensitive bool)) {
+	var sensitive []bool // by depth
+	for i := range sch.fields {
+		field := &sch.fields[i]
+
+		depth := len(*field.pathPtr)
+		sensitive = append(sensitive[:depth-1], field.sensitive || depth > 1 && sensitive[depth-2])
+
+		if sch.subtreeEnd(i) > i+1 {
+			// the other members of the nested value, its Field members following
+			oldPlain, newPlain := plainMembers(field.value(a)), plainMembers(field.value(b))
+			if !reflect.DeepEqual(oldPlain, newPlain) {
+				fn(field, reflect.ValueOf(oldPlain), reflect.ValueOf(newPlain), sensitive[depth-1])
+			}
+			continue
+		}
+
+		oldV, newV := field.value(a), field.value(b)
+		if !reflect.DeepEqual(oldV.Interface(), newV.Interface()) {
+			fn(field, oldV, newV, sensitive[depth-1])
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package named
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffAddress struct {
+	City Field[string] `json:"city"`
+}
+
+type diffSample struct {
+	Name     Field[string]                `json:"name"`
+	Password Field[string]                `json:"password" sensitive:"true"`
+	Address  Field[diffAddress]           `json:"address"`
+	Tags     FieldSlice[[]string, string] `json:"tags"`
+}
+
+func newDiffPair() (*diffSample, *diffSample) {
+	LoadLink[diffSample]("json")
+
+	a, b := &diffSample{}, &diffSample{}
+	a.Name.Value, b.Name.Value = "a", "a"
+	a.Password.Value, b.Password.Value = "x", "y"
+	a.Address.Value.City.Value, b.Address.Value.City.Value = "X", "Y"
+	a.Tags.Value, b.Tags.Value = []string{"t"}, []string{"t"}
+	Link(b) // linking doesn't matter
+	return a, b
+}
+
+func TestDiff(t *testing.T) {
+	a, b := newDiffPair()
+
+	expected := []Change{
+		{Path: []string{"password"}, Old: "x", New: "y"},
+		{Path: []string{"address", "city"}, Old: "X", New: "Y"},
+	}
+	if changes := Diff(a, b); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, changes)
+	}
+
+	if changes := Diff(a, a); changes != nil {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
+
+type diffGeo struct {
+	Lat  float64
+	Zone Field[string] `json:"zone"`
+}
+
+type diffPlace struct {
+	Geo Field[diffGeo] `json:"geo"`
+}
+
+func TestDiff_PlainMembers(t *testing.T) {
+	LoadLink[diffPlace]("json")
+
+	a, b := &diffPlace{}, &diffPlace{}
+	a.Geo.Value.Lat, b.Geo.Value.Lat = 1.5, 2.5
+
+	expected := []Change{
+		{Path: []string{"geo"}, Old: map[string]any{"Lat": 1.5}, New: map[string]any{"Lat": 2.5}},
+	}
+	if changes := Diff(a, b); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, changes)
+	}
+
+	b.Geo.Value.Lat = 1.5
+	b.Geo.Value.Zone.Value = "utc"
+	expected = []Change{
+		{Path: []string{"geo", "zone"}, Old: "", New: "utc"},
+	}
+	if changes := Diff(a, b); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, changes)
+	}
+}
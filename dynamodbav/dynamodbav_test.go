@@ -0,0 +1,95 @@
+package dynamodbav
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/alvarolm/named"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type address struct {
+	City Field[string] `dynamodbav:"city"`
+}
+
+type user struct {
+	ID      Field[string]                `dynamodbav:"pk"`
+	Email   Field[string]                `dynamodbav:"email_address" named:"email"`
+	Address Field[address]               `dynamodbav:"address"`
+	Tags    FieldSlice[[]string, string] `dynamodbav:"tags,omitempty"`
+}
+
+func init() {
+	if err := named.LoadLink[user](TagKey); err != nil {
+		panic(err)
+	}
+}
+
+func TestField_AttributeValue(t *testing.T) {
+	u := user{}
+	u.ID.Value = "u1"
+	u.Email.Value = "a@b.c"
+	u.Address.Value.City.Value = "X"
+
+	item, err := attributevalue.MarshalMapWithOptions(u, func(o *attributevalue.EncoderOptions) {
+		o.OmitNullAttributeValues = true
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s, ok := item["email_address"].(*types.AttributeValueMemberS); !ok || s.Value != "a@b.c" {
+		t.Errorf("Unexpected email attribute %#v", item["email_address"])
+	}
+	if m, ok := item["address"].(*types.AttributeValueMemberM); !ok || m.Value["city"].(*types.AttributeValueMemberS).Value != "X" {
+		t.Errorf("Unexpected address attribute %#v", item["address"])
+	}
+	if _, ok := item["tags"]; ok {
+		t.Errorf("Expected empty tags to be omitted, got %#v", item["tags"])
+	}
+
+	var got user
+	if err := attributevalue.UnmarshalMap(item, &got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.ID.Value != "u1" || got.Address.Value.City.Value != "X" {
+		t.Errorf("Unexpected round trip %+v", got)
+	}
+
+	if !named.Link(&got) || got.Address.Value.City.FullName(".") != "address.city" {
+		t.Errorf("Expected the fields to be linked, got %q", got.Address.Value.City.FullName("."))
+	}
+}
+
+func TestSetExpression(t *testing.T) {
+	u := user{}
+	u.Email.Value = "a@b.c"
+	u.Address.Value.City.Value = "X"
+
+	expr, names, values, err := SetExpression(&u, "email", "address.city")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "SET #n1 = :v0, #n2.#n3 = :v1"; expr != expected {
+		t.Errorf("Expected %q, got %q", expected, expr)
+	}
+	if expected := map[string]string{"#n1": "email_address", "#n2": "address", "#n3": "city"}; !maps.Equal(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+	if v, ok := values[":v1"].(*types.AttributeValueMemberS); !ok || v.Value != "X" {
+		t.Errorf("Unexpected value %#v", values[":v1"])
+	}
+
+	if _, _, _, err := SetExpression(&u, "nope"); !errors.Is(err, named.ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+	if _, _, _, err := SetExpression(&u); !errors.Is(err, ErrNoPaths) {
+		t.Errorf("Expected ErrNoPaths, got %v", err)
+	}
+
+	n, _ := NamesOf[user]()
+	if len(n.AttributeNames()) != 5 {
+		t.Errorf("Expected every placeholder, got %v", n.AttributeNames())
+	}
+}
@@ -0,0 +1,139 @@
+package dynamodbav
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvarolm/named"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrNoPaths is returned by SetExpression when no path is given.
+var ErrNoPaths = errors.New("dynamodbav: no paths to update")
+
+// Names maps the full paths of the fields of a type (as returned by
+// named.FieldPaths) to their expression attribute name
+// placeholders, e.g. "#n1.#n2" for "address.city". Placeholders are numbered
+// after the position of the field in the schema so they are stable.
+type Names struct {
+	exprs map[string]string
+	names map[string]string // placeholder -> attribute name
+}
+
+// NamesOf builds the placeholders of the fields of T, named after the tag key
+// of its schema (T should be registered with TagKey).
+func NamesOf[T any]() (*Names, error) {
+	s, ok := named.SchemaOf[T]()
+	if !ok {
+		return nil, named.ErrSchemaNotLoaded
+	}
+
+	n := &Names{
+		exprs: make(map[string]string, len(s.Fields)),
+		names: make(map[string]string, len(s.Fields)),
+	}
+	paths := named.FieldPaths[T]() // in the order of s.Fields
+	var parents []string           // expressions of the parents of the field, by depth
+	for i, f := range s.Fields {
+		placeholder := "#n" + strconv.Itoa(i)
+		n.names[placeholder] = f.WirePath[len(f.WirePath)-1]
+
+		// parents precede their nested fields
+		depth := len(f.Path) - 1
+		expr := placeholder
+		if depth > 0 {
+			expr = parents[depth-1] + "." + placeholder
+		}
+		parents = append(parents[:depth], expr)
+		n.exprs[paths[i]] = expr
+	}
+	return n, nil
+}
+
+// Expr returns the placeholder expression of the field at path, e.g. "#n1.#n2".
+// Returns an error wrapping named.ErrUnknownPath for unknown paths.
+func (n *Names) Expr(path string) (string, error) {
+	expr, ok := n.exprs[path]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", named.ErrUnknownPath, path)
+	}
+	return expr, nil
+}
+
+// AttributeNames returns the placeholders used by the expressions of paths
+// mapped to their attribute names, to be set as ExpressionAttributeNames.
+// Every placeholder is returned when paths is empty.
+func (n *Names) AttributeNames(paths ...string) map[string]string {
+	if len(paths) == 0 {
+		out := make(map[string]string, len(n.names))
+		for placeholder, name := range n.names {
+			out[placeholder] = name
+		}
+		return out
+	}
+
+	out := make(map[string]string)
+	for _, path := range paths {
+		for _, placeholder := range strings.Split(n.exprs[path], ".") {
+			if name, ok := n.names[placeholder]; ok {
+				out[placeholder] = name
+			}
+		}
+	}
+	return out
+}
+
+// SetExpression builds the "SET" UpdateExpression assigning the fields of s at
+// paths, along with its ExpressionAttributeNames and ExpressionAttributeValues:
+//
+//	expr, names, values, err := dynamodbav.SetExpression(&u, "email", "address.city")
+//	// SET #n1 = :v0, #n2.#n3 = :v1
+//
+// Returns an error wrapping named.ErrUnknownPath for unknown paths.
+func SetExpression[T any](s *T, paths ...string) (string, map[string]string, map[string]types.AttributeValue, error) {
+	if len(paths) == 0 {
+		return "", nil, nil, ErrNoPaths
+	}
+
+	n, err := NamesOf[T]()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	index := make(map[string]int)
+	for i, p := range named.FieldPaths[T]() {
+		index[p] = i
+	}
+	fieldValues := named.FieldValues(s)
+	sch, _ := named.SchemaOf[T]() // loaded, see NamesOf
+
+	var b strings.Builder
+	b.WriteString("SET ")
+	values := make(map[string]types.AttributeValue, len(paths))
+	for i, path := range paths {
+		expr, err := n.Expr(path)
+		if err != nil {
+			return "", nil, nil, err
+		}
+
+		av, err := attributevalue.Marshal(fieldValues[index[path]])
+		if err != nil {
+			return "", nil, nil, &named.FieldError{Path: sch.Fields[index[path]].Path, Err: err}
+		}
+
+		placeholder := ":v" + strconv.Itoa(i)
+		values[placeholder] = av
+
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(expr)
+		b.WriteString(" = ")
+		b.WriteString(placeholder)
+	}
+
+	return b.String(), n.AttributeNames(paths...), values, nil
+}
@@ -0,0 +1,132 @@
+// Package dynamodbav integrates named with the DynamoDB attributevalue package
+// of aws-sdk-go-v2, for types registered with the "dynamodbav" tag key:
+//
+//	type User struct {
+//		ID    dynamodbav.Field[string] `dynamodbav:"pk"`
+//		Email dynamodbav.Field[string] `dynamodbav:"email"`
+//	}
+//
+//	named.LoadLink[User](dynamodbav.TagKey)
+//
+// It lives in its own module so the root module does not depend on the AWS SDK.
+// It requires a published version of the root module, changes made to both
+// are built together with a local workspace (go work init . ./dynamodbav).
+package dynamodbav
+
+import (
+	"unsafe"
+
+	"github.com/alvarolm/named"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TagKey is the tag key used by the attributevalue package.
+const TagKey = "dynamodbav"
+
+// Field is a named.Field encoded by attributevalue as its Value.
+// It has the layout of named.Field, so it is linked like one.
+// The omitempty tag option needs the OmitNullAttributeValues encoder option,
+// nil values being encoded as NULL.
+type Field[T comparable] struct {
+	path       *[]string // goes first so it's recognized by the linker
+	parentPath *[]string
+	Value      T
+	present    bool // presence of named.Field
+}
+
+var (
+	_ attributevalue.Marshaler   = Field[int]{}
+	_ attributevalue.Unmarshaler = (*Field[int])(nil)
+)
+
+func (f *Field[T]) named() *named.Field[T] {
+	return (*named.Field[T])(unsafe.Pointer(f))
+}
+
+// Name returns the leaf name of the field, see named.Field.Name.
+func (f *Field[T]) Name() string { return f.named().Name() }
+
+// FullName returns the full path of the field, see named.Field.FullName.
+func (f *Field[T]) FullName(separator string) string { return f.named().FullName(separator) }
+
+// FullNameAs returns the full path of the field rendered with style.
+func (f *Field[T]) FullNameAs(style named.FullNameStyle) string { return f.named().FullNameAs(style) }
+
+// Path returns the full path of the field, see named.Field.Path.
+func (f *Field[T]) Path() []string { return f.named().Path() }
+
+func (f *Field[T]) NoName() bool { return f.named().NoName() }
+
+func (f *Field[T]) NoValue() bool { return f.named().NoValue() }
+
+// IsZero reports whether Value is the zero value, see named.Field.IsZero.
+func (f *Field[T]) IsZero() bool { return f.named().IsZero() }
+
+func (f Field[T]) MarshalJSON() ([]byte, error) { return f.named().MarshalJSON() }
+
+func (f *Field[T]) UnmarshalJSON(data []byte) error { return f.named().UnmarshalJSON(data) }
+
+// MarshalDynamoDBAttributeValue implements attributevalue.Marshaler.
+func (f Field[T]) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return attributevalue.Marshal(f.Value)
+}
+
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+func (f *Field[T]) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return attributevalue.Unmarshal(av, &f.Value)
+}
+
+// FieldSlice is a named.FieldSlice encoded by attributevalue as its Value.
+// It has the layout of named.FieldSlice, so it is linked like one.
+type FieldSlice[T named.Slice[E], E any] struct {
+	path       *[]string // goes first so it's recognized by the linker
+	parentPath *[]string
+	Value      T
+	elems      unsafe.Pointer // element paths of named.FieldSlice.At
+	present    bool           // presence of named.FieldSlice
+}
+
+var (
+	_ attributevalue.Marshaler   = FieldSlice[[]int, int]{}
+	_ attributevalue.Unmarshaler = (*FieldSlice[[]int, int])(nil)
+)
+
+func (f *FieldSlice[T, E]) named() *named.FieldSlice[T, E] {
+	return (*named.FieldSlice[T, E])(unsafe.Pointer(f))
+}
+
+// Name returns the leaf name of the field, see named.FieldSlice.Name.
+func (f *FieldSlice[T, E]) Name() string { return f.named().Name() }
+
+// FullName returns the full path of the field, see named.FieldSlice.FullName.
+func (f *FieldSlice[T, E]) FullName(separator string) string { return f.named().FullName(separator) }
+
+// FullNameAs returns the full path of the field rendered with style.
+func (f *FieldSlice[T, E]) FullNameAs(style named.FullNameStyle) string {
+	return f.named().FullNameAs(style)
+}
+
+// Path returns the full path of the field, see named.FieldSlice.Path.
+func (f *FieldSlice[T, E]) Path() []string { return f.named().Path() }
+
+func (f *FieldSlice[T, E]) NoName() bool { return f.named().NoName() }
+
+func (f *FieldSlice[T, E]) NoValue() bool { return f.named().NoValue() }
+
+// IsZero reports whether Value has no elements, see named.FieldSlice.IsZero.
+func (f *FieldSlice[T, E]) IsZero() bool { return f.named().IsZero() }
+
+func (f FieldSlice[T, E]) MarshalJSON() ([]byte, error) { return f.named().MarshalJSON() }
+
+func (f *FieldSlice[T, E]) UnmarshalJSON(data []byte) error { return f.named().UnmarshalJSON(data) }
+
+// MarshalDynamoDBAttributeValue implements attributevalue.Marshaler.
+func (f FieldSlice[T, E]) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return attributevalue.Marshal(f.Value)
+}
+
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+func (f *FieldSlice[T, E]) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return attributevalue.Unmarshal(av, &f.Value)
+}
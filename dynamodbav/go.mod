@@ -0,0 +1,14 @@
+module github.com/alvarolm/named/dynamodbav
+
+go 1.25.0
+
+require (
+	github.com/alvarolm/named v0.0.0-20261015060645-15ef767c5196
+	github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue v1.21.7
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.69.1
+)
+
+require (
+	github.com/aws/aws-sdk-go-v2/service/dynamodbstreams v1.43.0 // indirect
+	github.com/aws/smithy-go v1.28.1 // indirect
+)
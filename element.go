@@ -0,0 +1,81 @@
+package named
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Element is a view of an element of a FieldSlice, see FieldSlice.At.
+// Its path is the path of the slice followed by the index of the element.
+type Element[E any] struct {
+	path  *[]string
+	Value *E
+}
+
+// At returns a view of the element at index i, panicking if i is out of range.
+// If E is a struct holding Field members registered with LoadLink they are
+// linked under the indexed path, e.g. "items.2.sku", the element is left
+// unlinked otherwise: At never loads schemas, so it can be called by
+// concurrent requests.
+// The element has no name if the FieldSlice is not linked.
+func (f *FieldSlice[T, E]) At(i int) Element[E] {
+	e := Element[E]{Value: &f.Value[i]}
+	if f.path == nil {
+		return e
+	}
+
+	e.path = &f.elementPaths().paths[i].names
+	if reflect.TypeFor[E]().Kind() == reflect.Struct {
+		LinkWithPath(e.Value, e.path)
+	}
+	return e
+}
+
+// elementPaths are the paths of the elements of a FieldSlice, built once by
+// At for the path of the slice they extend.
+type elementPaths struct {
+	path, parentPath *[]string
+	paths            []fieldPath
+}
+
+// elementPaths returns the paths of the elements of the slice, built again
+// when the slice was linked to another path or has grown.
+func (f *FieldSlice[T, E]) elementPaths() *elementPaths {
+	if e := f.elems; e != nil && e.path == f.path && e.parentPath == f.parentPath && len(e.paths) >= len(f.Value) {
+		return e
+	}
+
+	base := f.Path()
+	sch := pathSchema(f.path)
+	names := make([]string, len(f.Value)*(len(base)+1)) // backs all the paths
+	e := &elementPaths{path: f.path, parentPath: f.parentPath, paths: make([]fieldPath, len(f.Value))}
+	for i := range e.paths {
+		path := names[i*(len(base)+1) : (i+1)*(len(base)+1) : (i+1)*(len(base)+1)]
+		copy(path, base)
+		path[len(base)] = strconv.Itoa(i)
+		e.paths[i] = fieldPath{names: path, sch: sch}
+	}
+	f.elems = e
+	return e
+}
+
+// Name returns the index of the element.
+func (e Element[E]) Name() string {
+	return fieldNameOp(e.path)
+}
+
+// FullName returns the full path of the element as a separated string,
+// see Field.FullName.
+func (e Element[E]) FullName(separator string) string {
+	return fieldFullNameOp(e.path, nil, separator)
+}
+
+// FullNameAs returns the full path of the element rendered with style.
+func (e Element[E]) FullNameAs(style FullNameStyle) string {
+	return fieldFullNameStyleOp(e.path, nil, style)
+}
+
+// Path returns the full path of the element, nil if the FieldSlice is not linked.
+func (e Element[E]) Path() []string {
+	return getCombinedPath(e.path, nil)
+}
@@ -0,0 +1,126 @@
+package named
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+type sampleItem struct {
+	SKU Field[string] `json:"sku"`
+	Qty Field[int]    `json:"qty"`
+}
+
+type sampleOrder struct {
+	Items FieldSlice[[]sampleItem, sampleItem] `json:"items"`
+	Tags  FieldSlice[[]string, string]         `json:"tags"`
+}
+
+func TestFieldSlice_At(t *testing.T) {
+	if err := LoadLinkAll("json", sampleOrder{}, sampleItem{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	o := sampleOrder{}
+	o.Items.Value = []sampleItem{{}, {}}
+	o.Tags.Value = []string{"a", "b"}
+
+	if e := o.Tags.At(1); e.Name() != "" || *e.Value != "b" {
+		t.Errorf("Expected an unnamed element before linking, got %q", e.Name())
+	}
+
+	if !Link(&o) {
+		t.Fatal("Link failed")
+	}
+
+	tag := o.Tags.At(1)
+	if tag.Name() != "1" || tag.FullName(".") != "tags.1" || tag.FullNameAs(StyleBracketed) != "tags[1]" {
+		t.Errorf("Unexpected names %q, %q, %q", tag.Name(), tag.FullName("."), tag.FullNameAs(StyleBracketed))
+	}
+	if !slices.Equal(tag.Path(), []string{"tags", "1"}) {
+		t.Errorf("Unexpected path %v", tag.Path())
+	}
+
+	item := o.Items.At(1)
+	item.Value.Qty.Value = 3
+	if o.Items.Value[1].Qty.Value != 3 {
+		t.Error("Expected the view to point into the slice")
+	}
+	if name := o.Items.Value[1].SKU.FullName("."); name != "items.1.sku" {
+		t.Errorf("Expected element fields to be linked under the index, got %q", name)
+	}
+	if !slices.Equal(o.Items.Value[1].Qty.Path(), []string{"items", "1", "qty"}) {
+		t.Errorf("Unexpected path %v", o.Items.Value[1].Qty.Path())
+	}
+
+	// the slice path is left untouched
+	if o.Tags.FullName(".") != "tags" || o.Items.FullName(".") != "items" {
+		t.Errorf("Unexpected slice names %q, %q", o.Tags.FullName("."), o.Items.FullName("."))
+	}
+}
+
+type unregisteredItem struct {
+	SKU Field[string] `json:"sku"`
+}
+
+type unregisteredOrder struct {
+	Items FieldSlice[[]unregisteredItem, unregisteredItem] `json:"items"`
+}
+
+func TestFieldSlice_At_Unregistered(t *testing.T) {
+	if err := LoadLink[unregisteredOrder]("json"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	o := unregisteredOrder{}
+	o.Items.Value = make([]unregisteredItem, 2)
+	Link(&o)
+
+	// the element is named, its Field members are left unlinked
+	if e := o.Items.At(1); e.FullName(".") != "items.1" || !e.Value.SKU.NoName() {
+		t.Errorf("Unexpected element %q, sku %q", e.FullName("."), e.Value.SKU.FullName("."))
+	}
+	if _, ok := loadSchema[unregisteredItem](); ok {
+		t.Error("Expected At not to load the element schema")
+	}
+}
+
+func TestFieldSlice_At_Paths(t *testing.T) {
+	if err := LoadLinkAll("json", sampleOrder{}, sampleItem{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	o := sampleOrder{}
+	o.Tags.Value = []string{"a", "b"}
+	Link(&o)
+
+	// built once for the slice
+	if a, b := o.Tags.At(0), o.Tags.At(0); a.path != b.path {
+		t.Error("Expected the element paths to be reused")
+	}
+
+	// and again once it has grown
+	o.Tags.Value = append(o.Tags.Value, "c")
+	if e := o.Tags.At(2); e.FullName(".") != "tags.2" {
+		t.Errorf("Unexpected name %q", e.FullName("."))
+	}
+
+	// linked concurrently
+	orders := make([]sampleOrder, 8)
+	var wg sync.WaitGroup
+	for i := range orders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orders[i].Items.Value = make([]sampleItem, 3)
+			Link(&orders[i])
+			orders[i].Items.At(2)
+		}()
+	}
+	wg.Wait()
+	for i := range orders {
+		if name := orders[i].Items.Value[2].SKU.FullName("."); name != "items.2.sku" {
+			t.Errorf("Unexpected name %q", name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package named
+
+import "strings"
+
+// FieldError is an error tied to a field, identified by its full path.
+type FieldError struct {
+	Path []string
+	// WirePath is the path made of the names of the tag key, when the error
+	// is found through the schema (see SchemaField.WirePath), used by
+	// NewProblem. Empty means Path.
+	WirePath []string
+	Err      error
+}
+
+func (e *FieldError) Error() string {
+	return strings.Join(e.Path, DefaultFullNameSeparator) + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors collects the errors of several fields.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap allows errors.Is and errors.As to inspect every field error.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+	return errs
+}
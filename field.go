@@ -12,12 +12,27 @@ import (
 type fielder interface {
 	Name() string
 	FullName(separator string) string
+	FullNameAs(style FullNameStyle) string
 	Path() []string
 	NoName() bool
 	NoValue() bool
 	IsZero() bool
 }
 
+// isZeroer is implemented by types with their own emptiness semantics, e.g. time.Time.
+type isZeroer interface {
+	IsZero() bool
+}
+
+// NamedTagKey is the struct tag key overriding the name of a field, regardless
+// of the tag key used to load its schema, decoupling the logical name (Name,
+// FullName, Path) from the wire name used when encoding:
+//
+//	Email Field[string] `json:"email_address" named:"email"`
+//
+// A named tag of "-" skips the field.
+const NamedTagKey = "named"
+
 // fieldHeader must match with the initial layout of Field[T] and FieldSlice[T,E]
 type fieldHeader struct {
 	path       *[]string
@@ -31,7 +46,24 @@ var TextUnmarshaler = func(data []byte, v any) error {
 	return json.Unmarshal(data, v)
 }
 
-const DefaulyFullNameSeparator = "."
+// DefaultFullNameSeparator is the separator FullName uses when none is given,
+// unless changed with SetDefaultSeparator or per type with WithSeparator.
+const DefaultFullNameSeparator = "."
+
+// Deprecated: use DefaultFullNameSeparator.
+const DefaulyFullNameSeparator = DefaultFullNameSeparator
+
+var defaultSeparator = DefaultFullNameSeparator
+
+// SetDefaultSeparator changes the separator used by FullName("") for every type
+// without its own default. An empty sep restores DefaultFullNameSeparator.
+// not async safe, should be called before any FullName calls.
+func SetDefaultSeparator(sep string) {
+	if sep == "" {
+		sep = DefaultFullNameSeparator
+	}
+	defaultSeparator = sep
+}
 
 func fieldNameOp(pathPtr *[]string) string {
 	if pathPtr == nil || len(*pathPtr) == 0 {
@@ -54,7 +86,10 @@ func stringJoinRawSize(elems []string, sep string) int {
 func fieldFullNameOp(pathPtr, parentPathPtr *[]string, separator string) string {
 
 	if separator == "" {
-		separator = DefaulyFullNameSeparator
+		separator = defaultSeparator
+		if sch := pathSchema(pathPtr); sch != nil && sch.separator != "" {
+			separator = sch.separator
+		}
 	}
 
 	if pathPtr == nil {
@@ -98,6 +133,20 @@ func fieldFullNameOp(pathPtr, parentPathPtr *[]string, separator string) string
 	return unsafe.String(unsafe.SliceData(buf), size)
 }
 
+// quoteJSONScalar wraps encoded strings, numbers and booleans in a JSON string,
+// like encoding/json does for the ",string" option, null being kept as is.
+// It is only called for the fields whose Value is quotable, see pathQuoted.
+func quoteJSONScalar(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	switch c := data[0]; {
+	case c == '"', c == 't', c == 'f', c == '-', c >= '0' && c <= '9':
+		return json.Marshal(string(data))
+	}
+	return data, nil
+}
+
 func fieldNoNameOp(pathPtr *[]string) bool {
 	return pathPtr == nil || len(*pathPtr) == 0
 }
@@ -125,6 +174,7 @@ type Field[T comparable] struct {
 	path       *[]string // goes first so it's aligned with fieldHeader
 	parentPath *[]string // second field, aligned with fieldHeader
 	Value      T
+	present    bool // present in the last decoded document, see Presence
 }
 
 var _ fielder = (*Field[int])(nil) // check interface compliance
@@ -135,12 +185,19 @@ func (f *Field[T]) Name() string {
 }
 
 // FullName returns the full hierarchical path as a separated string.
-// If separator is empty, defaults to ".".
+// If separator is empty, defaults to the separator set for the type with
+// WithSeparator, or else to the global default (".", see SetDefaultSeparator).
 // This provides backward compatibility for users who need the old Name() behavior.
 func (f *Field[T]) FullName(separator string) string {
 	return fieldFullNameOp(f.path, f.parentPath, separator)
 }
 
+// FullNameAs returns the full hierarchical path rendered with style.
+// StyleDefault uses the default style of the field's schema.
+func (f *Field[T]) FullNameAs(style FullNameStyle) string {
+	return fieldFullNameStyleOp(f.path, f.parentPath, style)
+}
+
 // Path returns the complete hierarchical path as a slice.
 // Returns nil if the field has no path information.
 func (f *Field[T]) Path() []string {
@@ -151,7 +208,16 @@ func (f *Field[T]) NoName() bool {
 	return fieldNoNameOp(f.path)
 }
 
+// NoValue reports whether the Value is empty: a checker registered with
+// RegisterZeroChecker is used first, then IsZero() bool if T (or *T) implements it,
+// otherwise Value is compared against the zero value of T.
 func (f *Field[T]) NoValue() bool {
+	if fn, ok := zeroChecker[T](); ok {
+		return fn(f.Value)
+	}
+	if z, ok := any(&f.Value).(isZeroer); ok {
+		return z.IsZero()
+	}
 	var zero T
 	return f.Value == zero
 }
@@ -162,11 +228,26 @@ func (f *Field[T]) IsZero() bool {
 	return f.NoValue()
 }
 
+// MarshalJSON encodes the Value, as a quoted string if the field is tagged
+// with the json ",string" option (requires the struct to be linked).
 func (f Field[T]) MarshalJSON() ([]byte, error) {
-	return json.Marshal(f.Value)
+	data, err := json.Marshal(f.Value)
+	if err != nil || !pathQuoted(f.path) {
+		return data, err
+	}
+	return quoteJSONScalar(data)
 }
 
+// UnmarshalJSON decodes into the Value, accepting quoted input if the field is
+// tagged with the json ",string" option (requires the struct to be linked).
 func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' && pathQuoted(f.path) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
 	return json.Unmarshal(data, &f.Value)
 }
 
@@ -190,6 +271,8 @@ type FieldSlice[T Slice[E], E any] struct {
 	path       *[]string // goes first so it's aligned with fieldHeader
 	parentPath *[]string // second field, aligned with fieldHeader
 	Value      T
+	elems      *elementPaths // paths of the elements, see At
+	present    bool          // present in the last decoded document, see Presence
 }
 
 var _ fielder = (*FieldSlice[[]int, int])(nil) // check interface compliance
@@ -200,12 +283,19 @@ func (f *FieldSlice[T, E]) Name() string {
 }
 
 // FullName returns the full hierarchical path as a separated string.
-// If separator is empty, defaults to ".".
+// If separator is empty, defaults to the separator set for the type with
+// WithSeparator, or else to the global default (".", see SetDefaultSeparator).
 // This provides backward compatibility for users who need the old Name() behavior.
 func (f *FieldSlice[T, E]) FullName(separator string) string {
 	return fieldFullNameOp(f.path, f.parentPath, separator)
 }
 
+// FullNameAs returns the full hierarchical path rendered with style.
+// StyleDefault uses the default style of the field's schema.
+func (f *FieldSlice[T, E]) FullNameAs(style FullNameStyle) string {
+	return fieldFullNameStyleOp(f.path, f.parentPath, style)
+}
+
 // Path returns the complete hierarchical path as a slice.
 // Returns nil if the field has no path information.
 func (f *FieldSlice[T, E]) Path() []string {
@@ -216,7 +306,12 @@ func (f *FieldSlice[T, E]) NoName() bool {
 	return fieldNoNameOp(f.path)
 }
 
+// NoValue reports whether the Value is empty: a checker registered with
+// RegisterZeroChecker is used if any, otherwise whether Value has no elements.
 func (f *FieldSlice[T, E]) NoValue() bool {
+	if fn, ok := zeroChecker[T](); ok {
+		return fn(f.Value)
+	}
 	return len(f.Value) == 0
 }
 
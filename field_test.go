@@ -0,0 +1,138 @@
+package named
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestField_JSONStringOption(t *testing.T) {
+	type sample struct {
+		ID    Field[int64]  `json:"id,string"`
+		Ok    Field[bool]   `json:"ok,string"`
+		Plain Field[int]    `json:"plain"`
+		Name  Field[string] `json:"name,string"`
+	}
+
+	LoadLink[sample]("json")
+
+	s := sample{}
+	Link(&s)
+	s.ID.Value = 42
+	s.Ok.Value = true
+	s.Plain.Value = 1
+	s.Name.Value = "bob"
+
+	data, err := json.Marshal(&s)
+	if err != nil {
+		t.Fatalf("Unexpected error during marshaling: %v", err)
+	}
+
+	expected := `{"id":"42","ok":"true","plain":1,"name":"\"bob\""}`
+	if string(data) != expected {
+		t.Errorf("Expected JSON: %s, got: %s", expected, string(data))
+	}
+
+	out := sample{}
+	Link(&out)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unexpected error during unmarshaling: %v", err)
+	}
+	if out.ID.Value != 42 || !out.Ok.Value || out.Plain.Value != 1 || out.Name.Value != "bob" {
+		t.Errorf("Unexpected round trip result: %+v", out)
+	}
+
+	// unquoted input is still accepted
+	if err := json.Unmarshal([]byte(`{"id":7}`), &out); err != nil || out.ID.Value != 7 {
+		t.Errorf("Expected unquoted input to be accepted, got %d (%v)", out.ID.Value, err)
+	}
+}
+
+type sampleQuotedText string
+
+func (s sampleQuotedText) MarshalText() ([]byte, error) { return []byte("text:" + s), nil }
+
+func (s *sampleQuotedText) UnmarshalText(data []byte) error {
+	*s = sampleQuotedText(strings.TrimPrefix(string(data), "text:"))
+	return nil
+}
+
+func TestField_JSONStringOption_NonScalar(t *testing.T) {
+	type sample struct {
+		At   Field[time.Time]        `json:"at,string"`
+		Text Field[sampleQuotedText] `json:"text,string"`
+		N    Field[*int]             `json:"n,string"`
+		Nil  Field[*int]             `json:"nil,string"`
+	}
+
+	LoadLink[sample]("json")
+
+	s := sample{}
+	Link(&s)
+	s.At.Value = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.Text.Value = "x"
+	n := 3
+	s.N.Value = &n
+
+	data, err := json.Marshal(&s)
+	if err != nil {
+		t.Fatalf("Unexpected error during marshaling: %v", err)
+	}
+
+	// as encoding/json does
+	type plain struct {
+		At   time.Time        `json:"at,string"`
+		Text sampleQuotedText `json:"text,string"`
+		N    *int             `json:"n,string"`
+		Nil  *int             `json:"nil,string"`
+	}
+	expected, err := json.Marshal(plain{s.At.Value, s.Text.Value, s.N.Value, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(expected) {
+		t.Errorf("Expected JSON: %s, got: %s", expected, data)
+	}
+
+	out := sample{}
+	Link(&out)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unexpected error during unmarshaling: %v", err)
+	}
+	if !out.At.Value.Equal(s.At.Value) || out.Text.Value != "x" || out.N.Value == nil || *out.N.Value != 3 || out.Nil.Value != nil {
+		t.Errorf("Unexpected round trip result: %+v", out)
+	}
+}
+
+type sampleCustomZero struct {
+	N int
+}
+
+// IsZero treats negative values as empty
+func (c sampleCustomZero) IsZero() bool {
+	return c.N < 0
+}
+
+func TestField_NoValueDelegatesIsZero(t *testing.T) {
+	loc := time.FixedZone("x", 3600)
+
+	tests := []struct {
+		name     string
+		field    fielder
+		expected bool
+	}{
+		{"time zero", &Field[time.Time]{}, true},
+		{"time zero in other location", &Field[time.Time]{Value: time.Time{}.In(loc)}, true},
+		{"time set", &Field[time.Time]{Value: time.Now()}, false},
+		{"custom empty", &Field[sampleCustomZero]{Value: sampleCustomZero{N: -1}}, true},
+		{"custom zero value is not empty", &Field[sampleCustomZero]{}, false},
+		{"int zero", &Field[int]{}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.field.NoValue(); got != tt.expected {
+			t.Errorf("%s: Expected NoValue() to be %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
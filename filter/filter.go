@@ -0,0 +1,387 @@
+// Package filter parses filter expressions such as
+//
+//	age>=18 AND (address.city=="X" OR NOT is_active==true)
+//
+// validating every identifier against the field paths of a type registered
+// with named.LoadLink, and every literal and operator against the type of
+// its field, so filter strings coming from clients can be checked before any
+// query is built from them.
+package filter
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alvarolm/named"
+)
+
+var (
+	// ErrUnknownField is returned for identifiers that are not a field path of the type.
+	ErrUnknownField = errors.New("filter: unknown field")
+	// ErrForbiddenField is returned for field paths excluded with Allow or Deny.
+	ErrForbiddenField = errors.New("filter: forbidden field")
+	// ErrTypeMismatch is returned for literals or operators that don't fit
+	// the type of their field, e.g. age=="x" or is_active>true.
+	ErrTypeMismatch = errors.New("filter: type mismatch")
+)
+
+// Op is a comparison operator.
+type Op string
+
+const (
+	Eq  Op = "=="
+	Neq Op = "!="
+	Gt  Op = ">"
+	Gte Op = ">="
+	Lt  Op = "<"
+	Lte Op = "<="
+)
+
+// Expr is a node of a parsed filter expression:
+// *And, *Or, *Not or *Comparison.
+type Expr interface {
+	String() string
+	expr()
+}
+
+// And is satisfied when both Left and Right are.
+type And struct {
+	Left, Right Expr
+}
+
+// Or is satisfied when Left or Right is.
+type Or struct {
+	Left, Right Expr
+}
+
+// Not negates X.
+type Not struct {
+	X Expr
+}
+
+// Comparison compares the field at Field (a full path) with Value.
+type Comparison struct {
+	Field string
+	Op    Op
+	Value Literal
+}
+
+// LiteralKind is the type of a Literal.
+type LiteralKind uint8
+
+const (
+	String LiteralKind = iota
+	Number
+	Bool
+	Null
+)
+
+// Literal is a constant of an expression. Value holds a string, float64,
+// bool or nil according to Kind.
+type Literal struct {
+	Kind  LiteralKind
+	Value any
+}
+
+func (*And) expr()        {}
+func (*Or) expr()         {}
+func (*Not) expr()        {}
+func (*Comparison) expr() {}
+
+func (e *And) String() string { return "(" + e.Left.String() + " AND " + e.Right.String() + ")" }
+func (e *Or) String() string  { return "(" + e.Left.String() + " OR " + e.Right.String() + ")" }
+func (e *Not) String() string { return "NOT " + e.X.String() }
+func (e *Comparison) String() string {
+	return e.Field + string(e.Op) + e.Value.String()
+}
+
+func (l Literal) String() string {
+	switch l.Kind {
+	case String:
+		return strconv.Quote(l.Value.(string))
+	case Number:
+		return strconv.FormatFloat(l.Value.(float64), 'g', -1, 64)
+	case Bool:
+		return strconv.FormatBool(l.Value.(bool))
+	}
+	return "null"
+}
+
+// SyntaxError reports a malformed expression.
+type SyntaxError struct {
+	Pos int // byte offset in the expression
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: syntax error at %d: %s", e.Pos, e.Msg)
+}
+
+// Option configures Parse.
+type Option func(*parser)
+
+// Allow restricts the fields usable in the expression to paths.
+func Allow(paths ...string) Option {
+	return func(p *parser) {
+		p.allowed = make(map[string]bool, len(paths))
+		for _, path := range paths {
+			p.allowed[path] = true
+		}
+	}
+}
+
+// Deny forbids the use of the fields at paths in the expression.
+func Deny(paths ...string) Option {
+	return func(p *parser) {
+		for _, path := range paths {
+			p.denied[path] = true
+		}
+	}
+}
+
+// Parse parses expr, resolving its identifiers against the field paths of T,
+// always joined with dots (whatever the separator of T, see named.WithSeparator),
+// and checking its literals and operators against the types of the fields:
+// numbers for the numeric fields, strings for the string fields and the ones
+// encoded as text (e.g. time.Time), true or false compared with == or != for
+// the bool fields, and null compared with == or != for any field.
+// T must be registered with named.LoadLink.
+func Parse[T any](expr string, opts ...Option) (Expr, error) {
+	sch, ok := named.SchemaOf[T]()
+	if !ok {
+		return nil, named.ErrSchemaNotLoaded
+	}
+
+	paths := make([]string, len(sch.Fields))
+	kinds := make(map[string]LiteralKind, len(paths))
+	for i, field := range sch.Fields {
+		paths[i] = strings.Join(field.Path, ".")
+		if field.Type != nil {
+			kinds[paths[i]] = fieldKind(field.Type.Field(2).Type) // Value is at index 2
+		}
+	}
+	return parse(expr, paths, kinds, opts)
+}
+
+// ParsePaths parses expr, resolving its identifiers against paths. The
+// literals are not checked, the types of the fields being unknown.
+func ParsePaths(expr string, paths []string, opts ...Option) (Expr, error) {
+	return parse(expr, paths, nil, opts)
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// fieldKind returns the kind of the literals the values of type t are
+// compared with, Null for the ones compared with null only (structs, slices...).
+func fieldKind(t reflect.Type) LiteralKind {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return String
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return Number
+	case reflect.String:
+		return String
+	}
+	return Null
+}
+
+// kindNames name the field kinds in the errors
+var kindNames = [...]string{String: "string", Number: "number", Bool: "bool", Null: "struct or collection"}
+
+func parse(expr string, paths []string, kinds map[string]LiteralKind, opts []Option) (Expr, error) {
+	p := &parser{
+		lex:    lexer{src: expr},
+		known:  make(map[string]bool, len(paths)),
+		kinds:  kinds,
+		denied: make(map[string]bool),
+	}
+	for _, path := range paths {
+		p.known[path] = true
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokEOF {
+		return nil, &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("unexpected %q", p.tok.text)}
+	}
+	return e, nil
+}
+
+type parser struct {
+	lex     lexer
+	tok     token
+	known   map[string]bool
+	kinds   map[string]LiteralKind // by path, nil if unknown
+	allowed map[string]bool
+	denied  map[string]bool
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	return p.tok.kind == tokIdent && strings.EqualFold(p.tok.text, kw)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.isKeyword("NOT") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+
+	if p.tok.kind == tokLParen {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, &SyntaxError{Pos: p.tok.pos, Msg: "missing )"}
+		}
+		return e, p.next()
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	if p.tok.kind != tokIdent {
+		return nil, &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("expected field, got %q", p.tok.text)}
+	}
+
+	field := p.tok.text
+	switch {
+	case !p.known[field]:
+		return nil, fmt.Errorf("%w %q at %d", ErrUnknownField, field, p.tok.pos)
+	case p.denied[field], p.allowed != nil && !p.allowed[field]:
+		return nil, fmt.Errorf("%w %q at %d", ErrForbiddenField, field, p.tok.pos)
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokOp {
+		return nil, &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("expected operator, got %q", p.tok.text)}
+	}
+	op, opPos := Op(p.tok.text), p.tok.pos
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	lit, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	if kind, ok := p.kinds[field]; ok {
+		ordered := op != Eq && op != Neq
+		switch {
+		case lit.Kind == Null && ordered:
+			return nil, fmt.Errorf("%w: %s null at %d", ErrTypeMismatch, op, opPos)
+		case lit.Kind != Null && lit.Kind != kind:
+			return nil, fmt.Errorf("%w: %s for %s field %q at %d", ErrTypeMismatch, lit, kindNames[kind], field, p.tok.pos)
+		case kind == Bool && ordered:
+			return nil, fmt.Errorf("%w: %s on bool field %q at %d", ErrTypeMismatch, op, field, opPos)
+		}
+	}
+
+	return &Comparison{Field: field, Op: op, Value: lit}, p.next()
+}
+
+func (p *parser) parseLiteral() (Literal, error) {
+	switch p.tok.kind {
+	case tokString:
+		return Literal{Kind: String, Value: p.tok.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return Literal{}, &SyntaxError{Pos: p.tok.pos, Msg: "invalid number " + p.tok.text}
+		}
+		return Literal{Kind: Number, Value: n}, nil
+	case tokIdent:
+		switch strings.ToLower(p.tok.text) {
+		case "true":
+			return Literal{Kind: Bool, Value: true}, nil
+		case "false":
+			return Literal{Kind: Bool, Value: false}, nil
+		case "null":
+			return Literal{Kind: Null}, nil
+		}
+	}
+	return Literal{}, &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("expected value, got %q", p.tok.text)}
+}
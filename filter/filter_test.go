@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvarolm/named"
+)
+
+type address struct {
+	City named.Field[string] `json:"city"`
+}
+
+type person struct {
+	Age      named.Field[int]       `json:"age"`
+	Active   named.Field[bool]      `json:"is_active"`
+	Password named.Field[string]    `json:"password"`
+	Address  named.Field[address]   `json:"address"`
+	Born     named.Field[time.Time] `json:"born"`
+	Score    named.Field[*float64]  `json:"score"`
+}
+
+func init() {
+	named.LoadLink[person]("json")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`age>=18`, `age>=18`},
+		{`age>=18 AND address.city=="X"`, `(age>=18 AND address.city=="X")`},
+		{`age < 3 or is_active == true and address.city != 'a\'b'`, `(age<3 OR (is_active==true AND address.city!="a'b"))`},
+		{`NOT (age==-1.5 OR address.city==null)`, `NOT (age==-1.5 OR address.city==null)`},
+		{`born>="2024-01-01T00:00:00Z" AND score<=0.5 AND address!=null`, `((born>="2024-01-01T00:00:00Z" AND score<=0.5) AND address!=null)`},
+	}
+
+	for _, tt := range tests {
+		e, err := Parse[person](tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): Unexpected error: %v", tt.expr, err)
+		}
+		if e.String() != tt.expected {
+			t.Errorf("Parse(%q): Expected %s, got %s", tt.expr, tt.expected, e.String())
+		}
+	}
+
+	c, _ := Parse[person](`age>=18`)
+	if cmp, ok := c.(*Comparison); !ok || cmp.Field != "age" || cmp.Op != Gte || cmp.Value.Kind != Number || cmp.Value.Value != 18.0 {
+		t.Errorf("Unexpected comparison node: %#v", c)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		opts []Option
+		err  error
+	}{
+		{`name=="x"`, nil, ErrUnknownField},
+		{`password=="x"`, []Option{Deny("password")}, ErrForbiddenField},
+		{`age>1 AND is_active==true`, []Option{Allow("age")}, ErrForbiddenField},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse[person](tt.expr, tt.opts...); !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q): Expected %v, got %v", tt.expr, tt.err, err)
+		}
+	}
+
+	for _, tt := range []struct {
+		expr string
+		pos  string
+	}{
+		{`age=="x"`, `"x" for number field "age" at 5`},
+		{`is_active==1`, `1 for bool field "is_active" at 11`},
+		{`age>1 AND password==true`, `true for string field "password" at 20`},
+		{`born==2024`, `2024 for string field "born" at 6`},
+		{`address=="x"`, `"x" for struct or collection field "address" at 9`},
+		{`is_active>true`, `> on bool field "is_active" at 9`},
+		{`age<null`, `< null at 3`},
+	} {
+		_, err := Parse[person](tt.expr)
+		if !errors.Is(err, ErrTypeMismatch) || !strings.HasSuffix(err.Error(), tt.pos) {
+			t.Errorf("Parse(%q): Expected %v ending with %s, got %v", tt.expr, ErrTypeMismatch, tt.pos, err)
+		}
+		// the kinds are unknown with ParsePaths
+		if _, err := ParsePaths(tt.expr, named.FieldPaths[person]()); err != nil {
+			t.Errorf("ParsePaths(%q): Unexpected error: %v", tt.expr, err)
+		}
+	}
+
+	for _, expr := range []string{``, `age`, `age>=`, `(age>1`, `age>1 extra`, `age=="x`, `age ~ 1`} {
+		var syntaxErr *SyntaxError
+		if _, err := Parse[person](expr); !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q): Expected a SyntaxError, got %v", expr, err)
+		}
+	}
+
+	type unknown struct{}
+	if _, err := Parse[unknown](`a==1`); !errors.Is(err, named.ErrSchemaNotLoaded) {
+		t.Errorf("Expected ErrSchemaNotLoaded, got %v", err)
+	}
+}
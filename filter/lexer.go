@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string // unquoted for strings
+	pos  int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func isIdentByte(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c == '.', c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && strings.IndexByte(" \t\r\n", l.src[l.pos]) != -1 {
+		l.pos++
+	}
+
+	start := l.pos
+	if start == len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.src[start]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case c == '"' || c == '\'':
+		return l.lexString(c)
+	case c == '-' || c >= '0' && c <= '9':
+		l.pos++
+		for l.pos < len(l.src) && strings.IndexByte("0123456789.eE+-", l.src[l.pos]) != -1 {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+	case isIdentByte(c, true):
+		for l.pos < len(l.src) && isIdentByte(l.src[l.pos], false) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	}
+
+	for _, op := range []Op{Eq, Neq, Gte, Lte, Gt, Lt} {
+		if strings.HasPrefix(l.src[start:], string(op)) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: string(op), pos: start}, nil
+		}
+	}
+
+	return token{}, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", c)}
+}
+
+func (l *lexer) lexString(quote byte) (token, error) {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+		case quote:
+			l.pos++
+			raw := l.src[start:l.pos]
+			if quote == '\'' {
+				// reuse strconv rules with double quotes
+				raw = `"` + strings.ReplaceAll(strings.ReplaceAll(raw[1:len(raw)-1], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			text, err := strconv.Unquote(raw)
+			if err != nil {
+				return token{}, &SyntaxError{Pos: start, Msg: "invalid string " + l.src[start:l.pos]}
+			}
+			return token{kind: tokString, text: text, pos: start}, nil
+		}
+		l.pos++
+	}
+	return token{}, &SyntaxError{Pos: start, Msg: "unterminated string"}
+}
@@ -0,0 +1,137 @@
+package named
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// FromMap populates the Field members of s from m, keyed by their names for
+// tagKey, the inverse of ToMap. Nested maps populate fields holding nested
+// fields, keys without a matching field are ignored.
+//
+// Values are converted to the field type when possible: numbers between numeric
+// types (floats into integers only when integral and in range), strings into
+// numbers and booleans, strings into types implementing encoding.TextUnmarshaler
+// (e.g. RFC 3339 into time.Time), []any into slices and nil into the zero value.
+// Mismatches are returned as FieldErrors, the other fields are still populated.
+// T must be registered with LoadLink for tagKey.
+func FromMap[T any](m map[string]any, s *T, tagKey string) error {
+	sch, ok := loadTagSchema[T](tagKey)
+	if !ok {
+		return fmt.Errorf("%w: tag key %q", ErrSchemaNotLoaded, tagKey)
+	}
+
+	var errs FieldErrors
+	sch.fromMap(m, unsafe.Pointer(s), 0, len(sch.fields), 0, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (sch *schema) fromMap(m map[string]any, ptr unsafe.Pointer, start, end, depth int, errs *FieldErrors) {
+	for key, src := range m {
+		i := sch.childByName(start, end, depth, key)
+		if i == -1 {
+			continue
+		}
+		field := &sch.fields[i]
+
+		if nested, ok := src.(map[string]any); ok {
+			if sub := sch.subtreeEnd(i); sub > i+1 {
+				sch.fromMap(nested, ptr, i+1, sub, depth+1, errs)
+				continue
+			}
+		}
+
+		if err := assignValue(field.value(ptr), src); err != nil {
+			*errs = append(*errs, field.error(err))
+		}
+	}
+}
+
+// assignValue stores src into the addressable dst, converting it if needed.
+func assignValue(dst reflect.Value, src any) error {
+	if src == nil {
+		dst.SetZero()
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	dt := dst.Type()
+
+	if sv.Type().AssignableTo(dt) {
+		dst.Set(sv)
+		return nil
+	}
+
+	if s, ok := src.(string); ok {
+		return setFromString(dst, s)
+	}
+
+	switch dt.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(dt.Elem())
+		if err := assignValue(elem.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt64(sv)
+		if !ok || dst.OverflowInt(n) {
+			return fmt.Errorf("cannot convert %v (%T) to %s", src, src, dt)
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := toInt64(sv)
+		if !ok || n < 0 || dst.OverflowUint(uint64(n)) {
+			return fmt.Errorf("cannot convert %v (%T) to %s", src, src, dt)
+		}
+		dst.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if sv.CanInt() || sv.CanUint() || sv.CanFloat() {
+			dst.Set(sv.Convert(dt))
+			return nil
+		}
+	case reflect.Slice:
+		if sv.Kind() == reflect.Slice {
+			out := reflect.MakeSlice(dt, sv.Len(), sv.Len())
+			for i := range sv.Len() {
+				if err := assignValue(out.Index(i), sv.Index(i).Interface()); err != nil {
+					return fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			dst.Set(out)
+			return nil
+		}
+	}
+
+	if sv.Type().ConvertibleTo(dt) && sv.Kind() == dt.Kind() {
+		dst.Set(sv.Convert(dt))
+		return nil
+	}
+
+	return fmt.Errorf("cannot assign %T to %s", src, dt)
+}
+
+// toInt64 converts integers, and floats without fractional part, to int64.
+func toInt64(v reflect.Value) (int64, bool) {
+	switch {
+	case v.CanInt():
+		return v.Int(), true
+	case v.CanUint():
+		return int64(v.Uint()), v.Uint() <= math.MaxInt64
+	case v.CanFloat():
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
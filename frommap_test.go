@@ -0,0 +1,74 @@
+package named
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type sampleFromMap struct {
+	Count   Field[int]                   `json:"count"`
+	Small   Field[uint8]                 `json:"small"`
+	Ratio   Field[float64]               `json:"ratio"`
+	When    Field[time.Time]             `json:"when"`
+	Active  Field[bool]                  `json:"active"`
+	Tags    FieldSlice[[]string, string] `json:"tags"`
+	Ptr     Field[*int]                  `json:"ptr"`
+	Address Field[sampleBindAddress]     `json:"address"`
+}
+
+func init() {
+	LoadLink[sampleFromMap]("json")
+}
+
+func TestFromMap(t *testing.T) {
+	s := sampleFromMap{}
+	err := FromMap(map[string]any{
+		"count":   "42",
+		"small":   float64(7),
+		"ratio":   3,
+		"when":    "2024-01-02T03:04:05Z",
+		"active":  "true",
+		"tags":    []any{"a", "b"},
+		"ptr":     float64(5),
+		"address": map[string]any{"city": "x"},
+		"unknown": 1,
+	}, &s, "json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.Count.Value != 42 || s.Small.Value != 7 || s.Ratio.Value != 3 || !s.Active.Value {
+		t.Errorf("Unexpected scalar values: %+v", s)
+	}
+	if !s.When.Value.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected time %v", s.When.Value)
+	}
+	if len(s.Tags.Value) != 2 || s.Tags.Value[1] != "b" {
+		t.Errorf("Unexpected tags %v", s.Tags.Value)
+	}
+	if s.Ptr.Value == nil || *s.Ptr.Value != 5 {
+		t.Errorf("Unexpected pointer value %v", s.Ptr.Value)
+	}
+	if s.Address.Value.City.Value != "x" {
+		t.Errorf("Unexpected nested value %q", s.Address.Value.City.Value)
+	}
+
+	t.Run("Mismatches", func(t *testing.T) {
+		s := sampleFromMap{}
+		err := FromMap(map[string]any{
+			"count":   1.5,
+			"small":   300,
+			"address": map[string]any{"city": []any{1}},
+			"ratio":   2,
+		}, &s, "json")
+
+		var errs FieldErrors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatalf("Expected 3 field errors, got %v", err)
+		}
+		if s.Ratio.Value != 2 {
+			t.Error("Expected valid fields to be populated despite errors")
+		}
+	})
+}
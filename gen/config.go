@@ -0,0 +1,304 @@
+package gen
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFiles are the names of the configuration file, at the module root
+var configFiles = []string{"named.yaml", "named.toml"}
+
+// config is the configuration file of a module, declaring directives as an
+// alternative to GENERATE-NAMED comments, which take precedence:
+//
+//	exclude: [mocks]
+//	directives:
+//	  - structs: "*"
+//	    packages: internal/models/...
+//	    tagKey: db
+//	    exclude: [Migration]
+type config struct {
+	Directives []configDirective `yaml:"directives" toml:"directives"`
+	Exclude    []string          `yaml:"exclude" toml:"exclude"`     // as -exclude
+	Header     string            `yaml:"header" toml:"header"`       // lines added to the header of the generated files, as the -header file
+	BuildTags  string            `yaml:"buildTags" toml:"buildTags"` // build constraint of the generated files, as -tags
+}
+
+// configDirective applies to the exported structs matching Structs, in the
+// packages matching Packages, that have no comment directive. The first
+// matching directive of the file applies.
+type configDirective struct {
+	Structs   string   `yaml:"structs" toml:"structs"`   // struct name glob pattern, e.g. "*" or "*Request"
+	Packages  string   `yaml:"packages" toml:"packages"` // package directory relative to the module root, glob pattern or "dir/..." for a tree, empty for all
+	TagKey    string   `yaml:"tagKey" toml:"tagKey"`
+	Output    string   `yaml:"output" toml:"output"`       // as the Output option
+	Exclude   []string `yaml:"exclude" toml:"exclude"`     // struct names left out
+	Template  string   `yaml:"template" toml:"template"`   // relative to the package, as the Template option
+	Fallback  string   `yaml:"fallback" toml:"fallback"`   // as the Fallback option
+	TagFormat string   `yaml:"tagFormat" toml:"tagFormat"` // as the TagFormat option
+	Table     string   `yaml:"table" toml:"table"`         // as the Table option
+	Alias     string   `yaml:"alias" toml:"alias"`         // as the Alias option
+	Mongo     string   `yaml:"mongo" toml:"mongo"`         // as the Mongo option
+	FieldType string   `yaml:"fieldType" toml:"fieldType"` // as the FieldType option
+	Interface string   `yaml:"interface" toml:"interface"` // as the Interface option
+
+	VarSuffix    string `yaml:"varSuffix" toml:"varSuffix"`
+	TypePrefix   string `yaml:"typePrefix" toml:"typePrefix"`
+	NamedMethod  string `yaml:"namedMethod" toml:"namedMethod"`   // as the NamedMethod option
+	FieldInfos   string `yaml:"fieldInfos" toml:"fieldInfos"`     // as the FieldInfos option
+	Listing      string `yaml:"listing" toml:"listing"`           // as the Listing option
+	TagOptions   string `yaml:"tagOptions" toml:"tagOptions"`     // as the TagOptions option
+	Patch        string `yaml:"patch" toml:"patch"`               // as the Patch option
+	Schema       string `yaml:"schema" toml:"schema"`             // as the Schema option
+	Pointers     string `yaml:"pointers" toml:"pointers"`         // as the Pointers option
+	StructFields string `yaml:"structFields" toml:"structFields"` // as the StructFields option
+	Rules        string `yaml:"rules" toml:"rules"`               // as the Rules option
+	Rename       string `yaml:"rename" toml:"rename"`             // as the Rename option, e.g. Password=pwd_hash|Email=mail
+	Qualified    string `yaml:"qualified" toml:"qualified"`       // as the Qualified option
+
+	IncludeUnexported string `yaml:"includeUnexported" toml:"includeUnexported"` // as the IncludeUnexported option
+}
+
+var (
+	configs   = make(map[string]*config) // by module root
+	configsMu sync.Mutex
+)
+
+// moduleRoot returns the directory of the go.mod file of the module of dir,
+// empty if not in a module
+func moduleRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil {
+			return root
+		}
+		if filepath.Dir(root) == root {
+			return ""
+		}
+	}
+}
+
+// loadConfig returns the configuration of the module root, read once, nil if
+// it has no configuration file
+func loadConfig(root string) (*config, error) {
+	configsMu.Lock()
+	defer configsMu.Unlock()
+
+	if cfg, ok := configs[root]; ok {
+		return cfg, nil
+	}
+
+	var cfg *config
+	for _, name := range configFiles {
+		filename := filepath.Join(root, name)
+		data, err := os.ReadFile(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cfg != nil {
+			return nil, fmt.Errorf("%s: both %s and %s found", root, configFiles[0], configFiles[1])
+		}
+		if cfg, err = parseConfig(name, data); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		logVerbose("Loaded configuration: %s", filename)
+	}
+
+	configs[root] = cfg
+	return cfg, nil
+}
+
+// parseConfig parses the configuration file name, rejecting unknown keys
+func parseConfig(name string, data []byte) (*config, error) {
+	cfg := &config{}
+	if filepath.Ext(name) == ".toml" {
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("unknown key %s", undecoded[0])
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	}
+
+	for _, pattern := range cfg.Exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %v", pattern, err)
+		}
+	}
+	if _, err := withBuildTags("", cfg.BuildTags); err != nil {
+		return nil, err
+	}
+	for i, d := range cfg.Directives {
+		if _, err := path.Match(d.Structs, ""); err != nil || d.Structs == "" {
+			return nil, fmt.Errorf("directive %d: invalid structs pattern %q", i+1, d.Structs)
+		}
+		if _, err := path.Match(strings.TrimSuffix(d.Packages, "/..."), ""); err != nil {
+			return nil, fmt.Errorf("directive %d: invalid packages pattern %q", i+1, d.Packages)
+		}
+		if cfg.Directives[i].TagKey == "" {
+			cfg.Directives[i].TagKey = defaultTagKey
+		}
+	}
+	return cfg, nil
+}
+
+// loadConfigExcludes adds the exclude patterns of the configuration of the
+// module of path to the -exclude ones, before processing path
+func loadConfigExcludes(path string) error {
+	root := moduleRoot(path)
+	if root == "" {
+		return nil
+	}
+	cfg, err := loadConfig(root)
+	if err != nil || cfg == nil {
+		return err
+	}
+	for _, pattern := range cfg.Exclude {
+		if !slices.Contains(excludes, pattern) {
+			excludes = append(excludes, pattern)
+		}
+	}
+	return nil
+}
+
+// fileSettings returns the header lines (commented) and build tags of the
+// files generated into the package at dir: the -header and -tags flags, or
+// else the ones of the configuration file of its module
+func fileSettings(dir string) (header, tags string, err error) {
+	header, tags = headerText, buildTags
+	if root := moduleRoot(dir); root != "" && (header == "" || tags == "") {
+		cfg, err := loadConfig(root)
+		if err != nil {
+			return "", "", err
+		}
+		if cfg != nil {
+			header, tags = cmp.Or(header, cfg.Header), cmp.Or(tags, cfg.BuildTags)
+		}
+	}
+	return commentLines(header), tags, nil
+}
+
+// commentLines returns the lines of text as line comments, those not already
+// comments prefixed by "// "
+func commentLines(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "//"):
+		case strings.TrimSpace(line) == "":
+			lines[i] = "//"
+		default:
+			lines[i] = "// " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addConfigDirectives adds to dirs the directives of the configuration file
+// of the package at dir matching structNames, the structs without comment
+// directive
+func addConfigDirectives(dirs directives, dir string, structNames []string) error {
+	root := moduleRoot(dir)
+	if root == "" {
+		return nil
+	}
+	cfg, err := loadConfig(root)
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	pkgPath := "."
+	if abs, err := filepath.Abs(dir); err == nil {
+		if rel, err := filepath.Rel(root, abs); err == nil {
+			pkgPath = filepath.ToSlash(rel)
+		}
+	}
+
+	for _, structName := range structNames {
+		if _, found := dirs.lookup(structName); found || !ast.IsExported(structName) {
+			continue
+		}
+		for _, d := range cfg.Directives {
+			if !d.matches(pkgPath, structName) {
+				continue
+			}
+			logVerbose("Found config directive for %s: %s (TagKey: %s)", structName, d.Structs, d.TagKey)
+			dirs[structName] = directive{
+				tagKey:    d.TagKey,
+				output:    d.Output,
+				template:  d.Template,
+				fallback:  d.Fallback,
+				tagFormat: d.TagFormat,
+				table:     d.Table,
+				alias:     d.Alias,
+				mongo:     d.Mongo,
+				fieldType: d.FieldType,
+				iface:     d.Interface,
+				method:    d.NamedMethod,
+				config:    d.Structs,
+
+				varSuffix:    d.VarSuffix,
+				typePrefix:   d.TypePrefix,
+				fieldInfos:   d.FieldInfos,
+				listing:      d.Listing,
+				tagOptions:   d.TagOptions,
+				patch:        d.Patch,
+				schema:       d.Schema,
+				pointers:     d.Pointers,
+				structFields: d.StructFields,
+				rules:        d.Rules,
+				rename:       d.Rename,
+				qualified:    d.Qualified,
+				unexported:   d.IncludeUnexported,
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// matches reports whether d applies to the struct structName of the package
+// at pkgPath, relative to the module root
+func (d configDirective) matches(pkgPath, structName string) bool {
+	if ok, _ := path.Match(d.Structs, structName); !ok || slices.Contains(d.Exclude, structName) {
+		return false
+	}
+	if tree, ok := strings.CutSuffix(d.Packages, "/..."); ok {
+		return pkgPath == tree || strings.HasPrefix(pkgPath, tree+"/") || tree == "."
+	}
+	if d.Packages == "" {
+		return true
+	}
+	ok, _ := path.Match(d.Packages, pkgPath)
+	return ok
+}
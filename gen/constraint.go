@@ -0,0 +1,111 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"path/filepath"
+	"strings"
+)
+
+// known GOOS and GOARCH values, for the file name constraints (see go/build)
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+		"illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true, "openbsd": true,
+		"plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true, "arm64be": true,
+		"loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true,
+		"mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true, "riscv64": true,
+		"s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// buildConstraint returns the build constraint of a Go file, combining its
+// //go:build (or legacy // +build) lines with the constraints implied by its
+// name (e.g. user_linux.go), empty if unconstrained. The generated files carry
+// it, so every variant of a constrained struct gets its own accessors.
+func buildConstraint(filename string, file *ast.File) string {
+	var exprs []constraint.Expr
+
+	// constraint lines precede the package clause
+	var plusBuild []constraint.Expr
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			switch {
+			case constraint.IsGoBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					exprs = append(exprs, expr)
+				}
+			case constraint.IsPlusBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					plusBuild = append(plusBuild, expr)
+				}
+			}
+		}
+	}
+	// // +build lines only matter without //go:build line
+	if len(exprs) == 0 {
+		exprs = plusBuild
+	}
+
+	exprs = append(exprs, fileNameConstraint(filename)...)
+	if len(exprs) == 0 {
+		return ""
+	}
+
+	expr := exprs[0]
+	for _, next := range exprs[1:] {
+		expr = &constraint.AndExpr{X: expr, Y: next}
+	}
+	return expr.String()
+}
+
+// withBuildTags returns the build constraint c of a generated file combined
+// with the build constraint expression tags (e.g. "integration && !js")
+func withBuildTags(c, tags string) (string, error) {
+	if tags == "" {
+		return c, nil
+	}
+	expr, err := constraint.Parse("//go:build " + tags)
+	if err != nil {
+		return "", fmt.Errorf("invalid build tags %q: %v", tags, err)
+	}
+	if c != "" {
+		fileExpr, err := constraint.Parse("//go:build " + c)
+		if err != nil {
+			return "", err
+		}
+		expr = &constraint.AndExpr{X: fileExpr, Y: expr}
+	}
+	return expr.String(), nil
+}
+
+// fileNameConstraint returns the GOOS and GOARCH tags implied by the name of a
+// file: name_GOOS, name_GOARCH or name_GOOS_GOARCH (test suffix excluded)
+func fileNameConstraint(filename string) []constraint.Expr {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	name = strings.TrimSuffix(name, "_test")
+
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return nil
+	}
+
+	last := parts[len(parts)-1]
+	if len(parts) >= 3 && knownOS[parts[len(parts)-2]] && knownArch[last] {
+		return []constraint.Expr{
+			&constraint.TagExpr{Tag: parts[len(parts)-2]},
+			&constraint.TagExpr{Tag: last},
+		}
+	}
+	if knownOS[last] || knownArch[last] {
+		return []constraint.Expr{&constraint.TagExpr{Tag: last}}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes of a hunk
+const diffContext = 3
+
+// diffOp is a line of an edit script: kept (' '), removed ('-') or added ('+')
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff returns the unified diff (as diff -u) turning old, the file
+// oldName, into new, the file newName, empty if they're equal. A missing file
+// is diffed as the empty /dev/null.
+func unifiedDiff(oldName, newName string, old, new []byte) string {
+	if string(old) == string(new) {
+		return ""
+	}
+	ops := diffLines(splitLines(string(old)), splitLines(string(new)))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// next change, and the end of its hunk: the changes separated by
+		// less than twice the context
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			} else if i-last > 2*diffContext {
+				break
+			}
+		}
+		from, to := max(first-diffContext, start), min(last+diffContext+1, len(ops))
+
+		// line numbers of the hunk, counted from the start of the script
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, op := range ops[from:to] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.text)
+		}
+		start = to
+	}
+	return b.String()
+}
+
+// hunkRange formats the line range of a hunk, the line before an empty range
+func hunkRange(line, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line-1)
+	case 1:
+		return fmt.Sprint(line)
+	default:
+		return fmt.Sprintf("%d,%d", line, count)
+	}
+}
+
+// splitLines splits text into lines, keeping their newline, a last line
+// without one marked as diff does
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n\\ No newline at end of file\n"
+	return lines
+}
+
+// diffLines returns the edit script turning the lines a into b, from their
+// longest common subsequence, past their common prefix and suffix
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	// lcs[i][j] is the length of the common subsequence of x[i:] and y[j:]
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			ops = append(ops, diffOp{' ', x[i]})
+			i, j = i+1, j+1
+		case j == len(y) || i < len(x) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', x[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', y[j]})
+			j++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
@@ -0,0 +1,2588 @@
+// Package gen generates the type-safe field name accessors of the structs
+// annotated with GENERATE-NAMED directives, as the generate-named command
+// does: Generate writes (or checks) the generated files of paths, Scan
+// returns the annotated structs without writing anything, and Run is the
+// whole command, modes included. Runs are serialized.
+//
+//	report, err := gen.Generate(gen.Options{Typed: true, KeepGoing: true}, "./...")
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/imports"
+)
+
+const (
+	generatedFileSuffix = "_named_generated.go"
+	testFileSuffix      = "_test.go"
+	generatedTestSuffix = "_named_generated_test.go" // generated from test files, with -tests
+	consolidatedPrefix  = "zz"                       // e.g. zz_named_generated.go, with -consolidate
+	defaultTagKey       = "json"
+	directivePrefix     = "GENERATE-NAMED="
+	docDirectivePrefix  = "GENERATE-NAMED" // in the doc comment of a struct, e.g. "// GENERATE-NAMED TagKey:db"
+	structNameKey       = "StructName"
+	tagKeyKey           = "TagKey"
+	excludeKey          = "Exclude" // struct names left out of a wildcard directive, separated by "|"
+	wildcardStructName  = "*"
+	namedTagKey         = "named"
+	outputKey           = "Output"
+	templateKey         = "Template"     // text/template file rendering the generated file, relative to the package
+	fallbackKey         = "Fallback"     // case of the names of the untagged fields, see fallbackName
+	tagFormatKey        = "TagFormat"    // format of the tag values, see tagParsers
+	tableKey            = "Table"        // SQL table, generating the table and columns functions
+	aliasKey            = "Alias"        // SQL alias of the table, see structQualified
+	qualifiedKey        = "Qualified"    // generates methods returning the qualified names, e.g. EmailQualified
+	mongoKey            = "Mongo"        // generates the MongoDB projection and sort builders, see mongoImports
+	fieldTypeKey        = "FieldType"    // generates a string type of the field names, e.g. UserField
+	interfaceKey        = "Interface"    // generates an interface of the accessor, e.g. UserNamer
+	methodKey           = "NamedMethod"  // generates a Named method of the struct returning its accessor
+	fieldInfosKey       = "FieldInfos"   // generates a function describing the fields, with their Go type
+	listingKey          = "Listing"      // generates the Fields and All methods of the accessors, listing the fields
+	tagOptionsKey       = "TagOptions"   // generates the Options method of the accessor, returning the tag options of a field
+	patchKey            = "Patch"        // generates a patch struct of named.Field, see patch.go
+	schemaKey           = "Schema"       // registers the schema of the named.Field members, see schema.go
+	pointersKey         = "Pointers"     // generates JSON Pointer constants, e.g. UserPointer_Email
+	structFieldsKey     = "StructFields" // generates STRUCT field name constants, e.g. UserStruct_Address_City
+	rulesKey            = "Rules"        // generates methods returning the validation rules, e.g. EmailRules
+	defaultRulesTagKey  = "validate"     // of Rules:true, as go-playground/validator
+	varSuffixKey        = "VarSuffix"    // suffix of the accessor variable and maps, e.g. OrderNamed
+	typePrefixKey       = "TypePrefix"   // prefix of the accessor type, lowercasing the struct name otherwise
+	defaultVarSuffix    = "Named"
+	namedImportPath     = "github.com/alvarolm/named"
+	stdinPath           = "-" // reads the source from stdin, writes the generated code to stdout
+
+	// also names the unexported fields, skipped otherwise
+	unexportedKey = "IncludeUnexported"
+	// names of top level fields overriding their tag, e.g. Rename:Password=pwd_hash|Email=mail
+	renameKey = "Rename"
+
+	// first line of the generated files, the same on every run and machine:
+	// no version, date or path
+	generatedHeader = "// Code generated by generate-named. DO NOT EDIT."
+	// starts the line following the generated header, see withContentHash
+	contentHashPrefix = "// Content hash: sha256:"
+)
+
+// method of the accessor types with the TagOptions option, a field of the
+// same name can't be accessed
+const tagOptionsName = "Options"
+
+// methods of the accessor types with the Listing option, nested ones included
+var listingNames = map[string]bool{"Fields": true, "All": true}
+
+// methods of the nested accessor types
+var reservedNestedNames = map[string]bool{"String": true, "Path": true}
+
+// Output modes, set with the Output directive option or the -output flag
+const (
+	outputMethods = "methods" // accessor struct with a method per field
+	outputConsts  = "consts"  // a constant per field, e.g. UserName_Email
+	outputBoth    = "both"
+)
+
+type structInfo struct {
+	name       string
+	tagKey     string
+	fields     []fieldInfo
+	pkgName    string
+	output     string
+	constraint string // build constraint of the source file, carried by the generated file
+	outDir     string // directory of the package generated into, relative to the source one
+	outFile    string // name of the generated file, from the Output option, instead of the default one
+	directive  string // key of the matching directive: the struct name, or the wildcard
+	template   string // template file, empty for the default template
+	varSuffix  string // see structAffixes
+	typePrefix string
+	table      string            // SQL table of the struct, if any
+	alias      string            // SQL alias of the table, with the Qualified option
+	qualified  bool              // methods of the names qualified by the table or alias
+	mongo      string            // import path of the bson package, with MongoDB helpers
+	fieldType  bool              // field names typed as <name>Field
+	iface      bool              // accessor interface <name>Namer
+	method     bool              // Named method of the struct
+	fieldInfos bool              // <name>FieldInfos function
+	listing    bool              // Fields and All methods of the accessors
+	tagOptions bool              // Options method of the accessor
+	patch      bool              // <name>Patch struct and converters
+	imports    map[string]string // of the patch, to their name if it differs from their package name, by path
+	schema     []schemaField     // with the Schema option
+	pointers   bool              // JSON Pointer constants
+	structs    bool              // STRUCT field name constants of the nested fields
+	rules      string            // tag key of the validation rules, with the Rules option
+	typeParams []string          // names of the type parameters of a generic struct
+}
+
+// directive holds the options of a GENERATE-NAMED directive
+type directive struct {
+	tagKey    string
+	exclude   string // raw Exclude value, keeps directive comparable
+	output    string // empty for the -output flag default
+	template  string // empty for the -template flag default
+	fallback  string // empty for the default of the tag key, see defaultFallbacks
+	tagFormat string // empty for the default of the tag key, see defaultTagFormats
+	table     string // empty without SQL helpers
+	alias     string // empty without aliased names, see structQualified
+	mongo     string // empty without MongoDB helpers
+	rules     string // empty without validation rules, see structRules
+	rename    string // raw Rename value, see parseRenames
+	config    string // structs pattern of the configuration file directive, see addConfigDirectives
+
+	varSuffix, typePrefix string // empty for the defaults, see structAffixes
+
+	// true or false, see boolOption
+	fieldType, iface, method, fieldInfos, listing, tagOptions, patch, schema, pointers, structFields, unexported, qualified string
+}
+
+// directives maps struct names (or the wildcard) to their directive
+type directives map[string]directive
+
+// lookup returns the directive of the struct named name: its own one, or else
+// the wildcard one for exported structs not excluded from it
+func (d directives) lookup(name string) (directive, bool) {
+	if dir, ok := d[name]; ok {
+		return dir, true
+	}
+	dir, ok := d[wildcardStructName]
+	if !ok || !ast.IsExported(name) {
+		return directive{}, false
+	}
+	for _, excluded := range strings.Split(dir.exclude, "|") {
+		if strings.TrimSpace(excluded) == name {
+			return directive{}, false
+		}
+	}
+	return dir, true
+}
+
+// key returns the key of the directive returned by lookup for name, or the
+// structs pattern of a configuration file directive
+func (d directives) key(name string) string {
+	if dir, ok := d[name]; ok {
+		if dir.config != "" {
+			return dir.config
+		}
+		return name
+	}
+	return wildcardStructName
+}
+
+type fieldInfo struct {
+	name     string
+	tagName  string
+	path     []string    // tag names from the annotated struct
+	options  []string    // options of the tag following the name, e.g. omitempty
+	children []fieldInfo // fields of a nested struct of the package
+
+	goType         string // Go type as declared, e.g. *Customer
+	pointer, slice bool   // the type is a pointer, a slice (or pointer to a slice)
+
+	// for the Patch option, see patch.go
+	tag        string            // struct tag
+	elemType   string            // element type of a slice type
+	comparable bool              // may be wrapped by named.Field
+	pkgNames   []string          // packages referred to by goType, without -typed
+	imports    map[string]string // packages referred to by goType, by name, with -typed
+}
+
+var (
+	clean  bool
+	check  bool
+	watch  bool
+	typed  bool
+	tests  bool
+	output string
+	outpkg string
+
+	// a generated file per package, rather than per source file
+	consolidate bool
+
+	// glob patterns of the directories and files to skip, see excluded
+	excludes patternList
+
+	// names of the directories skipped by the walkers, see -prune
+	pruned = map[string]bool{"vendor": true, "testdata": true, "node_modules": true}
+
+	// descend into the modules nested in the one of a path, see walkPackages
+	allModules bool
+
+	// descend into the symlinked directories, see walkPackages
+	followSymlinks bool
+
+	// packages processed concurrently, see processDirs
+	jobs int
+
+	// record the failures and continue, see keepGoingErr
+	keepGoing bool
+
+	// print a JSON report rather than progress lines, see report
+	jsonReport bool
+
+	// warn about fields sharing a name rather than failing, see resolveCandidates
+	warnDuplicates bool
+
+	// default template of the generated files, see loadTemplate
+	templatePath string
+
+	// header lines and build constraint of the generated files, see
+	// fileSettings
+	headerText, buildTags string
+
+	// bounds the goroutines scanning files, across packages
+	scanSlots = make(chan struct{}, runtime.GOMAXPROCS(0))
+
+	// generated files differing from the files on disk, in check mode
+	staleFiles []string
+	staleMu    sync.Mutex
+
+	// print the differences of the stale files, in check mode, see
+	// unifiedDiff
+	diffMode bool
+
+	// suffix of the generated files, see outputFileFor
+	fileSuffix = generatedFileSuffix
+
+	// remove the generated files no source file generates anymore, see
+	// cleanOrphans
+	orphans bool
+
+	// files that would be generated, with -clean-orphans
+	expectedFiles   = make(map[string]bool)
+	expectedFilesMu sync.Mutex
+
+	// collect the structs without writing the files, see Scan
+	scanOnly bool
+)
+
+// patternList is a list of glob patterns, checked when added
+type patternList []string
+
+func (p *patternList) String() string { return strings.Join(*p, ",") }
+
+func (p *patternList) Set(pattern string) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	*p = append(*p, pattern)
+	return nil
+}
+
+// setPrune updates the pruned directory names from a -prune value: names
+// separated by commas, added or, when prefixed by "-", removed
+func setPrune(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if remove, ok := strings.CutPrefix(name, "-"); ok {
+			delete(pruned, remove)
+			continue
+		}
+		name = strings.TrimPrefix(name, "+")
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid directory name %q", name)
+		}
+		pruned[name] = true
+	}
+	return nil
+}
+
+// excluded reports whether path matches an -exclude pattern: its base name
+// (e.g. "*_mock.go") or trailing elements (e.g. "internal/fixtures"), or is
+// ignored by a .namedignore file
+func excluded(path string) bool {
+	elems := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	for _, pattern := range excludes {
+		for i := range elems {
+			if ok, _ := filepath.Match(pattern, strings.Join(elems[i:], "/")); ok {
+				return true
+			}
+		}
+	}
+	return ignored(path)
+}
+
+// walkGoPackages recursively walks directories and calls fn for each directory
+// that could be a Go package (contains .go files, not hidden, pruned or excluded, not following symlinks
+// unless -follow-symlinks).
+// The directories of other modules than the one of root (with their own
+// go.mod file) are skipped, unless -all-modules. A workspace root (with a
+// go.work file) is walked as the modules it uses, each one without the nested
+// modules.
+func walkGoPackages(root string, fn func(string) error) error {
+	modules, err := workspaceModules(root)
+	if err != nil {
+		return err
+	}
+	visited := make(map[string]bool)
+	if modules == nil {
+		return walkPackages(root, !allModules && moduleRoot(root) != "", visited, fn)
+	}
+	for _, module := range modules {
+		logVerbose("Processing workspace module: %s", module)
+		if err := loadConfigExcludes(module); err != nil {
+			return fmt.Errorf("error loading the configuration: %v", err)
+		}
+		if err := loadIgnoreFile(module); err != nil {
+			return fmt.Errorf("error loading %s: %v", ignoreFileName, err)
+		}
+		if err := walkPackages(module, true, visited, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkPackages walks root as walkGoPackages, skipping the directories of
+// other modules if boundary is set: within a module, outside of a workspace
+// unless -all-modules
+func walkPackages(root string, boundary bool, visited map[string]bool, fn func(string) error) error {
+	info, err := os.Lstat(root) // Use Lstat to not follow symlinks
+	if err != nil {
+		return err
+	}
+
+	// Don't follow symlinks, unless -follow-symlinks
+	if info.Mode()&os.ModeSymlink != 0 {
+		if !followSymlinks {
+			logVerbose("Skipping symlink: %s", root)
+			return nil
+		}
+		if info, err = os.Stat(root); err != nil {
+			logVerbose("Skipping broken symlink: %s", root)
+			return nil
+		}
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	// Skip the directories already walked through another symlink, breaking
+	// the cycles
+	if followSymlinks {
+		first, err := firstVisit(visited, root)
+		if err != nil {
+			return err
+		}
+		if !first {
+			logVerbose("Skipping visited directory: %s", root)
+			return nil
+		}
+	}
+
+	// Skip hidden directories
+	if root != "." && strings.HasPrefix(filepath.Base(root), ".") {
+		logVerbose("Skipping hidden directory: %s", root)
+		return nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	// Check if this directory has .go files (potential Go package)
+	hasGoFiles := false
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			hasGoFiles = true
+			break
+		}
+	}
+
+	// Process this directory if it has Go files
+	if hasGoFiles {
+		if err := fn(root); err != nil {
+			return err
+		}
+	}
+
+	// Recurse into subdirectories
+	for _, entry := range entries {
+		if entry.IsDir() || followSymlinks && entry.Type()&os.ModeSymlink != 0 {
+			subPath := filepath.Join(root, entry.Name())
+			if pruned[entry.Name()] {
+				logVerbose("Skipping pruned directory: %s", subPath)
+				continue
+			}
+			if excluded(subPath) {
+				logVerbose("Skipping excluded directory: %s", subPath)
+				continue
+			}
+			module := isModuleRoot(subPath)
+			if boundary && module {
+				logVerbose("Skipping module: %s", subPath)
+				continue
+			}
+			if err := walkPackages(subPath, boundary || module && !allModules, visited, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// firstVisit reports whether the directory dir, resolved through its
+// symlinks, is not in visited yet, adding it
+func firstVisit(visited map[string]bool, dir string) (bool, error) {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+	if real, err = filepath.Abs(real); err != nil {
+		return false, err
+	}
+	if visited[real] {
+		return false, nil
+	}
+	visited[real] = true
+	return true, nil
+}
+
+// pathDir returns the file or directory of the path argument, and whether
+// its packages are processed recursively: with -typed, path is a directory
+// pattern, recursive if ending with /...
+func pathDir(path string) (string, bool) {
+	if typed {
+		return strings.CutSuffix(path, "/...")
+	}
+	return path, true
+}
+
+// cleanOrphans removes the generated files of path that the processing of
+// the paths didn't generate (see writeGenerated): their source file is gone,
+// or no longer has a matching directive
+func cleanOrphans(path string) error {
+	path, recursive := pathDir(path)
+	return removeGeneratedFiles(path, recursive, func(file string) bool {
+		abs, err := filepath.Abs(file)
+		return err == nil && !expectedFiles[abs]
+	})
+}
+
+// isGeneratedFile reports whether the file path is generated by
+// generate-named: named with the default suffix, or else (other -suffix or
+// Output file name) starting with the generated header
+func isGeneratedFile(path string) bool {
+	if strings.HasSuffix(path, generatedFileSuffix) || strings.HasSuffix(path, generatedTestSuffix) {
+		return true
+	}
+	if !strings.HasSuffix(path, ".go") {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, len(generatedHeader)+1)
+	n, _ := io.ReadFull(f, header)
+	return hasGeneratedHeader(header[:n])
+}
+
+// isSourceFile reports whether path is a Go file to process: not generated,
+// and not a test file unless -tests is set
+func isSourceFile(path string) bool {
+	return strings.HasSuffix(path, ".go") && (tests || !strings.HasSuffix(path, testFileSuffix)) &&
+		!isGeneratedFile(path)
+}
+
+func cleanGeneratedFiles(path string) error {
+	return removeGeneratedFiles(path, true, func(string) bool { return true })
+}
+
+// removeGeneratedFiles removes the generated files of path (a file, or the
+// packages of a directory, recursively if recursive) accepted by remove
+func removeGeneratedFiles(path string, recursive bool, remove func(string) bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		// If it's a file, check if it's a generated file and delete it
+		if isGeneratedFile(path) && remove(path) {
+			logVerbose("Removing: %s", path)
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("error removing %s: %v", path, err)
+			}
+			reportRemoved(path)
+		}
+		return nil
+	}
+
+	// If it's a directory, recursively clean all Go packages
+	return walkGoPackages(path, func(dir string) error {
+		if !recursive && dir != path {
+			return nil
+		}
+		logVerbose("Cleaning directory: %s", dir)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if fullPath := filepath.Join(dir, entry.Name()); isGeneratedFile(fullPath) && remove(fullPath) {
+				logVerbose("Removing: %s", fullPath)
+				if err := os.Remove(fullPath); err != nil {
+					return fmt.Errorf("error removing %s: %v", fullPath, err)
+				}
+				reportRemoved(fullPath)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Options are the settings of a run, as the flags of generate-named
+type Options struct {
+	Verbose        bool     // show detailed processing information
+	Clean          bool     // remove all generated files
+	CleanOrphans   bool     // remove the generated files whose source file is gone or no longer has a matching directive
+	Check          bool     // report generated files that are missing or out of date, without writing them
+	Lint           bool     // report the problems of the directives and generated files, without writing them
+	Watch          bool     // regenerate the packages of the paths when their files change, with Run
+	Typed          bool     // type check the packages matching the paths (e.g. ./...) with go/packages
+	Tests          bool     // also process _test.go files
+	Exclude        []string // glob patterns of the directories or files to skip, as -exclude
+	Prune          []string // directory names to skip, as -prune values (default vendor,testdata,node_modules)
+	Consolidate    bool     // generate a single file per package
+	OutPkg         string   // default output directory, relative to each package, e.g. ./named
+	Jobs           int      // number of packages processed concurrently, 1 if not positive
+	KeepGoing      bool     // continue after errors, reporting them all at the end
+	Template       string   // text/template file rendering the generated files, instead of the default one
+	Suffix         string   // suffix of the generated files (default _named_generated.go), _test inserted for test files
+	Header         string   // file of the lines added to the header of the generated files, e.g. a license
+	BuildTags      string   // build constraint of the generated files, combined with the one of their source, e.g. "integration"
+	JSON           bool     // print a JSON report to stdout rather than progress lines, with Run
+	Output         string   // default output of directives without Output option: methods (default), consts or both
+	WarnDuplicates bool     // warn about fields of a struct sharing a name, keeping the first one, rather than failing
+	AllModules     bool     // also process the modules nested in the one of a path, skipped otherwise
+	Diff           bool     // print a unified diff of the stale generated files, as Check, with Run
+	FollowSymlinks bool     // descend into the symlinked directories, each directory walked once, skipped otherwise
+	TypeScript     string   // TypeScript file of the names of the annotated structs of the paths, e.g. web/src/fields.ts
+	LogFormat      string   // format of the progress and errors: plain (default), text or json, the last two as slog records to stderr
+}
+
+// runMu serializes the runs, sharing the state of the package
+var runMu sync.Mutex
+
+// apply sets the state of a run from o, checking the options against paths
+func (o Options) apply(paths []string) error {
+	l, err := newLogger(o.LogFormat, o.Verbose)
+	if err != nil {
+		return err
+	}
+	logger = l
+	clean, orphans, check, lint, watch = o.Clean, o.CleanOrphans, o.Check || o.Diff, o.Lint, o.Watch
+	diffMode = o.Diff
+	typed, tests, consolidate, outpkg = o.Typed, o.Tests, o.Consolidate, o.OutPkg
+	keepGoing, templatePath, jsonReport, warnDuplicates = o.KeepGoing, o.Template, o.JSON, o.WarnDuplicates
+	output, jobs, allModules, followSymlinks = cmp.Or(o.Output, outputMethods), max(o.Jobs, 1), o.AllModules, o.FollowSymlinks
+
+	scanOnly = false
+	resetTemplates()
+	failures, staleFiles, lintFindings = nil, nil, nil
+	runReport = Report{}
+	expectedFiles = make(map[string]bool)
+	configs = make(map[string]*config)
+	scanSlots = make(chan struct{}, max(jobs, runtime.GOMAXPROCS(0)))
+
+	excludes, ignoreFiles = nil, nil
+	for _, pattern := range o.Exclude {
+		if err := excludes.Set(pattern); err != nil {
+			return err
+		}
+	}
+	pruned = map[string]bool{"vendor": true, "testdata": true, "node_modules": true}
+	for _, value := range o.Prune {
+		if err := setPrune(value); err != nil {
+			return err
+		}
+	}
+
+	fileSuffix = cmp.Or(o.Suffix, generatedFileSuffix)
+	if len(fileSuffix) <= len(".go") || !isOutputFile("x"+fileSuffix) {
+		return fmt.Errorf("invalid -suffix %q: expected a Go file suffix like _names.go", fileSuffix)
+	}
+
+	// the TypeScript file needs every struct of the paths
+	typeScriptFile, typeScriptStructs = o.TypeScript, nil
+	switch {
+	case typeScriptFile == "":
+	case !strings.HasSuffix(typeScriptFile, ".ts"):
+		return fmt.Errorf("invalid -ts %q: expected a .ts file", typeScriptFile)
+	case watch || slices.Contains(paths, stdinPath):
+		return errors.New("-ts isn't supported with -watch or stdin")
+	}
+
+	headerText, buildTags = "", o.BuildTags
+	if o.Header != "" {
+		data, err := os.ReadFile(o.Header)
+		if err != nil {
+			return err
+		}
+		headerText = string(data)
+	}
+	if _, err := withBuildTags("", buildTags); err != nil {
+		return err
+	}
+
+	if !validOutput(output) {
+		return fmt.Errorf("invalid -output %q: expected %s, %s or %s", output, outputMethods, outputConsts, outputBoth)
+	}
+	if outpkg != "" && !isOutputDir(outpkg) {
+		return fmt.Errorf("invalid -outpkg %q: expected a relative directory like ./named", outpkg)
+	}
+
+	modes := 0
+	for _, set := range []bool{clean, orphans, check, lint, watch} {
+		if set {
+			modes++
+		}
+	}
+	stdin := slices.Contains(paths, stdinPath)
+	switch {
+	case modes > 1:
+		return errors.New("-clean, -clean-orphans, -check (or -diff), -lint and -watch are mutually exclusive")
+	case typed && stdin:
+		return errors.New("-typed can't process stdin")
+	case (orphans || lint) && stdin:
+		return errors.New("-clean-orphans and -lint can't process stdin")
+	case (orphans || lint) && typed && slices.ContainsFunc(paths, func(path string) bool { return !isDirPattern(path) }):
+		return errors.New("-clean-orphans and -lint expect directory patterns with -typed (e.g. ./...)")
+	case watch && stdin:
+		return errors.New("-watch can't watch stdin")
+	case jsonReport && (watch || diffMode || stdin):
+		return errors.New("-json can't report on -watch, -diff or stdin")
+	}
+	return nil
+}
+
+// loadExcludes adds the exclude patterns of the configuration files, and the
+// .namedignore files, of the modules of paths, which apply to whole runs
+func loadExcludes(paths []string) error {
+	for _, path := range paths {
+		if path == stdinPath {
+			continue
+		}
+		if typed && !isDirPattern(path) {
+			path = "."
+		}
+		if err := loadConfigExcludes(strings.TrimSuffix(path, "/...")); err != nil {
+			return fmt.Errorf("error loading the configuration: %v", err)
+		}
+		if err := loadIgnoreFile(strings.TrimSuffix(path, "/...")); err != nil {
+			return fmt.Errorf("error loading %s: %v", ignoreFileName, err)
+		}
+	}
+	return nil
+}
+
+// Run processes paths (the current directory if none) as generate-named does,
+// printing its progress and errors, and returns its exit status: 1 on
+// failures, stale files or lint problems, 2 on invalid options
+func Run(opts Options, paths ...string) int {
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	if err := opts.apply(paths); err != nil {
+		logger.Error(err.Error(), "action", "options")
+		return 2
+	}
+
+	// lint compares the generated files as -check, reporting all the failures
+	if lint {
+		check, keepGoing = true, true
+		for _, path := range paths {
+			if err := lintPath(pathDir(path)); err != nil {
+				logError("Error linting", path, err)
+				recordFailure(path, err)
+			}
+		}
+	}
+
+	if err := loadExcludes(paths); err != nil {
+		logger.Error("Error: "+err.Error(), "action", "error", "error", err)
+		return finish(1)
+	}
+
+	// Handle clean mode
+	if clean {
+		for _, path := range paths {
+			if err := cleanGeneratedFiles(path); err != nil {
+				logError("Error cleaning", path, err)
+				recordFailure(path, err)
+				return finish(1)
+			}
+		}
+		return finish(0)
+	}
+
+	// Type checked generation mode, paths are package patterns
+	if typed {
+		if err := keepGoingErr(strings.Join(paths, " "), processTyped(paths)); err != nil {
+			logError("Error processing", strings.Join(paths, " "), err)
+			if !watch {
+				recordFailure(strings.Join(paths, " "), err)
+				return finish(1)
+			}
+		}
+	}
+
+	// Normal generation mode
+	for _, path := range paths {
+		if typed {
+			break
+		}
+		if err := keepGoingErr(path, processPath(path)); err != nil {
+			logError("Error processing", path, err)
+			if !watch {
+				recordFailure(path, err)
+				return finish(1)
+			}
+		}
+	}
+
+	// TypeScript file, once every struct is known
+	if typeScriptFile != "" && len(failures) == 0 {
+		if err := writeTypeScript(); err != nil {
+			logError("Error generating", typeScriptFile, err)
+			recordFailure(typeScriptFile, err)
+		}
+	}
+
+	// Orphans mode, once the files to keep are known
+	if orphans {
+		if len(failures) > 0 {
+			logFailures()
+			return finish(1)
+		}
+		for _, path := range paths {
+			if err := cleanOrphans(path); err != nil {
+				logError("Error cleaning", path, err)
+				recordFailure(path, err)
+				return finish(1)
+			}
+		}
+		return finish(0)
+	}
+
+	// Watch mode, errors are reported without exiting
+	if watch {
+		if err := watchPaths(paths); err != nil {
+			logger.Error("Error watching: "+err.Error(), "action", "watch", "error", err)
+			return 1
+		}
+	}
+
+	code := 0
+	if len(lintFindings) > 0 {
+		logger.Error(fmt.Sprintf("%d lint problem(s)", len(lintFindings)), "action", "lint", "count", len(lintFindings))
+		code = 1
+	}
+	if len(failures) > 0 {
+		logFailures()
+		code = 1
+	}
+	if len(staleFiles) > 0 {
+		logger.Error(fmt.Sprintf("%d generated file(s) out of date, run generate-named", len(staleFiles)), "action", "stale", "count", len(staleFiles))
+		code = 1
+	}
+	return finish(code)
+}
+
+// generate processes paths as Run, without its modes other than Check (or
+// scan, writing nothing), returning the report of the run and its first
+// error (all of them with KeepGoing)
+func generate(opts Options, paths []string, scan bool) (*Report, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	if opts.Clean || opts.CleanOrphans || opts.Lint || opts.Watch || opts.JSON || opts.Diff || slices.Contains(paths, stdinPath) {
+		return nil, errors.New("only Check is supported among the modes, and paths can't be stdin")
+	}
+	if err := opts.apply(paths); err != nil {
+		return nil, err
+	}
+	scanOnly = scan
+	if err := loadExcludes(paths); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if typed {
+		err = keepGoingErr(strings.Join(paths, " "), processTyped(paths))
+	}
+	for _, path := range paths {
+		if typed || err != nil {
+			break
+		}
+		if err = keepGoingErr(path, processPath(path)); err != nil {
+			err = fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err == nil && typeScriptFile != "" && len(failures) == 0 {
+		err = writeTypeScript()
+	}
+
+	r := buildReport()
+	if err == nil && len(failures) > 0 {
+		var errs []error
+		for _, f := range failures {
+			errs = append(errs, fmt.Errorf("%s: %w", f.path, f.err))
+		}
+		err = errors.Join(errs...)
+	}
+	if err == nil && len(staleFiles) > 0 {
+		err = fmt.Errorf("%d generated file(s) out of date", len(staleFiles))
+	}
+	return r, err
+}
+
+// Generate generates the files of the annotated structs of paths (files,
+// directories processed recursively or, with Typed, package patterns), as
+// generate-named does, or compares them with the files on disk with Check.
+// It returns the report of the run, along with its first error (all of them
+// with KeepGoing), or the count of the stale files with Check. Progress lines
+// and warnings are printed as by generate-named.
+func Generate(opts Options, paths ...string) (*Report, error) {
+	runMu.Lock()
+	defer runMu.Unlock()
+	return generate(opts, paths, false)
+}
+
+// Scan returns the annotated structs of paths, as Generate would generate
+// them, without writing anything
+func Scan(opts Options, paths ...string) ([]Struct, error) {
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	opts.Check = false
+	r, err := generate(opts, paths, true)
+	if r == nil {
+		return nil, err
+	}
+	return r.Structs, err
+}
+
+func processPath(path string) error {
+	if path == stdinPath {
+		return processStdin()
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		// Recursively process all Go package directories
+		if jobs <= 1 {
+			return walkGoPackages(path, processDirKeepGoing)
+		}
+
+		var dirs []string
+		err := walkGoPackages(path, func(dir string) error {
+			dirs = append(dirs, dir)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return processDirs(dirs)
+	}
+	return processFile(path, nil)
+}
+
+// processDirKeepGoing processes dir, recording its error with -keep-going
+func processDirKeepGoing(dir string) error {
+	return keepGoingErr(dir, processDir(dir))
+}
+
+// processDirs processes the package directories dirs with -j workers,
+// returning the error of the first failing package in dirs order
+func processDirs(dirs []string) error {
+	errs := make([]error, len(dirs))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(jobs, len(dirs)) {
+		wg.Go(func() {
+			for i := range next {
+				errs[i] = processDirKeepGoing(dirs[i])
+			}
+		})
+	}
+	for i := range dirs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func processDir(dir string) error {
+	logger.Debug("Processing package directory: "+dir, "action", "process", "package", dir)
+
+	// Single pass: parse all Go files once, collecting both directives and AST
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type scanResult struct {
+		path             string
+		directiveStructs directives
+		fileStructs      []string
+		hasDocDirective  bool
+		err              error
+	}
+
+	// Phase 1: Parallel scan to extract directives and struct names
+	var goFiles []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		if !isSourceFile(filepath.Join(dir, entry.Name())) {
+			continue
+		}
+		if excluded(filepath.Join(dir, entry.Name())) {
+			logVerbose("Skipping excluded file: %s", entry.Name())
+			continue
+		}
+		goFiles = append(goFiles, filepath.Join(dir, entry.Name()))
+		reportScanned(filepath.Join(dir, entry.Name()))
+	}
+
+	// Early exit if no go files
+	if len(goFiles) == 0 {
+		logVerbose("No Go files found in %s", dir)
+		return nil
+	}
+
+	// Scan all files in parallel, the slots being the ones of this run: the
+	// goroutines are waited for before returning
+	slots := scanSlots
+	results := make(chan scanResult, len(goFiles))
+	var scanning sync.WaitGroup
+	defer scanning.Wait()
+	for _, filePath := range goFiles {
+		scanning.Add(1)
+		go func(path string) {
+			defer scanning.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// Open file once and scan in a single pass
+			f, err := os.Open(path)
+			if err != nil {
+				results <- scanResult{
+					path: path,
+					err:  err,
+				}
+				return
+			}
+			defer f.Close()
+
+			scanner := bufio.NewScanner(f)
+			directiveStructs := make(directives)
+			var fileStructs []string
+			hasDocDirective := false
+
+			// Single pass: extract both directives and struct names
+			for scanner.Scan() {
+				line := scanner.Bytes()
+
+				extractDirectiveFromLine(line, directiveStructs)
+				extractStructNameFromLine(line, &fileStructs)
+				hasDocDirective = hasDocDirective || isDocDirectiveLine(line)
+			}
+
+			results <- scanResult{
+				path:             path,
+				directiveStructs: directiveStructs,
+				fileStructs:      fileStructs,
+				hasDocDirective:  hasDocDirective,
+				err:              scanner.Err(),
+			}
+		}(filePath)
+	}
+
+	// Collect results and build global directives
+	var allResults []scanResult
+	globalDirectives := make(directives)
+
+	for i := 0; i < len(goFiles); i++ {
+		allResults = append(allResults, <-results)
+	}
+
+	// Results arrive in any order, sort them so errors and output are
+	// deterministic
+	slices.SortFunc(allResults, func(a, b scanResult) int { return strings.Compare(a.path, b.path) })
+
+	for _, result := range allResults {
+		if result.err != nil {
+			if err := keepGoingErr(result.path, result.err); err != nil {
+				return fmt.Errorf("error scanning %s: %v", result.path, err)
+			}
+			continue
+		}
+
+		// Build global directives map
+		for _, structName := range slices.Sorted(maps.Keys(result.directiveStructs)) {
+			dir := result.directiveStructs[structName]
+			logger.Debug(fmt.Sprintf("Found directive in %s: %s (TagKey: %s)", filepath.Base(result.path), structName, dir.tagKey),
+				"action", "directive", "file", result.path, "struct", structName)
+			// Check for conflicting directives
+			if existing, exists := globalDirectives[structName]; exists {
+				if existing != dir {
+					return fmt.Errorf("conflicting GENERATE-NAMED directives for struct %s: %+v vs %+v",
+						structName, existing, dir)
+				}
+				// Same directive, skip (idempotent)
+				continue
+			}
+			globalDirectives[structName] = dir
+		}
+	}
+
+	// Warn about directives naming structs that don't exist (e.g. renamed ones)
+	declared := make(map[string]bool)
+	hasDocDirectives := false
+	for _, result := range allResults {
+		for _, structName := range result.fileStructs {
+			declared[structName] = true
+		}
+		hasDocDirectives = hasDocDirectives || result.hasDocDirective
+	}
+	for _, structName := range slices.Sorted(maps.Keys(globalDirectives)) {
+		// only exported struct names are collected by the scanner
+		// reported with their position by lintPackage with -lint
+		if !declared[structName] && ast.IsExported(structName) && structName != wildcardStructName && !lint {
+			warnf("GENERATE-NAMED directive for unknown struct %s in %s", structName, dir)
+		}
+	}
+
+	// the configuration file covers the structs without comment directive
+	if err := addConfigDirectives(globalDirectives, dir, slices.Sorted(maps.Keys(declared))); err != nil {
+		return err
+	}
+
+	// Early exit if no directives found
+	if len(globalDirectives) == 0 && !hasDocDirectives {
+		logVerbose("No directives found in %s", dir)
+		return nil
+	}
+
+	// Filter files that contain structs matching the directives
+	var candidateFiles []string
+	for _, result := range allResults {
+		hasMatch := result.hasDocDirective
+		for _, structName := range result.fileStructs {
+			if _, exists := globalDirectives.lookup(structName); exists {
+				logger.Debug(fmt.Sprintf("Found matching struct in %s: %s", filepath.Base(result.path), structName),
+					"action", "match", "file", result.path, "struct", structName)
+				hasMatch = true
+				break
+			}
+		}
+		if hasMatch {
+			candidateFiles = append(candidateFiles, result.path)
+		} else if len(result.fileStructs) > 0 {
+			logVerbose("Skipping %s (no matching structs)", filepath.Base(result.path))
+		}
+	}
+
+	// Early exit if no candidates found
+	if len(candidateFiles) == 0 {
+		logVerbose("No files with matching structs found in %s", dir)
+		return nil
+	}
+
+	// Phase 2: Parse the package, nested accessors may use structs of any
+	// unconstrained file, or of the files sharing the build constraint.
+	// External test packages (package x_test) only see their own structs.
+	fset := token.NewFileSet()
+
+	type structsKey struct{ pkgName, constraint string }
+	parsed := make(map[string]*ast.File, len(goFiles))
+	constraints := make(map[string]string, len(goFiles))
+	structsByConstraint := make(map[structsKey]map[string]*ast.StructType)
+	for _, fullPath := range goFiles {
+		logger.Debug("Parsing file: "+filepath.Base(fullPath), "action", "parse", "file", fullPath)
+
+		// Parse with optimization flag to skip type resolution
+		node, err := parser.ParseFile(fset, fullPath, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			if err := keepGoingErr(fullPath, err); err != nil {
+				return fmt.Errorf("error parsing %s: %v", fullPath, err)
+			}
+			continue
+		}
+		parsed[fullPath] = node
+
+		c := buildConstraint(fullPath, node)
+		constraints[fullPath] = c
+		key := structsKey{node.Name.Name, c}
+		if structsByConstraint[key] == nil {
+			structsByConstraint[key] = make(map[string]*ast.StructType)
+		}
+		collectStructTypes(node, structsByConstraint[key])
+	}
+
+	// Process candidate files
+	var generated []fileStructs
+	for _, fullPath := range candidateFiles {
+		node := parsed[fullPath]
+		if node == nil {
+			continue // failed to parse, with -keep-going
+		}
+
+		constraint := constraints[fullPath]
+		pkgStructs := structsByConstraint[structsKey{node.Name.Name, ""}]
+		if constraint != "" {
+			logVerbose("Build constraint of %s: %s", filepath.Base(fullPath), constraint)
+			pkgStructs = make(map[string]*ast.StructType)
+			maps.Copy(pkgStructs, structsByConstraint[structsKey{node.Name.Name, ""}])
+			maps.Copy(pkgStructs, structsByConstraint[structsKey{node.Name.Name, constraint}])
+		}
+
+		// Doc comment directives only apply to the struct they document
+		directives, err := mergeDocDirectives(globalDirectives, node)
+		if err != nil {
+			if err := keepGoingErr(fullPath, err); err != nil {
+				return fmt.Errorf("error processing %s: %v", fullPath, err)
+			}
+			continue
+		}
+
+		// Immediately process parsed file to find structs and generate
+		// code, the structs without errors with -keep-going
+		structs, err := findAnnotatedStructs(fset, node, directives, pkgStructs, constraint)
+		if err := keepGoingErr(fullPath, err); err != nil {
+			return fmt.Errorf("error processing %s: %v", fullPath, err)
+		}
+		if len(structs) > 0 {
+			logVerbose("Found %d struct(s) in %s", len(structs), filepath.Base(fullPath))
+			for _, s := range structs {
+				logVerbose("  - %s (%d fields)", s.name, len(s.fields))
+			}
+			generated = append(generated, fileStructs{fullPath, structs})
+		}
+	}
+
+	return generatePackage(dir, generated)
+}
+
+// fileStructs are the annotated structs of a source file
+type fileStructs struct {
+	path    string
+	structs []structInfo
+}
+
+// generatePackage generates the code of the annotated structs of the package
+// at dir: a file per source file, or with -consolidate a single file for the
+// unconstrained files (and one for the test files of the package). Files with
+// build constraints or of an external test package keep their own file.
+// Structs with an output directory are generated into the package there.
+func generatePackage(dir string, files []fileStructs) error {
+	slices.SortFunc(files, func(a, b fileStructs) int { return strings.Compare(a.path, b.path) })
+
+	outputs := make(map[string][]structInfo)
+	var outputFiles []string // in order
+	for _, file := range files {
+		for _, s := range file.structs {
+			s.template = resolveTemplate(dir, s.template)
+			outputFile, err := outputFileFor(dir, file.path, &s)
+			if err == nil && len(outputs[outputFile]) > 0 {
+				switch first := outputs[outputFile][0]; {
+				case first.template != s.template:
+					err = fmt.Errorf("template %q differs from the one of %s, generated into %s",
+						s.template, first.name, outputFile)
+				case first.constraint != s.constraint || first.pkgName != s.pkgName:
+					err = fmt.Errorf("package or build constraint differs from the ones of %s, generated into %s",
+						first.name, outputFile)
+				}
+			}
+			if err != nil {
+				if err := keepGoingErr(file.path, &structError{s.name, err}); err != nil {
+					return err
+				}
+				continue
+			}
+			reportStruct(file.path, s, outputFile)
+			addTypeScriptStruct(file.path, s)
+			if _, exists := outputs[outputFile]; !exists {
+				outputFiles = append(outputFiles, outputFile)
+			}
+			outputs[outputFile] = append(outputs[outputFile], s)
+		}
+	}
+
+	for _, outputFile := range outputFiles {
+		if err := keepGoingErr(outputFile, generateFile(outputFile, outputs[outputFile])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputFileFor returns the generated file of the struct s declared in the
+// source file sourceFile of the package at dir, updating the package name of
+// s when generated into another package
+func outputFileFor(dir, sourceFile string, s *structInfo) (string, error) {
+	// test files get a generated test file
+	base := filepath.Base(sourceFile)
+	suffix := fileSuffix
+	isTest := strings.HasSuffix(base, testFileSuffix)
+	if isTest {
+		base, suffix = strings.TrimSuffix(base, testFileSuffix), strings.TrimSuffix(fileSuffix, ".go")+testFileSuffix
+	}
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if consolidate && s.constraint == "" && !strings.HasSuffix(s.pkgName, "_test") {
+		name = consolidatedPrefix
+	}
+
+	// the Output file name replaces both
+	if s.outFile != "" {
+		name, suffix = strings.TrimSuffix(s.outFile, ".go"), ".go"
+		if isTest {
+			suffix = testFileSuffix
+		}
+	}
+
+	// the accessors of test files stay in their package
+	if s.outDir == "" || isTest {
+		return filepath.Join(dir, name+suffix), nil
+	}
+
+	// prefixed by the source package, which may share the output directory
+	// with other packages
+	outDir := filepath.Join(dir, s.outDir)
+	pkgName, err := outputPackageName(outDir)
+	if err != nil {
+		return "", err
+	}
+	outputFile := filepath.Join(outDir, s.pkgName+"_"+name+suffix)
+	s.pkgName = pkgName
+	return outputFile, nil
+}
+
+// outputPackageName returns the package name of the Go files in dir, or else
+// the name of dir
+func outputPackageName(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), testFileSuffix) {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, entry.Name()), nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+		return file.Name.Name, nil
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(filepath.Base(dir)))
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return "", fmt.Errorf("invalid package name for output directory %s", dir)
+	}
+	return name, nil
+}
+
+// extractDirectiveFromLine checks if a line contains a GENERATE-NAMED directive
+// and adds it to the result map if found
+func extractDirectiveFromLine(line []byte, result directives) {
+	if bytes.Contains(line, ([]byte)(directivePrefix)) {
+		// Extract the directive text
+		text := bytes.TrimSpace(line)
+		// Remove comment prefix if present
+		text = bytes.TrimSpace(bytes.TrimPrefix(text, []byte("//")))
+
+		if bytes.HasPrefix(text, ([]byte)(directivePrefix)) {
+			{
+				structName, dir := parseStructDirective((string)(text))
+				if structName != "" {
+					result[structName] = dir
+				}
+			}
+		}
+	}
+}
+
+// extractStructNameFromLine checks if a line contains a struct definition
+// and appends the struct name to result if found
+func extractStructNameFromLine(line []byte, result *[]string) {
+	line = bytes.TrimSpace(line)
+
+	// Look for pattern: type <name> struct, or <name> struct in a type group
+	// Handle both regular and generic structs
+	line = bytes.TrimPrefix(line, []byte("type "))
+	if bytes.Contains(line, []byte(" struct")) {
+		// Extract the struct name
+		// Pattern: "Name struct" or "Name[T any] struct"
+		parts := bytes.Fields(line)
+		if len(parts) >= 2 && (bytes.HasPrefix(parts[1], []byte("struct")) || bytes.Contains(parts[0], []byte("["))) {
+			// parts[0] = struct name (possibly with generics like "Name[T")
+			structName := parts[0]
+
+			// Handle generic structs: extract name before '['
+			if idx := bytes.Index(structName, []byte("[")); idx != -1 {
+				structName = structName[:idx]
+			}
+
+			// Verify it's a valid Go identifier and exported
+			if len(structName) > 0 && structName[0] >= 'A' && structName[0] <= 'Z' {
+				*result = append(*result, (string)(structName))
+			}
+		}
+	}
+}
+
+func processFile(filename string, globalDirectives directives) error {
+	reportScanned(filename)
+	structs, err := parseFile(filename, nil, globalDirectives)
+	if err != nil || len(structs) == 0 {
+		return err
+	}
+
+	return generatePackage(filepath.Dir(filename), []fileStructs{{filename, structs}})
+}
+
+// processStdin reads a Go source from stdin and writes the generated code to
+// stdout, nothing if the source has no annotated structs
+func processStdin() error {
+	src, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	structs, err := parseFile(stdinPath, src, nil)
+	if err := keepGoingErr(stdinPath, err); err != nil || len(structs) == 0 {
+		return err
+	}
+
+	// the source is assumed to belong to the package in the working directory
+	for i := range structs {
+		structs[i].template = resolveTemplate(".", structs[i].template)
+	}
+	content, err := renderCode(".", structs)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(withContentHash(content))
+	return err
+}
+
+// parseFile returns the annotated structs of the file filename, read from src
+// if not nil (see parser.ParseFile)
+func parseFile(filename string, src any, globalDirectives directives) ([]structInfo, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	// If no global directives provided (single file mode), collect from this file
+	if globalDirectives == nil {
+		globalDirectives = parseGenerateComments(node)
+
+		pkgStructs := make(map[string]*ast.StructType)
+		collectStructTypes(node, pkgStructs)
+		dir := filepath.Dir(filename)
+		if filename == stdinPath {
+			dir = "."
+		}
+		if err := addConfigDirectives(globalDirectives, dir, slices.Sorted(maps.Keys(pkgStructs))); err != nil {
+			return nil, err
+		}
+	}
+
+	directives, err := mergeDocDirectives(globalDirectives, node)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgStructs := make(map[string]*ast.StructType)
+	collectStructTypes(node, pkgStructs)
+
+	return findAnnotatedStructs(fset, node, directives, pkgStructs, buildConstraint(filename, node))
+}
+
+// collectStructTypes adds the struct types declared in file to structs, by name
+func collectStructTypes(file *ast.File, structs map[string]*ast.StructType) {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+				structs[typeSpec.Name.Name] = structType
+			}
+		}
+	}
+}
+
+// findAnnotatedStructs returns the structs of file with a directive, whose
+// build constraint is constraint. The errors of the structs are joined
+// structErrors, returned along with the other structs.
+func findAnnotatedStructs(fset *token.FileSet, file *ast.File, structDirectives directives, pkgStructs map[string]*ast.StructType,
+	constraint string) ([]structInfo, error) {
+	var results []structInfo
+	var errs []error
+
+	if len(structDirectives) == 0 {
+		return results, nil
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			// Check if this struct has a GENERATE-NAMED directive
+			dir, found := structDirectives.lookup(typeSpec.Name.Name)
+			if !found {
+				continue
+			}
+
+			out, outDir, outFile, err := structOutput(typeSpec.Name.Name, dir)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			if err := checkGeneric(typeSpec, dir); err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			varSuffix, typePrefix, err := structAffixes(typeSpec.Name.Name, dir)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			n, err := dir.naming(typeSpec.Name.Name, outDir)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			mongoImport, err := structMongo(typeSpec.Name.Name, dir, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			fieldType, err := boolOption(typeSpec.Name.Name, fieldTypeKey, dir.fieldType)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			iface, err := methodsOption(typeSpec.Name.Name, interfaceKey, dir.iface, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			method, err := methodsOption(typeSpec.Name.Name, methodKey, dir.method, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			fieldInfos, err := methodsOption(typeSpec.Name.Name, fieldInfosKey, dir.fieldInfos, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			listing, err := methodsOption(typeSpec.Name.Name, listingKey, dir.listing, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			tagOptions, err := methodsOption(typeSpec.Name.Name, tagOptionsKey, dir.tagOptions, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			patch, err := methodsOption(typeSpec.Name.Name, patchKey, dir.patch, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			rules, err := structRules(typeSpec.Name.Name, dir, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			qualified, err := structQualified(typeSpec.Name.Name, dir, out)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+
+			// Extract field information
+			visiting := map[string]bool{typeSpec.Name.Name: true}
+			fields, err := collectStructFields(fset, typeSpec.Name.Name, structType, n, nil, pkgStructs, visiting)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+
+			if err := checkReservedNames(typeSpec.Name.Name, out, rules, listing, tagOptions, fields); err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			if err := checkNamedMethod(typeSpec, method, outDir, fields); err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			if err := checkPatch(typeSpec.Name.Name, patch, outDir, fields); err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			if err := checkRenames(typeSpec.Name.Name, n, patch, fields); err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			if qualified {
+				if err := checkQualifiedNames(typeSpec.Name.Name, dir.table, dir.alias, fields); err != nil {
+					errs = append(errs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+			}
+			var imports map[string]string
+			if patch {
+				if imports, err = resolvePatchImports(typeSpec.Name.Name, fields, file); err != nil {
+					errs = append(errs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+			}
+			schema, err := optionSchema(typeSpec.Name.Name, dir, out, outDir, astMembers(structType, pkgStructs))
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			pointers, err := boolOption(typeSpec.Name.Name, pointersKey, dir.pointers)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+			structFields, err := boolOption(typeSpec.Name.Name, structFieldsKey, dir.structFields)
+			if err != nil {
+				errs = append(errs, &structError{typeSpec.Name.Name, err})
+				continue
+			}
+
+			if len(fields) > 0 {
+				results = append(results, structInfo{
+					name:       typeSpec.Name.Name,
+					tagKey:     dir.tagKey,
+					fields:     fields,
+					pkgName:    file.Name.Name,
+					output:     out,
+					constraint: constraint,
+					outDir:     outDir,
+					outFile:    outFile,
+					directive:  structDirectives.key(typeSpec.Name.Name),
+					template:   dir.template,
+					varSuffix:  varSuffix,
+					typePrefix: typePrefix,
+					table:      dir.table,
+					alias:      dir.alias,
+					qualified:  qualified,
+					mongo:      mongoImport,
+					fieldType:  fieldType,
+					iface:      iface,
+					method:     method,
+					fieldInfos: fieldInfos,
+					listing:    listing,
+					tagOptions: tagOptions,
+					patch:      patch,
+					imports:    imports,
+					schema:     schema,
+					pointers:   pointers,
+					structs:    structFields,
+					rules:      rules,
+					typeParams: typeParamNames(typeSpec),
+				})
+			}
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// structOutput returns the output mode and directory of the struct
+// structName: the ones of its directive (e.g. "Output:consts|./named"), or
+// else the -output and -outpkg flags
+func structOutput(structName string, dir directive) (out, outDir, outFile string, err error) {
+	out, outDir = output, outpkg
+	for _, value := range strings.Split(dir.output, "|") {
+		switch value = strings.TrimSpace(value); {
+		case value == "":
+		case strings.HasSuffix(value, ".go"):
+			if !isOutputFile(value) {
+				return "", "", "", fmt.Errorf("struct %s: invalid %s file name %q: expected a name like names.go, in the output directory",
+					structName, outputKey, value)
+			}
+			outFile = value
+		case isOutputDir(value):
+			outDir = value
+			if filepath.Clean(value) == "." {
+				outDir = "" // the package itself
+			}
+		default:
+			out = value
+		}
+	}
+	if !validOutput(out) {
+		return "", "", "", fmt.Errorf("struct %s: invalid %s %q: expected %s, %s or %s, a directory like ./named or a file name like names.go",
+			structName, outputKey, out, outputMethods, outputConsts, outputBoth)
+	}
+	return out, outDir, outFile, nil
+}
+
+// isOutputFile reports whether value is the name of a generated file, without
+// directory: a Go file, not a test one (the test files get one)
+func isOutputFile(value string) bool {
+	return strings.HasSuffix(value, ".go") && len(value) > len(".go") && filepath.Base(value) == value &&
+		!strings.ContainsAny(value, `/\`) && !strings.HasSuffix(value, testFileSuffix)
+}
+
+// structAffixes returns the suffix of the accessor variable of the struct
+// structName (Named unless set by its directive) and the prefix of its
+// accessor type (empty for the unexported struct name)
+func structAffixes(structName string, dir directive) (string, string, error) {
+	varSuffix := dir.varSuffix
+	if varSuffix == "" {
+		varSuffix = defaultVarSuffix
+	}
+	if !token.IsIdentifier(structName + varSuffix) {
+		return "", "", fmt.Errorf("struct %s: invalid %s %q", structName, varSuffixKey, dir.varSuffix)
+	}
+	if dir.typePrefix != "" && !token.IsIdentifier(dir.typePrefix+structName) {
+		return "", "", fmt.Errorf("struct %s: invalid %s %q", structName, typePrefixKey, dir.typePrefix)
+	}
+	return varSuffix, dir.typePrefix, nil
+}
+
+// boolOption returns the value of the boolean option key of the struct
+// structName, false if unset
+func boolOption(structName, key, value string) (bool, error) {
+	switch value {
+	case "", "false":
+		return false, nil
+	case "true":
+		return true, nil
+	}
+	return false, fmt.Errorf("struct %s: invalid %s %q: expected true or false", structName, key, value)
+}
+
+// methodsOption is a boolOption generating methods, invalid with the
+// output out of constants only
+func methodsOption(structName, key, value, out string) (bool, error) {
+	enabled, err := boolOption(structName, key, value)
+	if enabled && out == outputConsts {
+		return false, fmt.Errorf("struct %s: %s requires the methods output", structName, key)
+	}
+	return enabled, err
+}
+
+// rulesSuffix suffixes the methods returning the validation rules, e.g.
+// EmailRules
+const rulesSuffix = "Rules"
+
+// structRules returns the tag key of the validation rules of the struct
+// structName, empty without Rules option: validate for Rules:true, or the
+// tag key given (e.g. Rules:binding)
+func structRules(structName string, dir directive, out string) (string, error) {
+	switch dir.rules {
+	case "", "false":
+		return "", nil
+	case "true":
+		dir.rules = defaultRulesTagKey
+	}
+	if strings.ContainsAny(dir.rules, ` :"|`) {
+		return "", fmt.Errorf("struct %s: invalid %s %q: expected true, false or a tag key", structName, rulesKey, dir.rules)
+	}
+	if out == outputConsts {
+		return "", fmt.Errorf("struct %s: %s requires the methods output", structName, rulesKey)
+	}
+	return dir.rules, nil
+}
+
+// suffixes of the methods returning the names qualified by the table, e.g.
+// EmailQualified, and by its alias, e.g. EmailAliased
+const (
+	qualifiedSuffix = "Qualified"
+	aliasedSuffix   = "Aliased"
+)
+
+// structQualified reports whether the struct structName has the Qualified
+// option, generating the methods of the names of its top level fields
+// qualified by its Table (users.email) or its Alias (u.email), or both
+func structQualified(structName string, dir directive, out string) (bool, error) {
+	enabled, err := methodsOption(structName, qualifiedKey, dir.qualified, out)
+	switch {
+	case err != nil:
+		return false, err
+	case !enabled && dir.alias != "":
+		return false, fmt.Errorf("struct %s: %s requires %s:true", structName, aliasKey, qualifiedKey)
+	case !enabled:
+		return false, nil
+	case dir.table == "" && dir.alias == "":
+		return false, fmt.Errorf("struct %s: %s requires a %s or an %s", structName, qualifiedKey, tableKey, aliasKey)
+	case strings.ContainsAny(dir.alias, ` :".|`):
+		return false, fmt.Errorf("struct %s: invalid %s %q: expected an SQL alias, e.g. u", structName, aliasKey, dir.alias)
+	}
+	return true, nil
+}
+
+// checkQualifiedNames fails if a top level field of structName clashes with
+// the qualified (with table) or aliased (with alias) method of another one
+func checkQualifiedNames(structName, table, alias string, fields []fieldInfo) error {
+	var suffixes []string
+	if table != "" {
+		suffixes = append(suffixes, qualifiedSuffix)
+	}
+	if alias != "" {
+		suffixes = append(suffixes, aliasedSuffix)
+	}
+	for _, suffix := range suffixes {
+		for _, field := range fields {
+			name := field.name + suffix
+			if len(field.children) == 0 && slices.ContainsFunc(fields, func(f fieldInfo) bool { return f.name == name }) {
+				return fmt.Errorf("struct %s: field %s conflicts with the generated %s method of field %s",
+					structName, name, name, field.name)
+			}
+		}
+	}
+	return nil
+}
+
+// mongoImports are the import paths of the bson package, by Mongo option
+var mongoImports = map[string]string{
+	"true": "go.mongodb.org/mongo-driver/v2/bson",
+	"v2":   "go.mongodb.org/mongo-driver/v2/bson",
+	"v1":   "go.mongodb.org/mongo-driver/bson",
+}
+
+// structMongo returns the import path of the bson package used by the MongoDB
+// helpers of the struct structName, empty without Mongo option. The helpers
+// resolve the fields with the maps generated along with the methods.
+func structMongo(structName string, dir directive, out string) (string, error) {
+	if dir.mongo == "" || dir.mongo == "false" {
+		return "", nil
+	}
+	importPath, ok := mongoImports[dir.mongo]
+	if !ok {
+		return "", fmt.Errorf("struct %s: invalid %s %q: expected true, v1 or v2", structName, mongoKey, dir.mongo)
+	}
+	if out == outputConsts {
+		return "", fmt.Errorf("struct %s: %s requires the methods output", structName, mongoKey)
+	}
+	return importPath, nil
+}
+
+// isOutputDir reports whether an Output value is a directory, relative to the
+// package directory
+func isOutputDir(value string) bool {
+	return value == "." || value == ".." || strings.HasPrefix(value, "./") || strings.HasPrefix(value, "../")
+}
+
+// checkReservedNames fails if a field of structName would clash with the
+// methods of its accessor type, including the field number, the listing ones
+// with the Listing option, the Options one with the TagOptions option and,
+// with the rules tag key, validation rules ones
+func checkReservedNames(structName, out, rules string, listing, tagOptions bool, fields []fieldInfo) error {
+	if out == outputConsts {
+		return nil
+	}
+	for _, field := range fields {
+		if tagOptions && field.name == tagOptionsName {
+			return fmt.Errorf("struct %s: field %s conflicts with the generated %s method",
+				structName, field.name, field.name)
+		}
+	}
+	if listing {
+		if err := checkListingNames(structName, "", fields); err != nil {
+			return err
+		}
+	}
+	hasNumber := func(field fieldInfo) bool { return protobufNumber(field.tag) != "" }
+	if err := checkSuffixedNames(structName, numberSuffix, hasNumber, fields); err != nil {
+		return err
+	}
+	if rules == "" {
+		return nil
+	}
+	return checkSuffixedNames(structName, rulesSuffix, func(fieldInfo) bool { return true }, fields)
+}
+
+// checkListingNames fails if a field of the accessor of parent (the struct
+// itself if empty) or of its nested accessors clashes with the Fields and All
+// methods of the Listing option
+func checkListingNames(structName, parent string, fields []fieldInfo) error {
+	for _, field := range fields {
+		switch {
+		case listingNames[field.name] && parent == "":
+			return fmt.Errorf("struct %s: field %s conflicts with the generated %s method",
+				structName, field.name, field.name)
+		case listingNames[field.name]:
+			return fmt.Errorf("struct %s: field %s of nested %s conflicts with the generated %s method",
+				structName, field.name, parent, field.name)
+		}
+		if err := checkListingNames(structName, field.name, field.children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSuffixedNames fails if a field of an accessor type clashes with the
+// method suffixed by suffix (e.g. EmailNumber) of another one, generated for
+// the fields has accepts
+func checkSuffixedNames(structName, suffix string, has func(fieldInfo) bool, fields []fieldInfo) error {
+	names := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		names[field.name] = true
+	}
+	for _, field := range fields {
+		if has(field) && (names[field.name+suffix] || reservedNestedNames[field.name+suffix]) {
+			return fmt.Errorf("struct %s: field %s conflicts with the generated %s method of field %s",
+				structName, field.name+suffix, field.name+suffix, field.name)
+		}
+		if err := checkSuffixedNames(structName, suffix, has, field.children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNamedMethod fails if the Named method of the struct of typeSpec, when
+// enabled, can't be declared: on an alias, in another package than the
+// struct, or clashing with a field
+func checkNamedMethod(typeSpec *ast.TypeSpec, enabled bool, outDir string, fields []fieldInfo) error {
+	structName := typeSpec.Name.Name
+	switch {
+	case !enabled:
+		return nil
+	case typeSpec.Assign.IsValid():
+		return fmt.Errorf("struct %s: %s can't declare methods on an alias", structName, methodKey)
+	case outDir != "":
+		return fmt.Errorf("struct %s: %s requires the accessors in the package of the struct", structName, methodKey)
+	}
+	for _, field := range fields {
+		if field.name == "Named" {
+			return fmt.Errorf("struct %s: field Named conflicts with the generated Named method", structName)
+		}
+	}
+	return nil
+}
+
+// checkGeneric fails if the struct of typeSpec is generic and dir sets an
+// option generating code that depends on its type parameters, other than the
+// Named method
+func checkGeneric(typeSpec *ast.TypeSpec, dir directive) error {
+	if typeSpec.TypeParams == nil {
+		return nil
+	}
+	for _, option := range []struct{ key, value string }{{patchKey, dir.patch}, {schemaKey, dir.schema}} {
+		if option.value == "true" {
+			return fmt.Errorf("struct %s: %s doesn't support generic structs", typeSpec.Name.Name, option.key)
+		}
+	}
+	return nil
+}
+
+// typeParamNames returns the names of the type parameters of the struct of
+// typeSpec, nil if not generic. The accessors don't depend on them, as the
+// names of the fields don't.
+func typeParamNames(typeSpec *ast.TypeSpec) []string {
+	if typeSpec.TypeParams == nil {
+		return nil
+	}
+	var names []string
+	for _, field := range typeSpec.TypeParams.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// checkNestedNames fails if a field of the nested fieldName would clash with
+// the methods of its nested accessor type
+func checkNestedNames(structName, fieldName string, children []fieldInfo) error {
+	for _, child := range children {
+		if reservedNestedNames[child.name] {
+			return fmt.Errorf("struct %s: field %s of nested %s conflicts with the generated %s method",
+				structName, child.name, fieldName, child.name)
+		}
+	}
+	return nil
+}
+
+// collectStructFields returns the fields of structType named after n.
+// Fields whose type is a struct of the package (pkgStructs) get their own
+// fields as children, prefixed with parent; visiting guards against cycles.
+// The fields of embedded structs are promoted following encoding/json rules,
+// or for mapstructure its squash option.
+func collectStructFields(fset *token.FileSet, structName string, structType *ast.StructType, n naming, parent []string,
+	pkgStructs map[string]*ast.StructType, visiting map[string]bool) ([]fieldInfo, error) {
+
+	candidates, err := collectCandidates(fset, structName, structType, n, parent, pkgStructs, visiting, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resolveCandidates(fset, structName, candidates)
+}
+
+// exprKind reports whether the type expression expr is a pointer, and a slice
+// or pointer to a slice. Named slice types are not resolved.
+func exprKind(expr ast.Expr) (pointer, slice bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		pointer, expr = true, star.X
+	}
+	array, ok := expr.(*ast.ArrayType)
+	return pointer, ok && array.Len == nil
+}
+
+// candidate is a field found in a struct or promoted from an embedded one
+type candidate struct {
+	info   fieldInfo
+	owner  string // struct declaring the field
+	depth  int    // embedding depth, 0 for the fields of the struct itself
+	tagged bool   // named by a tag
+	pos    token.Pos
+}
+
+func collectCandidates(fset *token.FileSet, structName string, structType *ast.StructType, n naming, parent []string,
+	pkgStructs map[string]*ast.StructType, visiting map[string]bool, depth int) ([]candidate, error) {
+
+	var candidates []candidate
+	for _, field := range structType.Fields.List {
+		override := tagName(structTag(field.Tag), namedTagKey)
+		tagName, options := n.parseTag(structTag(field.Tag))
+
+		// Skip fields with tag:"-"
+		if n.skips(tagName, override, options) {
+			continue
+		}
+
+		var fieldName string
+		if len(field.Names) == 0 {
+			// Embedded field
+			typeName, local := embeddedTypeName(field.Type)
+
+			// Untagged (or squashed) embedded structs of the package are flattened
+			if embedded, ok := pkgStructs[typeName]; ok && local && n.flattens(tagName, override, options) {
+				if visiting[typeName] {
+					continue
+				}
+				visiting[typeName] = true
+				promoted, err := collectCandidates(fset, typeName, embedded, n, parent, pkgStructs, visiting, depth+1)
+				delete(visiting, typeName)
+				if err != nil {
+					return nil, err
+				}
+				candidates = append(candidates, promoted...)
+				continue
+			}
+
+			// Otherwise named after its type
+			fieldName = typeName
+		} else {
+			fieldName = field.Names[0].Name
+		}
+
+		// Skip unexported fields, unless IncludeUnexported
+		if !ast.IsExported(fieldName) && !n.unexported {
+			continue
+		}
+
+		tagged := tagName != "" || override != ""
+
+		// Use field name if no tag specified, in the fallback case
+		if tagName == "" {
+			tagName = n.fallbackName(fieldName)
+		}
+
+		// The named tag overrides the name, as in the runtime linker, and
+		// the Rename option both
+		if rename := n.rename(fieldName, parent); rename != "" {
+			tagged, override = true, rename
+		}
+		if override != "" {
+			tagName = override
+		}
+
+		info := fieldInfo{
+			name:    fieldName,
+			tagName: tagName,
+			path:    append(append([]string(nil), parent...), tagName),
+			options: options,
+			goType:  types.ExprString(field.Type),
+		}
+		info.pointer, info.slice = exprKind(field.Type)
+		info.elemType, info.comparable, info.pkgNames = exprTraits(field.Type)
+		if field.Tag != nil {
+			info.tag, _ = strconv.Unquote(field.Tag.Value)
+		}
+
+		// Nested struct of the package
+		if typeName := structTypeName(field.Type); typeName != "" && !visiting[typeName] {
+			if nested, ok := pkgStructs[typeName]; ok {
+				visiting[typeName] = true
+				children, err := collectStructFields(fset, typeName, nested, n, info.path, pkgStructs, visiting)
+				delete(visiting, typeName)
+				if err != nil {
+					return nil, err
+				}
+				if err := checkNestedNames(structName, fieldName, children); err != nil {
+					return nil, err
+				}
+				info.children = children
+			}
+		}
+
+		candidates = append(candidates, candidate{info: info, owner: structName, depth: depth, tagged: tagged, pos: field.Pos()})
+	}
+
+	return candidates, nil
+}
+
+// resolveCandidates keeps, for each name, the shallowest field (or the only
+// tagged one among the shallowest), as encoding/json does, but rejects the
+// ambiguous names json would silently drop, reporting the positions of the
+// fields in fset (only the first one is kept with -warn-duplicates).
+// Declaration order is kept.
+func resolveCandidates(fset *token.FileSet, structName string, candidates []candidate) ([]fieldInfo, error) {
+	byName := make(map[string][]int)
+	for i, c := range candidates {
+		byName[c.info.tagName] = append(byName[c.info.tagName], i)
+	}
+
+	keep := make([]bool, len(candidates))
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		indexes := byName[name]
+		// shallowest candidates
+		var shallowest []int
+		for _, i := range indexes {
+			switch {
+			case len(shallowest) == 0 || candidates[i].depth < candidates[shallowest[0]].depth:
+				shallowest = []int{i}
+			case candidates[i].depth == candidates[shallowest[0]].depth:
+				shallowest = append(shallowest, i)
+			}
+		}
+
+		if len(shallowest) > 1 {
+			var tagged []int
+			for _, i := range shallowest {
+				if candidates[i].tagged {
+					tagged = append(tagged, i)
+				}
+			}
+			switch {
+			case len(tagged) == 1:
+				shallowest = tagged
+			case warnDuplicates:
+				a, b := candidates[shallowest[0]], candidates[shallowest[1]]
+				warnf("struct %s: duplicate name %q used by both %s.%s (%s) and %s.%s (%s), keeping %s.%s",
+					structName, name, a.owner, a.info.name, position(fset, a.pos), b.owner, b.info.name, position(fset, b.pos),
+					a.owner, a.info.name)
+			default:
+				// Reject duplicated names
+				a, b := candidates[shallowest[0]], candidates[shallowest[1]]
+				return nil, fmt.Errorf("struct %s: duplicate name %q used by both %s.%s (%s) and %s.%s (%s)",
+					structName, name, a.owner, a.info.name, position(fset, a.pos), b.owner, b.info.name, position(fset, b.pos))
+			}
+		}
+		keep[shallowest[0]] = true
+	}
+
+	var fields []fieldInfo
+	methods := make(map[string]string) // Go name -> name
+	for i, c := range candidates {
+		if !keep[i] {
+			continue
+		}
+		// promoted fields may share a Go name, but not an accessor
+		if prev, exists := methods[c.info.name]; exists {
+			return nil, fmt.Errorf("struct %s: accessor %s would return both %q and %q",
+				structName, c.info.name, prev, c.info.tagName)
+		}
+		methods[c.info.name] = c.info.tagName
+		fields = append(fields, c.info)
+	}
+
+	return fields, nil
+}
+
+// position returns the file name and line of pos in fset, e.g. user.go:12
+func position(fset *token.FileSet, pos token.Pos) string {
+	p := fset.Position(pos)
+	return fmt.Sprintf("%s:%d", filepath.Base(p.Filename), p.Line)
+}
+
+// embeddedTypeName returns the name of the type of an embedded field, and
+// whether it is declared in the package (not qualified)
+func embeddedTypeName(expr ast.Expr) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, true
+	case *ast.StarExpr:
+		return embeddedTypeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name, false
+	case *ast.IndexExpr:
+		return embeddedTypeName(t.X)
+	}
+	return "", false
+}
+
+// structTypeName returns the name of the package level type of expr, looking
+// through pointers and named Field types (Field[T], named.Field[T]), or "" for
+// any other type expression.
+func structTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return structTypeName(t.X)
+	case *ast.IndexExpr:
+		var name string
+		switch x := t.X.(type) {
+		case *ast.Ident:
+			name = x.Name
+		case *ast.SelectorExpr:
+			name = x.Sel.Name
+		}
+		if name == "Field" {
+			return structTypeName(t.Index)
+		}
+	}
+	return ""
+}
+
+// parseGenerateComments scans all comments in the file for GENERATE-NAMED directives
+// Returns a map of struct name to tag key
+func parseGenerateComments(file *ast.File) directives {
+	result := make(directives)
+
+	// Parse each comment
+	for _, commentGroup := range file.Comments {
+		for _, comment := range commentGroup.List {
+			text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+
+			// Check for format: GENERATE-NAMED=StructName:[name],TagKey:[key]
+			if strings.HasPrefix(text, directivePrefix) {
+				structName, dir := parseStructDirective(text)
+				if structName != "" {
+					result[structName] = dir
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// isDocDirectiveLine reports whether line is a comment holding a doc comment
+// directive, e.g. "// GENERATE-NAMED TagKey:db"
+func isDocDirectiveLine(line []byte) bool {
+	text := bytes.TrimSpace(line)
+	if !bytes.HasPrefix(text, []byte("//")) {
+		return false
+	}
+	_, ok := parseDocDirective(string(text[2:]))
+	return ok
+}
+
+// parseDocDirective parses the text of a doc comment line like
+// "GENERATE-NAMED TagKey:db"
+func parseDocDirective(text string) (directive, bool) {
+	text = strings.TrimSpace(text)
+	rest, ok := strings.CutPrefix(text, docDirectivePrefix)
+	if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return directive{}, false
+	}
+	_, dir := parseStructDirective(rest)
+	return dir, true
+}
+
+// docDirectives returns the struct names annotated with a directive in their
+// doc comment
+func docDirectives(file *ast.File) directives {
+	result := make(directives)
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+
+			// the doc of a single type declaration is attached to the GenDecl
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			if doc == nil {
+				continue
+			}
+
+			for _, comment := range doc.List {
+				if dir, ok := parseDocDirective(strings.TrimPrefix(comment.Text, "//")); ok {
+					result[typeSpec.Name.Name] = dir
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// mergeDocDirectives returns directives extended with the doc comment
+// directives of file, failing on conflicting ones
+func mergeDocDirectives(global directives, file *ast.File) (directives, error) {
+	doc := docDirectives(file)
+	if len(doc) == 0 {
+		return global, nil
+	}
+
+	merged := make(directives, len(global)+len(doc))
+	for structName, dir := range global {
+		merged[structName] = dir
+	}
+	for _, structName := range slices.Sorted(maps.Keys(doc)) {
+		dir := doc[structName]
+		// doc comments take precedence over the configuration file
+		if existing, ok := merged[structName]; ok && existing.config == "" && existing != dir {
+			return nil, fmt.Errorf("conflicting GENERATE-NAMED directives for struct %s: %+v vs %+v",
+				structName, existing, dir)
+		}
+		logVerbose("Found doc comment directive: %s (TagKey: %s)", structName, dir.tagKey)
+		merged[structName] = dir
+	}
+	return merged, nil
+}
+
+// parseStructDirective parses a directive like "GENERATE-NAMED=StructName:Foo,TagKey:db"
+// Returns the struct name (or "*" for every exported struct) and the directive
+// (TagKey defaults to json)
+func parseStructDirective(text string) (string, directive) {
+	var structName string
+	dir := directive{tagKey: defaultTagKey}
+
+	// Remove GENERATE-NAMED= prefix
+	text = strings.TrimPrefix(text, directivePrefix)
+
+	// Split by comma (or spaces) to get key-value pairs
+	parts := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+
+		// Split by colon
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(kv[0])
+		value := strings.TrimSpace(kv[1])
+
+		switch key {
+		case structNameKey:
+			structName = value
+		case tagKeyKey:
+			dir.tagKey = value
+		case excludeKey:
+			dir.exclude = value
+		case outputKey:
+			dir.output = value
+		case templateKey:
+			dir.template = value
+		case varSuffixKey:
+			dir.varSuffix = value
+		case fallbackKey:
+			dir.fallback = value
+		case tagFormatKey:
+			dir.tagFormat = value
+		case tableKey:
+			dir.table = value
+		case aliasKey:
+			dir.alias = value
+		case qualifiedKey:
+			dir.qualified = value
+		case mongoKey:
+			dir.mongo = value
+		case fieldTypeKey:
+			dir.fieldType = value
+		case interfaceKey:
+			dir.iface = value
+		case methodKey:
+			dir.method = value
+		case fieldInfosKey:
+			dir.fieldInfos = value
+		case listingKey:
+			dir.listing = value
+		case tagOptionsKey:
+			dir.tagOptions = value
+		case patchKey:
+			dir.patch = value
+		case schemaKey:
+			dir.schema = value
+		case pointersKey:
+			dir.pointers = value
+		case structFieldsKey:
+			dir.structFields = value
+		case rulesKey:
+			dir.rules = value
+		case typePrefixKey:
+			dir.typePrefix = value
+		case unexportedKey:
+			dir.unexported = value
+		case renameKey:
+			dir.rename = value
+		}
+	}
+
+	return structName, dir
+}
+
+func validOutput(output string) bool {
+	return output == outputMethods || output == outputConsts || output == outputBoth
+}
+
+// structTag returns the struct tag of a field, without backticks
+func structTag(tag *ast.BasicLit) string {
+	if tag == nil {
+		return ""
+	}
+	return strings.Trim(tag.Value, "`")
+}
+
+// tagName returns the name part of the value of key in the struct tag tag
+func tagName(tag, key string) string {
+	// Use reflect.StructTag.Get() which properly handles:
+	// - Quoted values with whitespace
+	// - Multiple tag keys
+	// - Proper escaping
+	name, _ := parseCommaTag(reflect.StructTag(tag).Get(key))
+	return name
+}
+
+// generateFile generates the code of structs into outputFile
+func generateFile(outputFile string, structs []structInfo) error {
+	formatted, err := renderCode(filepath.Dir(outputFile), structs)
+	if err != nil {
+		return err
+	}
+	return writeGenerated(outputFile, formatted)
+}
+
+// renderCode returns the formatted code generated for structs, declared in
+// the package at dir, with their template
+func renderCode(dir string, structs []structInfo) ([]byte, error) {
+	code, err := executeTemplate(structs[0].template, dir, structs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Format the generated code, fixing its imports as goimports does: the
+	// missing ones of custom templates are added, the unused ones removed
+	formatted, err := imports.Process(filepath.Join(dir, generatedFileSuffix), code, &imports.Options{
+		Comments:  true,
+		TabIndent: true,
+		TabWidth:  8,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("formatting error: %v\n%s", err, code)
+	}
+	return formatted, nil
+}
+
+// writeGenerated writes content to outputFile, or in check mode compares it
+// with the file on disk, reporting the differences
+func writeGenerated(outputFile string, content []byte) error {
+	content = withContentHash(content)
+	if orphans || scanOnly {
+		abs, err := filepath.Abs(outputFile)
+		if err != nil {
+			return err
+		}
+		expectedFilesMu.Lock()
+		expectedFiles[abs] = true
+		expectedFilesMu.Unlock()
+		return nil
+	}
+
+	if check {
+		existing, err := os.ReadFile(outputFile)
+		switch {
+		case os.IsNotExist(err) && diffMode:
+			fmt.Print(unifiedDiff("/dev/null", outputFile, nil, content))
+		case os.IsNotExist(err):
+			logger.Info("Missing: "+outputFile, "action", "missing", "file", outputFile)
+		case err != nil:
+			return err
+		case bytes.Equal(existing, content):
+			logger.Debug("Up to date: "+outputFile, "action", "up-to-date", "file", outputFile)
+			return nil
+		case lint && hasGeneratedHeader(existing) && !validContentHash(existing):
+			return nil // reported by lintGeneratedFile
+		case diffMode:
+			fmt.Print(unifiedDiff(outputFile, outputFile, existing, content))
+		default:
+			logger.Info(fmt.Sprintf("Out of date: %s (%s)", outputFile, diffSummary(existing, content)), "action", "stale", "file", outputFile)
+		}
+		staleMu.Lock()
+		staleFiles = append(staleFiles, outputFile)
+		staleMu.Unlock()
+		return nil
+	}
+
+	// Write to file, creating the directory of another package
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputFile, content, 0644); err != nil {
+		return err
+	}
+
+	reportWritten(outputFile)
+	return nil
+}
+
+// withContentHash returns the generated content with the line of the hash of
+// the rest of it after the generated header, for -lint to tell the manual
+// edits. The content of templates leaving out the header is returned as is.
+func withContentHash(content []byte) []byte {
+	body, ok := bytes.CutPrefix(content, []byte(generatedHeader+"\n"))
+	if !ok {
+		return content
+	}
+	sum := sha256.Sum256(body)
+	return slices.Concat([]byte(generatedHeader+"\n"+contentHashPrefix+hex.EncodeToString(sum[:])+"\n"), body)
+}
+
+// hasGeneratedHeader reports whether content starts with the generated header
+func hasGeneratedHeader(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(generatedHeader+"\n"))
+}
+
+// validContentHash reports whether the generated content has the line of its
+// hash (see withContentHash), matching the rest of it
+func validContentHash(content []byte) bool {
+	rest, ok := bytes.CutPrefix(content, []byte(generatedHeader+"\n"+contentHashPrefix))
+	if !ok {
+		return false
+	}
+	hash, body, _ := bytes.Cut(rest, []byte("\n"))
+	sum := sha256.Sum256(body)
+	return string(hash) == hex.EncodeToString(sum[:])
+}
+
+// diffSummary describes how the generated content differs from the existing
+// one: the first differing line and the line counts
+func diffSummary(existing, generated []byte) string {
+	oldLines := strings.Split(string(existing), "\n")
+	newLines := strings.Split(string(generated), "\n")
+
+	line := 0
+	for line < len(oldLines) && line < len(newLines) && oldLines[line] == newLines[line] {
+		line++
+	}
+
+	summary := fmt.Sprintf("first difference at line %d, %d lines on disk, %d generated", line+1, len(oldLines)-1, len(newLines)-1)
+	if line < len(newLines) {
+		summary += fmt.Sprintf(": %q", strings.TrimSpace(newLines[line]))
+	}
+	return summary
+}
+
+// namedQualifier returns the qualifier of the named package in the package
+// at dir: "named." unless dir is the named package itself
+func namedQualifier(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "named."
+	}
+	root := moduleRoot(abs)
+	if root != abs {
+		return "named."
+	}
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "named."
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if modulePath, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			if strings.Trim(strings.TrimSpace(modulePath), `"`) == namedImportPath {
+				return ""
+			}
+			break
+		}
+	}
+	return "named."
+}
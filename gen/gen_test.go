@@ -0,0 +1,1460 @@
+package gen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScan(t *testing.T) {
+	structs, err := Scan(Options{}, "testdata/users")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(structs) != 1 {
+		t.Fatalf("Expected 1 struct, got %v", structs)
+	}
+	s := structs[0]
+	if s.Name != "User" || s.TagKey != "json" || s.Fields != 2 || s.Generated != filepath.Join("testdata", "users", "user_named_generated.go") {
+		t.Errorf("Unexpected struct %+v", s)
+	}
+	if _, err := os.Stat(s.Generated); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be written, got %v", s.Generated, err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(Options{Check: true}, dir); err == nil {
+		t.Errorf("Expected the missing file to be reported")
+	}
+	report, err := Generate(Options{}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := filepath.Join(dir, "user_named_generated.go"); len(report.Written) != 1 || report.Written[0] != want {
+		t.Errorf("Expected %s to be written, got %v", want, report.Written)
+	}
+	if _, err := Generate(Options{Check: true}, dir); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGenerate_Options(t *testing.T) {
+	if _, err := Generate(Options{Output: "names"}, "testdata/users"); err == nil {
+		t.Errorf("Expected an invalid output error")
+	}
+	if _, err := Generate(Options{Clean: true}, "testdata/users"); err == nil {
+		t.Errorf("Expected an unsupported mode error")
+	}
+}
+
+func TestGenerate_HeaderTags(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	header := filepath.Join(dir, "LICENSE")
+	if err := os.WriteFile(header, []byte("Copyright Example\n\n// SPDX-License-Identifier: MIT\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(Options{Header: header, BuildTags: "integration"}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "user_named_generated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// after the header and the line of the content hash
+	want := "// Copyright Example\n//\n// SPDX-License-Identifier: MIT\n\n//go:build integration\n\npackage users\n"
+	lines := strings.SplitAfterN(string(got), "\n", 3)
+	if len(lines) < 3 || lines[0] != generatedHeader+"\n" || !strings.HasPrefix(lines[1], contentHashPrefix) || !strings.HasPrefix(lines[2], want) {
+		t.Errorf("Expected the file to start with\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestGenerate_Suffix(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(Options{Suffix: "_test.go"}, dir); err == nil {
+		t.Errorf("Expected an invalid suffix error")
+	}
+	report, err := Generate(Options{Suffix: "_names.go"}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := filepath.Join(dir, "user_names.go")
+	if len(report.Written) != 1 || report.Written[0] != want {
+		t.Errorf("Expected %s to be written, got %v", want, report.Written)
+	}
+	if !isGeneratedFile(want) {
+		t.Errorf("Expected %s to be recognized as generated", want)
+	}
+	if structs, err := Scan(Options{Suffix: "_names.go"}, dir); err != nil || len(structs) != 1 {
+		t.Errorf("Expected the generated file to be skipped, got %v, %v", structs, err)
+	}
+}
+
+func TestGenerate_TypeScript(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := filepath.Join(dir, "web", "fields.ts")
+	if _, err := Generate(Options{TypeScript: ts}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := withContentHash([]byte(generatedHeader + "\n\n// names of the fields of User\nexport const UserFields = {\n" +
+		"  email_address: \"email_address\",\n  name: \"name\",\n} as const;\n"))
+	if string(data) != string(want) {
+		t.Errorf("Expected\n%s\ngot\n%s", want, data)
+	}
+	if _, err := Generate(Options{TypeScript: ts, Check: true}, dir); err != nil {
+		t.Errorf("Expected the TypeScript file to be up to date, got %v", err)
+	}
+}
+
+func TestGenerate_Workspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.work":            "go 1.25\n\nuse ./a\n",
+		"a/go.mod":           "module example.com/a\n",
+		"a/user.go":          string(src),
+		"a/nested/go.mod":    "module example.com/nested\n",
+		"a/nested/user.go":   string(src),
+		"outside/users/u.go": string(src),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := Generate(Options{}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := filepath.Join(dir, "a", "user_named_generated.go"); len(report.Written) != 1 || report.Written[0] != want {
+		t.Errorf("Expected only %s to be written, got %v", want, report.Written)
+	}
+}
+
+func TestGenerate_AllModules(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"go.mod", "user.go", "nested/go.mod", "nested/user.go"} {
+		content := src
+		if filepath.Base(name) == "go.mod" {
+			content = []byte("module example.com/" + filepath.Base(filepath.Dir(filepath.Join(dir, name))) + "\n")
+		}
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := Generate(Options{Check: true}, dir)
+	if err == nil || len(report.Stale) != 1 {
+		t.Errorf("Expected the nested module to be skipped, got %v, %v", report.Stale, err)
+	}
+	report, err = Generate(Options{Check: true, AllModules: true}, dir)
+	if err == nil || len(report.Stale) != 2 {
+		t.Errorf("Expected the nested module to be processed, got %v, %v", report.Stale, err)
+	}
+}
+
+func TestGenerate_FollowSymlinks(t *testing.T) {
+	dir, farm := t.TempDir(), t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(farm, "user.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the linked directory, twice, and a cycle
+	for link, target := range map[string]string{"users": farm, "again": farm, "loop": dir} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Skip("symlinks unsupported:", err)
+		}
+	}
+
+	report, err := Generate(Options{Check: true}, dir)
+	if err != nil || len(report.Stale) != 0 {
+		t.Errorf("Expected the symlinks to be skipped, got %v, %v", report.Stale, err)
+	}
+	report, err = Generate(Options{Check: true, FollowSymlinks: true}, dir)
+	if err == nil || len(report.Stale) != 1 {
+		t.Errorf("Expected the linked directory to be processed once, got %v, %v", report.Stale, err)
+	}
+}
+
+func TestGenerate_IgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod":                  "module example.com/users\n",
+		".namedignore":            "# generated trees\nfixtures/\n/third_party/**\n*_legacy.go\n!keep_legacy.go\n",
+		"user.go":                 string(src),
+		"user_legacy.go":          strings.Replace(string(src), "User struct", "Legacy struct", 1),
+		"keep_legacy.go":          strings.Replace(string(src), "User struct", "Kept struct", 1),
+		"fixtures/user.go":        string(src),
+		"api/fixtures/user.go":    string(src),
+		"third_party/x/y/user.go": string(src),
+		"api/third_party/user.go": string(src),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	structs, err := Scan(Options{}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got []string
+	for _, s := range structs {
+		rel, _ := filepath.Rel(dir, s.File)
+		got = append(got, filepath.ToSlash(rel)+":"+s.Name)
+	}
+	slices.Sort(got)
+	want := []string{"api/third_party/user.go:User", "keep_legacy.go:Kept", "user.go:User"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	want := "--- x.go\n+++ x.go\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n"
+	if got := unifiedDiff("x.go", "x.go", []byte(old), []byte(new)); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+	if got := unifiedDiff("/dev/null", "x.go", nil, []byte("a\n")); got != "--- /dev/null\n+++ x.go\n@@ -0,0 +1 @@\n+a\n" {
+		t.Errorf("Unexpected diff of a missing file\n%s", got)
+	}
+	if got := unifiedDiff("x.go", "x.go", []byte(old), []byte(old)); got != "" {
+		t.Errorf("Expected no diff, got\n%s", got)
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	ctx := context.Background()
+	for _, format := range []string{"", "plain", "text", "json"} {
+		l, err := newLogger(format, false)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", format, err)
+		}
+		if l.Enabled(ctx, slog.LevelDebug) || !l.Enabled(ctx, slog.LevelInfo) {
+			t.Errorf("Expected the %q logger to log from the info level", format)
+		}
+		if l, _ := newLogger(format, true); !l.Enabled(ctx, slog.LevelDebug) {
+			t.Errorf("Expected the verbose %q logger to log the debug level", format)
+		}
+	}
+	if _, err := Generate(Options{LogFormat: "xml"}, t.TempDir()); err == nil || !strings.Contains(err.Error(), `invalid log format "xml"`) {
+		t.Errorf("Expected an invalid log format error, got %v", err)
+	}
+}
+
+func TestParseRenames(t *testing.T) {
+	renames, err := parseRenames("Password=pwd_hash|Email=mail")
+	if err != nil || renames["Password"] != "pwd_hash" || renames["Email"] != "mail" || len(renames) != 2 {
+		t.Errorf("Unexpected renames %v, %v", renames, err)
+	}
+	for _, value := range []string{"Password", "Password=", "pass word=x", "Password=-", "Password=a|Password=b"} {
+		if _, err := parseRenames(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestStructQualified(t *testing.T) {
+	for _, tt := range []struct {
+		dir directive
+		out string
+		ok  bool
+	}{
+		{directive{table: "users", qualified: "true"}, outputMethods, true},
+		{directive{alias: "u", qualified: "true"}, outputMethods, true},
+		{directive{table: "users"}, outputMethods, false},
+		{directive{qualified: "true"}, outputMethods, false},
+		{directive{alias: "u"}, outputMethods, false},
+		{directive{alias: "u.x", qualified: "true"}, outputMethods, false},
+		{directive{table: "users", qualified: "true"}, outputConsts, false},
+	} {
+		enabled, err := structQualified("User", tt.dir, tt.out)
+		if tt.ok && (!enabled || err != nil) || !tt.ok && enabled {
+			t.Errorf("%+v: unexpected %v, %v", tt.dir, enabled, err)
+		}
+	}
+}
+
+// writeFiles writes files, by slash separated path, into dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// userSource returns the source file of testdata/users
+func userSource(t *testing.T) string {
+	t.Helper()
+	src, err := os.ReadFile("testdata/users/user.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(src)
+}
+
+// runOutput runs Run with opts and paths, returning its exit status and
+// what it printed to stdout, its output to stderr being discarded
+func runOutput(t *testing.T, opts Options, paths ...string) (int, string) {
+	t.Helper()
+	stdout, stderr := os.Stdout, os.Stderr
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	discard, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer discard.Close()
+	os.Stdout, os.Stderr = out, discard
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	code := Run(opts, paths...)
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(data)
+}
+
+func TestRun_Check(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"user.go": userSource(t)})
+	generated := filepath.Join(dir, "user_named_generated.go")
+
+	// missing
+	if code, _ := runOutput(t, Options{Check: true}, dir); code != 1 {
+		t.Errorf("Expected the missing file to fail the check, got %d", code)
+	}
+	if _, err := os.Stat(generated); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be written, got %v", generated, err)
+	}
+
+	// up to date
+	if code, _ := runOutput(t, Options{}, dir); code != 0 {
+		t.Fatalf("Unexpected exit status %d", code)
+	}
+	if code, _ := runOutput(t, Options{Check: true}, dir); code != 0 {
+		t.Errorf("Expected the check to pass, got %d", code)
+	}
+
+	// stale
+	stale := []byte(generatedHeader + "\n\npackage users\n")
+	if err := os.WriteFile(generated, stale, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out := runOutput(t, Options{Check: true}, dir)
+	if code != 1 || !strings.Contains(out, "Out of date: "+generated) {
+		t.Errorf("Expected the stale file to fail the check, got %d\n%s", code, out)
+	}
+	if data, err := os.ReadFile(generated); err != nil || string(data) != string(stale) {
+		t.Errorf("Expected %s to be left untouched, got %s (%v)", generated, data, err)
+	}
+}
+
+func TestRun_Watch(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{"user.go": src})
+	generated := filepath.Join(dir, "user_named_generated.go")
+
+	watchStop = make(chan struct{})
+	defer func() { watchStop = nil }()
+	done := make(chan int)
+	go func() {
+		code, _ := runOutput(t, Options{Watch: true}, dir)
+		done <- code
+	}()
+
+	// waits for the generated file to hold want, writing the source file
+	// src (if any) until then, the watcher being set up after the first
+	// generation
+	waitFor := func(want, src string) {
+		t.Helper()
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(250 * time.Millisecond) {
+			if data, err := os.ReadFile(generated); err == nil && strings.Contains(string(data), want) {
+				return
+			}
+			if src != "" {
+				writeFiles(t, dir, map[string]string{"user.go": src})
+			}
+		}
+		t.Errorf("Expected %s to hold %q", generated, want)
+	}
+
+	waitFor(`"email_address"`, "")
+	waitFor(`"full_name"`, strings.Replace(src, `json:"name"`, `json:"full_name"`, 1))
+
+	close(watchStop)
+	select {
+	case code := <-done:
+		if code != 0 {
+			t.Errorf("Unexpected exit status %d", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the watch to stop")
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"user.go": userSource(t)})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "user_named_generated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdin, err := os.Open(filepath.Join(dir, "user.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+	os.Stdin = stdin
+
+	// the generated code goes to stdout, nothing is written
+	code, out := runOutput(t, Options{}, stdinPath)
+	if code != 0 || out != string(want) {
+		t.Errorf("Expected exit status 0 and\n%s\ngot %d and\n%s", want, code, out)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected no other file, got %v", entries)
+	}
+
+	if code, _ := runOutput(t, Options{Typed: true}, stdinPath); code != 2 {
+		t.Errorf("Expected -typed to be rejected with stdin, got %d", code)
+	}
+}
+
+func TestGenerate_Typed(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.25\n",
+		"models/order.go": `package models
+
+import "example.com/shop/address"
+
+// GENERATE-NAMED TagKey:json
+type Order struct {
+	ID       OrderID         ` + "`json:\"id\"`" + `
+	Shipping address.Address ` + "`json:\"shipping\"`" + `
+	Audit
+}
+
+type OrderID = int64
+`,
+		"models/audit.go": `package models
+
+type Audit struct {
+	CreatedBy string ` + "`json:\"created_by\"`" + `
+}
+`,
+		"address/address.go": `package address
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+`,
+	})
+	generated := filepath.Join(dir, "models", "order_named_generated.go")
+
+	// the types of the other files and packages are resolved
+	if _, err := Generate(Options{Typed: true}, dir+"/..."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"id"`, `"shipping.city"`, `"created_by"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s to hold %s\n%s", generated, want, data)
+		}
+	}
+
+	// but not without -typed
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(generated); err != nil || strings.Contains(string(data), `"shipping.city"`) {
+		t.Errorf("Expected the nested struct of another package not to be resolved\n%s", data)
+	}
+}
+
+func TestGenerate_BuildConstraints(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"home.go":         "//go:build linux && amd64\n\npackage users\n\n// GENERATE-NAMED TagKey:json\ntype User struct {\n\tHome string `json:\"home\"`\n}\n",
+		"user_windows.go": "package users\n\n// GENERATE-NAMED TagKey:json\ntype User struct {\n\tProfile string `json:\"profile\"`\n}\n",
+	})
+
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// each variant gets its own accessors, under its constraint
+	for name, want := range map[string][]string{
+		"home_named_generated.go":         {"//go:build linux && amd64\n", `"home"`},
+		"user_windows_named_generated.go": {"//go:build windows\n", `"profile"`},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range want {
+			if !strings.Contains(string(data), w) {
+				t.Errorf("Expected %s to hold %q\n%s", name, w, data)
+			}
+		}
+	}
+}
+
+func TestGenerate_Tests(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"user.go":         userSource(t),
+		"fixture_test.go": "package users\n\n// GENERATE-NAMED TagKey:json\ntype fixture struct {\n\tInput string `json:\"input\"`\n}\n",
+	})
+
+	report, err := Generate(Options{}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := filepath.Join(dir, "user_named_generated.go"); len(report.Written) != 1 || report.Written[0] != want {
+		t.Errorf("Expected only %s to be written, got %v", want, report.Written)
+	}
+
+	report, err = Generate(Options{Tests: true}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	generated := filepath.Join(dir, "fixture_named_generated_test.go")
+	if len(report.Structs) != 2 || !slices.Contains(report.Written, generated) {
+		t.Errorf("Expected %s to be written, got %v", generated, report.Written)
+	}
+	if data, err := os.ReadFile(generated); err != nil || !strings.Contains(string(data), `"input"`) {
+		t.Errorf("Unexpected %s\n%s (%v)", generated, data, err)
+	}
+}
+
+// structFiles returns the sorted files of structs, relative to dir and
+// slash separated, followed by their struct name, e.g. "api/user.go:User"
+func structFiles(dir string, structs []Struct) []string {
+	var files []string
+	for _, s := range structs {
+		rel, _ := filepath.Rel(dir, s.File)
+		files = append(files, filepath.ToSlash(rel)+":"+s.Name)
+	}
+	slices.Sort(files)
+	return files
+}
+
+func TestScan_Exclude(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{
+		"user.go":                 src,
+		"user_mock.go":            strings.Replace(src, "User struct", "MockUser struct", 1),
+		"mocks/user.go":           src,
+		"internal/fixtures/a.go":  src,
+		"api/fixtures/user.go":    src,
+		"api/internal/user.go":    src,
+		"api/v1/user_mock_gen.go": src,
+	})
+
+	structs, err := Scan(Options{Exclude: []string{"*_mock.go", "mocks", "internal/fixtures"}}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"api/fixtures/user.go:User", "api/internal/user.go:User", "api/v1/user_mock_gen.go:User", "user.go:User"}
+	if got := structFiles(dir, structs); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := Scan(Options{Exclude: []string{"["}}, dir); err == nil {
+		t.Error("Expected an invalid pattern error")
+	}
+}
+
+func TestScan_Prune(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{
+		"user.go":              src,
+		"vendor/x/user.go":     src,
+		"testdata/user.go":     src,
+		"node_modules/user.go": src,
+		"api/build/user.go":    src,
+		"api/testdata/user.go": src,
+	})
+
+	// the default directories
+	structs, err := Scan(Options{}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, want := structFiles(dir, structs), []string{"api/build/user.go:User", "user.go:User"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// one added, one removed from the defaults
+	structs, err = Scan(Options{Prune: []string{"build,-testdata"}}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"api/testdata/user.go:User", "testdata/user.go:User", "user.go:User"}
+	if got := structFiles(dir, structs); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := Scan(Options{Prune: []string{"api/build"}}, dir); err == nil {
+		t.Error("Expected an invalid directory name error")
+	}
+}
+
+func TestGenerate_Consolidate(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{
+		"user.go":         src,
+		"account.go":      strings.Replace(src, "User struct", "Account struct", 1),
+		"order_linux.go":  strings.Replace(src, "User struct", "Order struct", 1),
+		"fixture_test.go": strings.Replace(src, "User struct", "Fixture struct", 1),
+		"api/token.go":    strings.Replace(strings.Replace(src, "User struct", "Token struct", 1), "package users", "package api", 1),
+	})
+
+	report, err := Generate(Options{Consolidate: true, Tests: true}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// one file per package, the constrained file keeping its own
+	var written []string
+	for _, path := range report.Written {
+		rel, _ := filepath.Rel(dir, path)
+		written = append(written, filepath.ToSlash(rel))
+	}
+	want := []string{"api/zz_named_generated.go", "order_linux_named_generated.go", "zz_named_generated.go", "zz_named_generated_test.go"}
+	if !slices.Equal(written, want) {
+		t.Errorf("Expected %v, got %v", want, written)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "zz_named_generated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "var UserNamed") || !strings.Contains(string(data), "var AccountNamed") {
+		t.Errorf("Expected the accessors of User and Account\n%s", data)
+	}
+}
+
+func TestGenerate_OutPkg(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.25\n",
+		"users/user.go": `package users
+
+import "time"
+
+// GENERATE-NAMED TagKey:json,FieldInfos:true
+type User struct {
+	Email   string    ` + "`json:\"email\"`" + `
+	Created time.Time ` + "`json:\"created\"`" + `
+}
+`,
+	})
+
+	if _, err := Generate(Options{OutPkg: "named"}, dir); err == nil {
+		t.Error("Expected an invalid -outpkg error")
+	}
+	report, err := Generate(Options{OutPkg: "./named"}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// prefixed by the package of the struct, several packages may share the directory
+	generated := filepath.Join(dir, "users", "named", "users_user_named_generated.go")
+	if len(report.Written) != 1 || report.Written[0] != generated {
+		t.Fatalf("Expected %s to be written, got %v", generated, report.Written)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), generated, nil, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing %s: %v", generated, err)
+	}
+	var imports []string
+	for _, spec := range file.Imports {
+		imports = append(imports, spec.Path.Value)
+	}
+	if file.Name.Name != "named" || !slices.Equal(imports, []string{`"github.com/alvarolm/named"`}) {
+		t.Errorf("Expected package named importing the named package, got %s importing %v", file.Name.Name, imports)
+	}
+	data, err := os.ReadFile(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "[]named.FieldInfo{") || strings.Contains(string(data), "users.") {
+		t.Errorf("Expected the named types to be qualified, and no reference to the users package\n%s", data)
+	}
+
+	if _, err := Generate(Options{OutPkg: "./named", Check: true}, dir); err != nil {
+		t.Errorf("Expected the generated file to be up to date, got %v", err)
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	dir := t.TempDir()
+	var src strings.Builder
+	src.WriteString("package users\n\n// GENERATE-NAMED=StructName:*,TagKey:json\n")
+	for _, name := range []string{"Zeta", "Alpha", "Mu", "Beta", "Omega", "Kappa"} {
+		src.WriteString("// GENERATE-NAMED=StructName:" + name + ",TagKey:db\n")
+		src.WriteString("type " + name + " struct {\n\tB string `db:\"b\" json:\"b\"`\n\tA string `db:\"a\" json:\"a\"`\n}\n\n")
+	}
+	writeFiles(t, dir, map[string]string{
+		"a.go": src.String(),
+		"b.go": "package users\n\ntype Other struct {\n\tX string `json:\"x\"`\n}\n",
+	})
+	generated := filepath.Join(dir, "zz_named_generated.go")
+
+	var first []byte
+	for i := range 5 {
+		if err := os.Remove(generated); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		if _, err := Generate(Options{Consolidate: true, Jobs: 4}, dir); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(generated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = data
+		} else if string(data) != string(first) {
+			t.Fatalf("Expected identical files, got\n%s\nthen\n%s", first, data)
+		}
+	}
+
+	// in declaration order, by file
+	var vars []string
+	for _, line := range strings.Split(string(first), "\n") {
+		if name, ok := strings.CutPrefix(line, "var "); ok && strings.HasSuffix(strings.Fields(name)[0], "Named") {
+			vars = append(vars, strings.Fields(name)[0])
+		}
+	}
+	want := []string{"ZetaNamed", "AlphaNamed", "MuNamed", "BetaNamed", "OmegaNamed", "KappaNamed", "OtherNamed"}
+	if !slices.Equal(vars, want) {
+		t.Errorf("Expected %v, got %v", want, vars)
+	}
+}
+
+func TestGenerate_Jobs(t *testing.T) {
+	src := userSource(t)
+	files := make(map[string]string)
+	for i := range 20 {
+		files[fmt.Sprintf("pkg%02d/user.go", i)] = src
+		files[fmt.Sprintf("pkg%02d/account.go", i)] = strings.Replace(src, "User struct", "Account struct", 1)
+	}
+
+	// the packages processed concurrently give the report of a sequential run
+	var reports [2]*Report
+	for i, jobs := range []int{1, 8} {
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+		r, err := Generate(Options{Jobs: jobs}, dir)
+		if err != nil {
+			t.Fatalf("Unexpected error with %d jobs: %v", jobs, err)
+		}
+		if len(r.Written) != 40 {
+			t.Errorf("Expected 40 files written with %d jobs, got %d", jobs, len(r.Written))
+		}
+		for j, path := range r.Written {
+			r.Written[j], _ = filepath.Rel(dir, path)
+		}
+		reports[i] = r
+	}
+	if !slices.Equal(reports[0].Written, reports[1].Written) {
+		t.Errorf("Expected the same files, got %v and %v", reports[0].Written, reports[1].Written)
+	}
+}
+
+func TestGenerate_KeepGoing(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{
+		"a/broken.go": "package a\n\n// GENERATE-NAMED TagKey:json\ntype Broken struct {\n",
+		"b/dup.go":    "package b\n\n// GENERATE-NAMED TagKey:json\ntype Dup struct {\n\tA string `json:\"x\"`\n\tB string `json:\"x\"`\n}\n\n// GENERATE-NAMED TagKey:json\ntype Fine struct {\n\tA string `json:\"a\"`\n}\n",
+		"c/user.go":   src,
+	})
+
+	// the first error stops the run
+	if _, err := Generate(Options{}, dir); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c", "user_named_generated.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected c not to be generated, got %v", err)
+	}
+
+	// all the failures are reported, the rest generated
+	report, err := Generate(Options{KeepGoing: true}, dir)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	var failures []string
+	for _, f := range report.Failures {
+		rel, _ := filepath.Rel(dir, f.Path)
+		failures = append(failures, filepath.ToSlash(rel)+":"+f.Struct)
+	}
+	if want := []string{"a/broken.go:", "b/dup.go:Dup"}; !slices.Equal(failures, want) {
+		t.Errorf("Expected failures %v, got %v", want, report.Failures)
+	}
+	for _, want := range []string{"b/dup.go: ", "a/broken.go: "} {
+		if !strings.Contains(err.Error(), filepath.FromSlash(want)) {
+			t.Errorf("Expected the error to list %s, got %v", want, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "b", "dup_named_generated.go"))
+	if err != nil || !strings.Contains(string(data), "FineNamed") || strings.Contains(string(data), "DupNamed") {
+		t.Errorf("Expected only Fine to be generated in b, got %s (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c", "user_named_generated.go")); err != nil {
+		t.Errorf("Expected c to be generated, got %v", err)
+	}
+}
+
+// jsonKeys returns the sorted keys of the JSON object v, and of the first
+// element of its array members, prefixed by their member name
+func jsonKeys(v map[string]any) []string {
+	var keys []string
+	for key, value := range v {
+		keys = append(keys, key)
+		if list, ok := value.([]any); ok && len(list) > 0 {
+			if object, ok := list[0].(map[string]any); ok {
+				for sub := range object {
+					keys = append(keys, key+"."+sub)
+				}
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestRun_JSON(t *testing.T) {
+	readme, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, documented, _ := strings.Cut(string(readme), "`-json` prints a report")
+	_, documented, _ = strings.Cut(documented, "```json\n")
+	documented, _, _ = strings.Cut(documented, "```")
+	var want map[string]any
+	if err := json.Unmarshal([]byte(documented), &want); err != nil {
+		t.Fatalf("Unexpected error decoding the documented report: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"user.go": userSource(t)})
+	code, out := runOutput(t, Options{JSON: true}, dir)
+	if code != 0 {
+		t.Fatalf("Unexpected exit status %d", code)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Expected only the report on stdout, got %v\n%s", err, out)
+	}
+	if !slices.Equal(jsonKeys(got), jsonKeys(want)) {
+		t.Errorf("Expected the documented members %v, got %v", jsonKeys(want), jsonKeys(got))
+	}
+
+	var report Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatal(err)
+	}
+	generated := filepath.Join(dir, "user_named_generated.go")
+	if len(report.Structs) != 1 || report.Structs[0] != (Struct{Name: "User", File: filepath.Join(dir, "user.go"), Directive: "User",
+		TagKey: "json", Output: "methods", Fields: 2, Generated: generated}) || !slices.Equal(report.Written, []string{generated}) {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	// the failures, with their struct
+	writeFiles(t, dir, map[string]string{"user.go": strings.Replace(userSource(t), `json:"name"`, `json:"email_address"`, 1)})
+	if code, out = runOutput(t, Options{JSON: true, KeepGoing: true}, dir); code != 1 {
+		t.Errorf("Expected exit status 1, got %d", code)
+	}
+	got = nil
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatal(err)
+	}
+	if keys := jsonKeys(got); !slices.Contains(keys, "failures.struct") || !slices.Contains(keys, "failures.error") || !slices.Contains(keys, "failures.path") {
+		t.Errorf("Expected a failure with its path, struct and error, got %s", out)
+	}
+}
+
+func TestGenerate_Template(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := "{{.Header}}\n\npackage {{.Package}}\n{{range .Structs}}\nvar {{.Name}}Columns = []string{ {{- range .Accessor.Leaves}}{{quote .Name}}, {{end -}} }\n{{end}}"
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{
+		"columns.tmpl":          tmpl,
+		"users/user.go":         src,
+		"accounts/account.go":   strings.Replace(strings.Replace(src, "TagKey:json", "TagKey:json,Template:account.tmpl", 1), "package users", "package accounts", 1),
+		"accounts/account.tmpl": strings.Replace(tmpl, "Columns", "Keys", 1),
+	})
+
+	if _, err := Generate(Options{Template: filepath.Join(dir, "columns.tmpl")}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for name, want := range map[string]string{
+		// by -template
+		"users/user_named_generated.go": "var UserColumns = []string{\"email_address\", \"name\"}\n",
+		// by the Template option of the directive, relative to the package
+		"accounts/account_named_generated.go": "var UserKeys = []string{\"email_address\", \"name\"}\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), generatedHeader+"\n") || !strings.HasSuffix(string(data), want) {
+			t.Errorf("Expected %s to be rendered by its template, got\n%s", name, data)
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{"columns.tmpl": "{{.Missing"})
+	if _, err := Generate(Options{Template: filepath.Join(dir, "columns.tmpl")}, filepath.Join(dir, "users")); err == nil {
+		t.Error("Expected a template error")
+	}
+}
+
+func TestScan_Config(t *testing.T) {
+	plain := func(pkg, name string) string {
+		return "package " + pkg + "\n\ntype " + name + " struct {\n\tID string `json:\"id\" db:\"user_id\"`\n}\n"
+	}
+	for _, config := range []map[string]string{
+		{"named.yaml": `exclude: [mocks]
+directives:
+  - structs: "*Request"
+    packages: api
+    output: consts
+  - structs: "*"
+    packages: internal/models/...
+    tagKey: db
+    exclude: [Migration]
+`},
+		{"named.toml": `exclude = ["mocks"]
+
+[[directives]]
+structs = "*Request"
+packages = "api"
+output = "consts"
+
+[[directives]]
+structs = "*"
+packages = "internal/models/..."
+tagKey = "db"
+exclude = ["Migration"]
+`},
+	} {
+		dir := t.TempDir()
+		writeFiles(t, dir, config)
+		writeFiles(t, dir, map[string]string{
+			"go.mod":                      "module example.com/shop\n",
+			"api/req.go":                  plain("api", "CreateRequest") + "\ntype Response struct{}\n",
+			"internal/models/user.go":     plain("models", "User") + "\ntype Migration struct {\n\tV int `db:\"v\"`\n}\n",
+			"internal/models/sub/item.go": plain("sub", "Item"),
+			"internal/models/order.go":    "package models\n\n// GENERATE-NAMED TagKey:json\n" + plain("", "Order")[len("package \n\n"):],
+			"mocks/user.go":               "package mocks\n\n// GENERATE-NAMED TagKey:json\n" + plain("", "Mock")[len("package \n\n"):],
+		})
+
+		structs, err := Scan(Options{}, dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got []string
+		for _, s := range structs {
+			rel, _ := filepath.Rel(dir, s.File)
+			got = append(got, filepath.ToSlash(rel)+":"+s.Name+":"+s.TagKey+":"+s.Output)
+		}
+		slices.Sort(got)
+		// the comment of Order takes precedence
+		want := []string{
+			"api/req.go:CreateRequest:json:consts",
+			"internal/models/order.go:Order:json:methods",
+			"internal/models/sub/item.go:Item:db:methods",
+			"internal/models/user.go:User:db:methods",
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%v: Expected %v, got %v", slices.Collect(maps.Keys(config)), want, got)
+		}
+	}
+}
+
+func TestGenerate_Affixes(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{
+		"user.go": strings.Replace(src, "TagKey:json", "TagKey:json,VarSuffix:Names,TypePrefix:x", 1),
+	})
+
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "user_named_generated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"type xUserNames struct", "var UserNames xUserNames"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "UserNamed") {
+		t.Errorf("Expected the default names to be replaced, got\n%s", data)
+	}
+
+	for _, affix := range []string{"VarSuffix:-", "TypePrefix:1"} {
+		writeFiles(t, dir, map[string]string{"user.go": strings.Replace(src, "TagKey:json", "TagKey:json,"+affix, 1)})
+		if _, err := Generate(Options{}, dir); err == nil {
+			t.Errorf("%s: Expected an invalid identifier error", affix)
+		}
+	}
+}
+
+func TestGenerate_TagFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"models.go": "package models\n\n" +
+			"// GENERATE-NAMED TagKey:gorm\ntype Gorm struct {\n" +
+			"\tID    int64  `gorm:\"column:user_id;type:bigint\"`\n" +
+			"\tEmail string `gorm:\"type:varchar(100);unique\"`\n" +
+			"\tTemp  string `gorm:\"-\"`\n}\n\n" +
+			"// GENERATE-NAMED TagKey:xorm\ntype Xorm struct {\n" +
+			"\tName  string `xorm:\"varchar(25) notnull 'user_name'\"`\n" +
+			"\tEmail string `xorm:\"unique\"`\n}\n\n" +
+			"// GENERATE-NAMED TagKey:bson\ntype Bson struct {\n" +
+			"\tID     string `bson:\"_id\"`\n" +
+			"\tUserID string `bson:\",omitempty\"`\n}\n\n" +
+			"// GENERATE-NAMED TagKey:db,TagFormat:gorm\ntype Db struct {\n" +
+			"\tID int64 `db:\"column:id;primaryKey\"`\n}\n",
+	})
+
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "models_named_generated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func (gormNamed) ID() string    { return \"user_id\" }",
+		"func (gormNamed) Email() string { return \"email\" }", // falls back to snake case
+		"func (xormNamed) Name() string  { return \"user_name\" }",
+		"func (xormNamed) Email() string { return \"email\" }",
+		"func (bsonNamed) ID() string     { return \"_id\" }",
+		"func (bsonNamed) UserID() string { return \"userid\" }", // falls back to lower case
+		"func (dbNamed) ID() string { return \"id\" }",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "Temp") {
+		t.Errorf("Expected the field tagged - to be skipped, got\n%s", data)
+	}
+
+	writeFiles(t, dir, map[string]string{"models.go": "package models\n\n// GENERATE-NAMED TagKey:db,TagFormat:yaml\ntype Db struct {\n\tID int64 `db:\"id\"`\n}\n"})
+	if _, err := Generate(Options{}, dir); err == nil {
+		t.Error("Expected an invalid tag format error")
+	}
+}
+
+func TestGenerate_Mongo(t *testing.T) {
+	dir := t.TempDir()
+	src := "package users\n\n// GENERATE-NAMED TagKey:bson,Mongo:%s\ntype User struct {\n\tID        string `bson:\"_id\"`\n\tCreatedAt int64  `bson:\"created_at\"`\n}\n"
+	for option, importPath := range map[string]string{
+		"true": "go.mongodb.org/mongo-driver/v2/bson",
+		"v1":   "go.mongodb.org/mongo-driver/bson",
+	} {
+		writeFiles(t, dir, map[string]string{"user.go": fmt.Sprintf(src, option)})
+		if _, err := Generate(Options{}, dir); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		file := filepath.Join(dir, "user_named_generated.go")
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.ContainsFunc(f.Imports, func(spec *ast.ImportSpec) bool { return spec.Path.Value == strconv.Quote(importPath) }) {
+			t.Errorf("Mongo:%s: Expected %s to be imported", option, importPath)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"func UserProjection(fields ...string) bson.D {",
+			"func UserSort(fields ...string) bson.D {",
+			"name, ok := UserNamedMap[field]",
+		} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("Mongo:%s: Expected %q in\n%s", option, want, data)
+			}
+		}
+	}
+
+	for _, option := range []string{"v3", "true,Output:consts"} {
+		writeFiles(t, dir, map[string]string{"user.go": fmt.Sprintf(src, option)})
+		if _, err := Generate(Options{}, dir); err == nil {
+			t.Errorf("Mongo:%s: Expected an error", option)
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{"user.go": fmt.Sprintf(src, "false")})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "user_named_generated.go")); err != nil || strings.Contains(string(data), "bson") {
+		t.Errorf("Expected no MongoDB helpers, got %v\n%s", err, data)
+	}
+}
+
+func TestGenerate_Duplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"user.go": "package users\n\n// GENERATE-NAMED TagKey:json\ntype User struct {\n" +
+			"\tEmail   string `json:\"email\"`\n" +
+			"\tContact string `json:\"email\"`\n" +
+			"\tName    string `json:\"name\"`\n}\n",
+	})
+
+	_, err := Generate(Options{}, dir)
+	if err == nil {
+		t.Fatal("Expected a duplicate name error")
+	}
+	for _, want := range []string{`duplicate name "email"`, "User.Email (user.go:5)", "User.Contact (user.go:6)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %v", want, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "user_named_generated.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be written, got %v", err)
+	}
+
+	report, err := Generate(Options{WarnDuplicates: true}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "keeping User.Email") {
+		t.Errorf("Expected a duplicate warning keeping Email, got %v", report.Warnings)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "user_named_generated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Email() string") || strings.Contains(string(data), "Contact() string") {
+		t.Errorf("Expected only the first field to be kept, got\n%s", data)
+	}
+}
+
+func TestRun_CleanOrphans(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{
+		"kept/user.go":      src,
+		"gone/user.go":      strings.Replace(src, "package users", "package gone", 1),
+		"untagged/user.go":  strings.Replace(src, "package users", "package untagged", 1),
+		"other/handmade.go": "package other\n",
+	})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	kept := filepath.Join(dir, "kept/user_named_generated.go")
+	before, err := os.ReadFile(kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the source file is gone, or its directive is removed
+	if err := os.Remove(filepath.Join(dir, "gone/user.go")); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"untagged/user.go": strings.Replace(strings.Replace(src, "package users", "package untagged", 1), "GENERATE-NAMED", "NAMED", 1),
+	})
+
+	code, out := runOutput(t, Options{CleanOrphans: true, JSON: true}, dir)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "gone/user_named_generated.go"), filepath.Join(dir, "untagged/user_named_generated.go")}
+	if !slices.Equal(report.Removed, want) {
+		t.Errorf("Expected %v to be removed, got %v", want, report.Removed)
+	}
+	for _, file := range want {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", file, err)
+		}
+	}
+	if len(report.Written) != 0 {
+		t.Errorf("Expected nothing to be written, got %v", report.Written)
+	}
+	if after, err := os.ReadFile(kept); err != nil || string(after) != string(before) {
+		t.Errorf("Expected %s to be kept as is, got %v", kept, err)
+	}
+	for _, file := range []string{"kept/user.go", "other/handmade.go"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", file, err)
+		}
+	}
+}
+
+func TestRun_Lint(t *testing.T) {
+	dir := t.TempDir()
+	src := userSource(t)
+	writeFiles(t, dir, map[string]string{"users/user.go": src})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if code, out := runOutput(t, Options{Lint: true, JSON: true}, dir); code != 0 {
+		t.Fatalf("Expected exit code 0 without problems, got %d\n%s", code, out)
+	}
+
+	generated := filepath.Join(dir, "users/user_named_generated.go")
+	data, err := os.ReadFile(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(data), `"name"`, `"full_name"`, 1)
+	writeFiles(t, dir, map[string]string{
+		"users/user_named_generated.go": edited,
+		"orders/order.go": "package orders\n\nimport \"github.com/alvarolm/named\"\n\n" +
+			"// GENERATE-NAMED=StructName:Account,TagKey:json\n\n" +
+			"// Order has Field members tagged with json only\ntype Order struct {\n\tID named.Field[string] `json:\"id\"`\n}\n\n" +
+			"type Item struct {\n\tSKU string `named:\"sku\"`\n}\n\n" +
+			"// GENERATE-NAMED=StructName:Cart,TagKey:json\n// GENERATE-NAMED=StructName:Cart,TagKey:db\ntype Cart struct {\n\tID string `json:\"id\" db:\"id\"`\n}\n",
+	})
+
+	code, out := runOutput(t, Options{Lint: true, JSON: true}, dir)
+	if code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatal(err)
+	}
+	order := filepath.Join(dir, "orders/order.go")
+	want := []string{
+		order + ":8: struct Order has named.Field members but no GENERATE-NAMED directive",
+		order + ":12: struct Item has named tags but no GENERATE-NAMED directive",
+		order + ":5: GENERATE-NAMED directive for unknown struct Account",
+		order + ":16: conflicting TagKeys json and db (" + order + ":17) for struct Cart",
+		generated + ": edited manually, the content hash of the header doesn't match",
+	}
+	slices.Sort(want)
+	if got := slices.Sorted(slices.Values(report.Lint)); !slices.Equal(got, want) {
+		t.Errorf("Expected the lint problems\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	// the edited file is reported by its hash, not as stale
+	if slices.Contains(report.Stale, generated) {
+		t.Errorf("Expected %s to be reported as edited only, got %v", generated, report.Stale)
+	}
+
+	// nothing is written
+	if data, err := os.ReadFile(generated); err != nil || string(data) != edited {
+		t.Errorf("Expected %s to be left as is, got %v", generated, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "orders/order_named_generated.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be generated, got %v", err)
+	}
+}
+
+func TestGenerate_SchemaDuplicateWire(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"user.go": "package users\n\nimport \"github.com/alvarolm/named\"\n\n// GENERATE-NAMED TagKey:db,Schema:true\ntype User struct {\n" +
+			"\tHome named.Field[string] `db:\"email\" named:\"home\"`\n" +
+			"\tWork named.Field[string] `db:\"email\" named:\"work\"`\n}\n",
+	})
+
+	_, err := Generate(Options{}, dir)
+	if err == nil || !strings.Contains(err.Error(), `duplicate db name "email" used by both Home and Work`) {
+		t.Errorf("Expected a duplicate db name error, got %v", err)
+	}
+}
+
+func TestGenerate_Listing(t *testing.T) {
+	dir := t.TempDir()
+	src := "package users\n\n// GENERATE-NAMED TagKey:json%s\ntype User struct {\n\tFields string `json:\"fields\"`\n\tAll    string `json:\"all\"`\n}\n"
+
+	// without Listing, the names are free and the named package isn't imported
+	writeFiles(t, dir, map[string]string{"user.go": fmt.Sprintf(src, "")})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	file := filepath.Join(dir, "user_named_generated.go")
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Imports) > 0 {
+		t.Errorf("Expected no imports, got %d", len(f.Imports))
+	}
+
+	writeFiles(t, dir, map[string]string{"user.go": fmt.Sprintf(src, ",Listing:true")})
+	if _, err := Generate(Options{}, dir); err == nil || !strings.Contains(err.Error(), "field Fields conflicts with the generated Fields method") {
+		t.Errorf("Expected a conflict with the Fields method, got %v", err)
+	}
+
+	writeFiles(t, dir, map[string]string{"user.go": strings.Replace(fmt.Sprintf(src, ",Listing:true"), "\tFields string `json:\"fields\"`\n\tAll    string `json:\"all\"`\n", "\tEmail string `json:\"email\"`\n", 1)})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type UserFieldName struct {",
+		"func (userNamed) Fields() []string {",
+		"func (userNamed) All() []UserFieldName {",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "github.com/alvarolm/named") {
+		t.Errorf("Expected no import of the named package in\n%s", data)
+	}
+}
+
+func TestGenerate_TagOptions(t *testing.T) {
+	dir := t.TempDir()
+	src := "package users\n\n// GENERATE-NAMED TagKey:json%s\ntype User struct {\n\tEmail   string `json:\"email,omitempty\"`\n\tOptions string `json:\"options\"`\n}\n"
+
+	writeFiles(t, dir, map[string]string{"user.go": fmt.Sprintf(src, "")})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	file := filepath.Join(dir, "user_named_generated.go")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "func (userNamed) Options() string {") {
+		t.Errorf("Expected the accessor of the Options field in\n%s", data)
+	}
+
+	writeFiles(t, dir, map[string]string{"user.go": fmt.Sprintf(src, ",TagOptions:true")})
+	if _, err := Generate(Options{}, dir); err == nil || !strings.Contains(err.Error(), "field Options conflicts with the generated Options method") {
+		t.Errorf("Expected a conflict with the Options method, got %v", err)
+	}
+
+	writeFiles(t, dir, map[string]string{"user.go": strings.Replace(fmt.Sprintf(src, ",TagOptions:true"), "\tOptions string `json:\"options\"`\n", "", 1)})
+	if _, err := Generate(Options{}, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err = os.ReadFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\tcase \"Email\":\n\t\treturn []string{\"omitempty\"}\n"; !strings.Contains(string(data), want) {
+		t.Errorf("Expected %q in\n%s", want, data)
+	}
+}
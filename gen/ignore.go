@@ -0,0 +1,169 @@
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the file of the paths to skip, in gitignore syntax, at the
+// root of a module (or of a path outside of modules)
+const ignoreFileName = ".namedignore"
+
+// ignoreRule is a pattern of an ignore file
+type ignoreRule struct {
+	pattern  string // slash separated, without the leading "!" and "/" and trailing "/"
+	negate   bool   // "!" prefix, including again the paths of earlier patterns
+	dirOnly  bool   // "/" suffix, matching directories only
+	anchored bool   // a "/" before the end, matching from the root of the file
+}
+
+// ignoreFile holds the rules of an ignore file in the directory root
+type ignoreFile struct {
+	root  string // absolute
+	rules []ignoreRule
+}
+
+// ignoreFiles are the ignore files of the modules of the paths, see
+// loadIgnoreFile
+var ignoreFiles []*ignoreFile
+
+// parseIgnoreFile parses the content of the ignore file of root: a pattern
+// per line as .gitignore, blank lines and # comments skipped
+func parseIgnoreFile(root string, data []byte) (*ignoreFile, error) {
+	f := &ignoreFile{root: root}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if text, rule.negate = strings.CutPrefix(text, "!"); !rule.negate {
+			text = strings.TrimPrefix(text, `\`) // escaped leading ! or #
+		}
+		text, rule.dirOnly = strings.CutSuffix(text, "/")
+		rule.anchored = strings.Contains(text, "/")
+		rule.pattern = strings.TrimPrefix(text, "/")
+		if rule.pattern == "" {
+			continue
+		}
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern %q: %v", ignoreFileName, line, text, err)
+		}
+		f.rules = append(f.rules, rule)
+	}
+	return f, scanner.Err()
+}
+
+// loadIgnoreFile adds the ignore file of the module of path (or of path
+// itself, a directory outside of modules), if any, to ignoreFiles
+func loadIgnoreFile(path string) error {
+	root := moduleRoot(path)
+	if root == "" {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if root, err = filepath.Abs(path); err != nil {
+			return err
+		}
+	}
+	for _, f := range ignoreFiles {
+		if f.root == root {
+			return nil
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, ignoreFileName))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	f, err := parseIgnoreFile(root, data)
+	if err != nil {
+		return err
+	}
+	logVerbose("Using %s", filepath.Join(root, ignoreFileName))
+	ignoreFiles = append(ignoreFiles, f)
+	return nil
+}
+
+// ignored reports whether path is skipped by an ignore file: it, or one of
+// its parent directories, matches the last matching pattern of the file,
+// unless negated
+func ignored(path string) bool {
+	if len(ignoreFiles) == 0 {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, f := range ignoreFiles {
+		rel, err := filepath.Rel(f.root, abs)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		elems := strings.Split(filepath.ToSlash(rel), "/")
+		for i := range elems {
+			isDir := i < len(elems)-1
+			if !isDir {
+				info, err := os.Stat(abs)
+				isDir = err == nil && info.IsDir()
+			}
+			if f.matches(elems[:i+1], isDir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches reports whether the path of elems, relative to the root of f, is
+// ignored by its rules, the last matching one deciding
+func (f *ignoreFile) matches(elems []string, isDir bool) bool {
+	ignore := false
+	for _, rule := range f.rules {
+		if rule.dirOnly && !isDir || rule.negate != ignore {
+			continue
+		}
+		var ok bool
+		if rule.anchored {
+			ok = matchElems(strings.Split(rule.pattern, "/"), elems)
+		} else {
+			ok, _ = path.Match(rule.pattern, elems[len(elems)-1])
+		}
+		if ok {
+			ignore = !rule.negate
+		}
+	}
+	return ignore
+}
+
+// matchElems reports whether the path elements elems match the pattern
+// elements, "**" matching any number of elements
+func matchElems(pattern, elems []string) bool {
+	if len(pattern) == 0 {
+		return len(elems) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(elems); i++ {
+			if matchElems(pattern[1:], elems[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elems) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], elems[0])
+	return ok && matchElems(pattern[1:], elems[1:])
+}
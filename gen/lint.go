@@ -0,0 +1,220 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// The -lint mode reports the problems of the directives without generating
+// anything: directives for unknown structs, conflicting TagKeys, structs with
+// named.Field members or named tags but no directive, the generated files
+// edited manually (their content not matching the hash of their header),
+// along with the generated files that are missing or stale, as reported by
+// -check, and the generation failures.
+
+var (
+	// report the problems of the directives and generated files, see lintPath
+	lint bool
+
+	// problems found with -lint
+	lintFindings   []string
+	lintFindingsMu sync.Mutex
+)
+
+// lintf logs a problem found with -lint, and records it
+func lintf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	logger.Warn("Lint: "+msg, "action", "lint")
+	lintFindingsMu.Lock()
+	lintFindings = append(lintFindings, msg)
+	lintFindingsMu.Unlock()
+}
+
+// lintDirective is a comment directive, with its position
+type lintDirective struct {
+	dir directive
+	pos string
+}
+
+// lintPath reports the problems of the directives of the packages of path
+// (the package of a file, or the packages of a directory, recursively if
+// recursive)
+func lintPath(path string, recursive bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return lintPackage(filepath.Dir(path))
+	}
+	return walkGoPackages(path, func(dir string) error {
+		if !recursive && dir != path {
+			return nil
+		}
+		return lintPackage(dir)
+	})
+}
+
+// lintPackage reports the problems of the directives of the package at dir.
+// Its external test package, with -tests, is linted on its own.
+func lintPackage(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	filesByPkg := make(map[string][]*ast.File)
+	for _, entry := range entries {
+		fullPath := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || excluded(fullPath) {
+			continue
+		}
+		if strings.HasSuffix(fullPath, ".go") && isGeneratedFile(fullPath) {
+			if err := lintGeneratedFile(fullPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if !isSourceFile(fullPath) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, fullPath, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			lintf("%s: %v", fullPath, err)
+			continue
+		}
+		filesByPkg[file.Name.Name] = append(filesByPkg[file.Name.Name], file)
+	}
+
+	for _, pkgName := range slices.Sorted(maps.Keys(filesByPkg)) {
+		if err := lintFiles(fset, dir, filesByPkg[pkgName]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lintGeneratedFile reports the generated file path edited since generated:
+// its content doesn't match the hash of its header (see withContentHash). The
+// files of the templates leaving out the header have no hash to check.
+func lintGeneratedFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !hasGeneratedHeader(content) || validContentHash(content):
+	case !bytes.HasPrefix(content, []byte(generatedHeader+"\n"+contentHashPrefix)):
+		lintf("%s: no content hash in the header, generated by an older generate-named", path)
+	default:
+		lintf("%s: edited manually, the content hash of the header doesn't match", path)
+	}
+	return nil
+}
+
+// lintFiles reports the problems of the directives of the files of a package
+func lintFiles(fset *token.FileSet, dir string, files []*ast.File) error {
+	pkgStructs := make(map[string]*ast.StructType)
+	pos := func(p token.Pos) string {
+		position := fset.Position(p)
+		return fmt.Sprintf("%s:%d", position.Filename, position.Line)
+	}
+	structPos := make(map[string]string)
+	comments := make(map[string][]lintDirective) // by struct name
+	docs := make(directives)
+	for _, file := range files {
+		collectStructTypes(file, pkgStructs)
+		for _, decl := range file.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
+				for _, spec := range genDecl.Specs {
+					structPos[spec.(*ast.TypeSpec).Name.Name] = pos(spec.Pos())
+				}
+			}
+		}
+
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+				if !strings.HasPrefix(text, directivePrefix) {
+					continue
+				}
+				if structName, d := parseStructDirective(text); structName != "" {
+					comments[structName] = append(comments[structName], lintDirective{d, pos(comment.Pos())})
+				}
+			}
+		}
+		maps.Copy(docs, docDirectives(file))
+	}
+
+	// directives naming structs that don't exist, e.g. renamed ones
+	for _, structName := range slices.Sorted(maps.Keys(comments)) {
+		if _, ok := pkgStructs[structName]; !ok && structName != wildcardStructName {
+			lintf("%s: GENERATE-NAMED directive for unknown struct %s", comments[structName][0].pos, structName)
+		}
+	}
+
+	// directives of the same struct disagreeing, the doc comment one included
+	merged := make(directives)
+	for _, structName := range slices.Sorted(maps.Keys(comments)) {
+		found := comments[structName]
+		if doc, ok := docs[structName]; ok {
+			found = append(found, lintDirective{doc, structPos[structName]})
+		}
+		for _, other := range found[1:] {
+			first := found[0]
+			switch {
+			case other.dir.tagKey != first.dir.tagKey:
+				lintf("%s: conflicting TagKeys %s and %s (%s) for struct %s",
+					first.pos, first.dir.tagKey, other.dir.tagKey, other.pos, structName)
+			case other.dir != first.dir:
+				lintf("%s: conflicting GENERATE-NAMED directives for struct %s (%s)",
+					first.pos, structName, other.pos)
+			}
+		}
+		merged[structName] = found[0].dir
+	}
+	maps.Copy(merged, docs)
+	if err := addConfigDirectives(merged, dir, slices.Sorted(maps.Keys(pkgStructs))); err != nil {
+		return err
+	}
+
+	// structs with named.Field members (whatever their tag key) or named tags
+	// but no directive, unless the accessors of the annotated structs cover them
+	referenced := make(map[string]bool)
+	for structName, structType := range pkgStructs {
+		if _, ok := merged.lookup(structName); ok {
+			ast.Inspect(structType, func(node ast.Node) bool {
+				if ident, ok := node.(*ast.Ident); ok {
+					referenced[ident.Name] = true
+				}
+				return true
+			})
+		}
+	}
+	for _, structName := range slices.Sorted(maps.Keys(pkgStructs)) {
+		if _, ok := merged.lookup(structName); ok || referenced[structName] {
+			continue
+		}
+		for _, field := range pkgStructs[structName].Fields.List {
+			if _, ok := astFieldValue(field.Type); ok {
+				lintf("%s: struct %s has named.Field members but no GENERATE-NAMED directive", structPos[structName], structName)
+				break
+			}
+			if tagName(structTag(field.Tag), namedTagKey) != "" {
+				lintf("%s: struct %s has %s tags but no GENERATE-NAMED directive", structPos[structName], structName, namedTagKey)
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// formats of the -log-format option
+const (
+	logFormatPlain = "plain" // the messages alone, progress to stdout
+	logFormatText  = "text"  // slog key=value records, to stderr
+	logFormatJSON  = "json"  // slog JSON records, to stderr
+)
+
+// logger prints the progress, verbose lines (debug level), warnings and
+// errors of the runs, with the attributes action, package, file, struct or
+// error when known
+var logger = slog.New(plainHandler{level: slog.LevelInfo})
+
+// newLogger returns the logger of a -log-format, logging the debug records in
+// verbose mode
+func newLogger(format string, verbose bool) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "", logFormatPlain:
+		return slog.New(plainHandler{level: level}), nil
+	case logFormatText:
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case logFormatJSON:
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q: expected %s, %s or %s", format, logFormatPlain, logFormatText, logFormatJSON)
+}
+
+// plainHandler prints the messages of the records without their attributes:
+// the info ones to stdout (unless it holds the -json report), the others to
+// stderr, the debug ones prefixed by [verbose]
+type plainHandler struct {
+	level slog.Level
+}
+
+func (h plainHandler) Enabled(_ context.Context, level slog.Level) bool { return level >= h.level }
+
+func (h plainHandler) Handle(_ context.Context, r slog.Record) error {
+	var err error
+	switch {
+	case r.Level < slog.LevelInfo:
+		_, err = fmt.Fprintf(os.Stderr, "[verbose] %s\n", r.Message)
+	case r.Level == slog.LevelInfo:
+		if !jsonReport {
+			_, err = fmt.Println(r.Message)
+		}
+	default:
+		_, err = fmt.Fprintln(os.Stderr, r.Message)
+	}
+	return err
+}
+
+func (h plainHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h plainHandler) WithGroup(string) slog.Handler { return h }
+
+// logVerbose logs a verbose line, without attributes
+func logVerbose(format string, args ...any) {
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+// logError logs the error err of a path
+func logError(msg, path string, err error) {
+	logger.Error(fmt.Sprintf("%s %s: %v", msg, path, err), "action", "error", "path", path, "error", err)
+}
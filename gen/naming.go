@@ -0,0 +1,372 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fallbacks of the untagged fields, see fallbackName
+const (
+	fallbackNone  = "none" // the Go field name
+	fallbackSnake = "snake"
+	fallbackCamel = "camel"
+	fallbackKebab = "kebab"
+	fallbackLower = "lower" // e.g. userid, as the MongoDB driver does
+)
+
+// defaultFallbacks are the fallbacks of the tag keys whose libraries don't
+// use the Go field name, unless set by the Fallback option
+var defaultFallbacks = map[string]string{
+	"bson": fallbackLower,
+	"gorm": fallbackSnake,
+	"xorm": fallbackSnake,
+}
+
+// squashTagKeys are the tag keys whose libraries nest the embedded structs
+// under their type name unless tagged with the squash option, and skip the
+// field of the remain option collecting the other keys, as mapstructure (used
+// by Viper) does
+var squashTagKeys = map[string]bool{
+	"mapstructure": true,
+}
+
+// options of the squash tag keys
+const (
+	squashOption = "squash"
+	remainOption = "remain"
+)
+
+// tagParser returns the name and options of a tag value, name "-" skipping
+// the field and an empty name falling back to the field name
+type tagParser func(value string) (name string, options []string)
+
+// tagParsers are the tag formats, set with the TagFormat option
+var tagParsers = map[string]tagParser{
+	"comma":    parseCommaTag, // name,option,... as encoding/json
+	"gorm":     parseGormTag,
+	"protobuf": parseProtobufTag,
+	"xorm":     parseXormTag,
+}
+
+// defaultTagFormats are the formats of the tag keys not using the comma
+// format, unless set by the TagFormat option
+var defaultTagFormats = map[string]string{
+	"gorm":     "gorm",
+	"protobuf": "protobuf",
+	"xorm":     "xorm",
+}
+
+// fieldTagKey ends a chain of tag keys (e.g. TagKey:json|yaml|field), naming
+// the fields tagged with none of them after the field name, see fallbackName
+const fieldTagKey = "field"
+
+// naming holds the options of a directive deciding the names of the fields
+type naming struct {
+	tagKeys  []string // looked up in order, see tagKeys
+	fallback string   // names the untagged fields, see fallbackName
+	parsers  []tagParser
+	squash   bool // embedded structs flattened by the squash option only, see squashTagKeys
+
+	unexported bool              // IncludeUnexported
+	renames    map[string]string // names of the top level fields, by Go name, see parseRenames
+}
+
+// tagKeys returns the tag keys of the chain tagKey (e.g. json|yaml|field),
+// without the trailing field
+func tagKeys(tagKey string) ([]string, error) {
+	keys := strings.Split(tagKey, "|")
+	for i, key := range keys {
+		switch {
+		case key == fieldTagKey && i == len(keys)-1 && i > 0:
+			return keys[:i], nil
+		case key == "" || key == fieldTagKey || strings.ContainsAny(key, ` :"`):
+			return nil, fmt.Errorf("invalid %s %q: expected a tag key, or tag keys looked up in order like json|yaml|%s",
+				tagKeyKey, tagKey, fieldTagKey)
+		}
+	}
+	return keys, nil
+}
+
+// naming returns the naming options of the directive of the struct
+// structName, generated into outDir
+func (d directive) naming(structName, outDir string) (naming, error) {
+	keys, err := tagKeys(d.tagKey)
+	if err != nil {
+		return naming{}, fmt.Errorf("struct %s: %v", structName, err)
+	}
+	n := naming{tagKeys: keys, fallback: d.fallback, squash: squashTagKeys[keys[0]]}
+	if n.unexported, err = boolOption(structName, unexportedKey, d.unexported); err != nil {
+		return naming{}, err
+	}
+	if n.unexported && outDir != "" {
+		// the accessors of the unexported fields would be unexported in another package
+		return naming{}, fmt.Errorf("struct %s: %s requires the accessors in the package of the struct", structName, unexportedKey)
+	}
+	if n.renames, err = parseRenames(d.rename); err != nil {
+		return naming{}, fmt.Errorf("struct %s: %v", structName, err)
+	}
+	switch n.fallback {
+	case "":
+		n.fallback = defaultFallbacks[keys[0]]
+	case fallbackNone, fallbackSnake, fallbackCamel, fallbackKebab, fallbackLower:
+	default:
+		return naming{}, fmt.Errorf("struct %s: invalid %s %q: expected %s, %s, %s, %s or %s", structName,
+			fallbackKey, n.fallback, fallbackSnake, fallbackCamel, fallbackKebab, fallbackLower, fallbackNone)
+	}
+
+	for _, key := range keys {
+		format := d.tagFormat
+		if format == "" {
+			format = defaultTagFormats[key]
+		}
+		if format == "" {
+			format = "comma"
+		}
+		parser, ok := tagParsers[format]
+		if !ok {
+			return naming{}, fmt.Errorf("struct %s: invalid %s %q: expected %s", structName,
+				tagFormatKey, format, strings.Join(slices.Sorted(maps.Keys(tagParsers)), ", "))
+		}
+		n.parsers = append(n.parsers, parser)
+	}
+	return n, nil
+}
+
+// parseTag returns the name and options of the value of the first tag key of
+// n naming the field in the struct tag tag ("-" included), else empty with
+// the options of the first one present
+func (n naming) parseTag(tag string) (string, []string) {
+	var first []string
+	found := false
+	for i, key := range n.tagKeys {
+		value, ok := reflect.StructTag(tag).Lookup(key)
+		if !ok {
+			continue
+		}
+		name, options := n.parsers[i](value)
+		if name != "" {
+			return name, options
+		}
+		if !found {
+			first, found = options, true
+		}
+	}
+	return "", first
+}
+
+// parseRenames parses the value of the Rename option, e.g.
+// Password=pwd_hash|Email=mail, returning the names by Go field name
+func parseRenames(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	renames := make(map[string]string)
+	for _, rename := range strings.Split(value, "|") {
+		goName, name, ok := strings.Cut(rename, "=")
+		switch {
+		case !ok || !token.IsIdentifier(goName) || name == "" || name == "-" || strings.ContainsAny(name, "\"`"):
+			return nil, fmt.Errorf("invalid %s %q: expected GoName=name pairs separated by |, e.g. Password=pwd_hash", renameKey, rename)
+		case renames[goName] != "":
+			return nil, fmt.Errorf("invalid %s: field %s renamed twice", renameKey, goName)
+		}
+		renames[goName] = name
+	}
+	return renames, nil
+}
+
+// rename returns the name of the field fieldName set by the Rename option,
+// for the top level fields (without parent), empty otherwise
+func (n naming) rename(fieldName string, parent []string) string {
+	if len(parent) > 0 {
+		return ""
+	}
+	return n.renames[fieldName]
+}
+
+// checkRenames fails if the Rename option of n names a field missing from
+// fields, or along the Patch option: the patch would be decoded after the tags
+func checkRenames(structName string, n naming, patch bool, fields []fieldInfo) error {
+	if len(n.renames) == 0 {
+		return nil
+	}
+	if patch {
+		return fmt.Errorf("struct %s: %s doesn't support %s", structName, renameKey, patchKey)
+	}
+	for _, goName := range slices.Sorted(maps.Keys(n.renames)) {
+		if !slices.ContainsFunc(fields, func(field fieldInfo) bool { return field.name == goName }) {
+			return fmt.Errorf("struct %s: %s of unknown field %s", structName, renameKey, goName)
+		}
+	}
+	return nil
+}
+
+// skips reports whether the field of tag name and options, and named tag
+// override, is left out: named "-", or collecting the remaining keys
+func (n naming) skips(name, override string, options []string) bool {
+	return name == "-" || override == "-" || n.squash && slices.Contains(options, remainOption)
+}
+
+// flattens reports whether the embedded field of tag name and options, and
+// named tag override, has its fields promoted: untagged as with encoding/json,
+// or with the squash option for the squash tag keys
+func (n naming) flattens(name, override string, options []string) bool {
+	switch {
+	case override != "":
+		return false
+	case n.squash:
+		return slices.Contains(options, squashOption)
+	default:
+		return name == ""
+	}
+}
+
+// parseCommaTag parses a tag value like "name,omitempty"
+func parseCommaTag(value string) (string, []string) {
+	name, rest, found := strings.Cut(value, ",")
+	if !found {
+		return name, nil
+	}
+	var options []string
+	for _, option := range strings.Split(rest, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return name, options
+}
+
+// parseGormTag parses a tag value like "column:user_id;type:bigint", the
+// settings other than the column being the options
+func parseGormTag(value string) (string, []string) {
+	if value == "-" || strings.HasPrefix(value, "-:") && value != "-:migration" {
+		return "-", nil
+	}
+	var name string
+	var options []string
+	for _, setting := range strings.Split(value, ";") {
+		setting = strings.TrimSpace(setting)
+		key, column, _ := strings.Cut(setting, ":")
+		switch {
+		case setting == "":
+		case strings.EqualFold(strings.TrimSpace(key), "column"):
+			name = strings.TrimSpace(column)
+		default:
+			options = append(options, setting)
+		}
+	}
+	return name, options
+}
+
+// parseXormTag parses a tag value like "varchar(25) notnull 'user_name'", the
+// quoted token being the column and the others the options
+func parseXormTag(value string) (string, []string) {
+	if strings.TrimSpace(value) == "-" {
+		return "-", nil
+	}
+	var name string
+	var options []string
+	for _, token := range strings.Fields(value) {
+		if len(token) >= 2 && token[0] == '\'' && token[len(token)-1] == '\'' {
+			name = token[1 : len(token)-1]
+			continue
+		}
+		options = append(options, token)
+	}
+	return name, options
+}
+
+// parseProtobufTag parses a tag value of protoc-gen-go like
+// "bytes,3,opt,name=email,json=emailAddress,proto3", the name being the one
+// of the name= setting and the others (wire type, number...) the options
+func parseProtobufTag(value string) (string, []string) {
+	var name string
+	var options []string
+	for _, setting := range strings.Split(value, ",") {
+		if n, ok := strings.CutPrefix(setting, "name="); ok {
+			name = n
+			continue
+		}
+		if setting != "" {
+			options = append(options, setting)
+		}
+	}
+	return name, options
+}
+
+// numberSuffix suffixes the methods returning the field numbers, e.g.
+// EmailNumber
+const numberSuffix = "Number"
+
+// protobufNumber returns the field number of the protobuf tag of the struct
+// tag tag, e.g. 3 for `protobuf:"bytes,3,opt,name=email"`, empty without one
+func protobufNumber(tag string) string {
+	value, ok := reflect.StructTag(tag).Lookup("protobuf")
+	if !ok {
+		return ""
+	}
+	settings := strings.Split(value, ",")
+	if len(settings) < 2 {
+		return ""
+	}
+	if number, err := strconv.ParseInt(settings[1], 10, 32); err != nil || number <= 0 {
+		return ""
+	}
+	return settings[1]
+}
+
+// fallbackName returns the name of the untagged field fieldName: the field
+// name converted to the fallback case (UserID: user_id, userID or user-id)
+func (n naming) fallbackName(fieldName string) string {
+	switch n.fallback {
+	case fallbackSnake:
+		return strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+	case fallbackKebab:
+		return strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+	case fallbackCamel:
+		words := splitWords(fieldName)
+		words[0] = strings.ToLower(words[0])
+		return strings.Join(words, "")
+	case fallbackLower:
+		return strings.ToLower(fieldName)
+	default:
+		return fieldName
+	}
+}
+
+// splitWords splits a Go identifier into words, keeping acronyms (ID, HTTP)
+// together as the repo package does: HTTPServerID is HTTP, Server and ID
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	if len(words) == 0 {
+		return []string{name}
+	}
+	return words
+}
@@ -0,0 +1,148 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The Patch option generates <name>Patch, the struct of the top level fields
+// of the annotated struct wrapped by named.Field (named.FieldSlice for
+// slices) with the same tags, along with the <name>ToPatch and
+// Apply<name>Patch converters.
+
+// exprTraits returns the element type of the slice type expression expr, if
+// any, whether the type may be comparable (named types are assumed to be, as
+// they are not resolved) and the names of the packages it refers to
+func exprTraits(expr ast.Expr) (elemType string, comparable bool, pkgNames []string) {
+	if array, ok := expr.(*ast.ArrayType); ok && array.Len == nil {
+		elemType = types.ExprString(array.Elt)
+	}
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && !slices.Contains(pkgNames, ident.Name) {
+				pkgNames = append(pkgNames, ident.Name)
+			}
+		}
+		return true
+	})
+	return elemType, exprComparable(expr), pkgNames
+}
+
+// exprComparable reports whether the type expression expr may be comparable
+func exprComparable(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.ArrayType:
+		return expr.Len != nil && exprComparable(expr.Elt)
+	case *ast.MapType, *ast.FuncType:
+		return false
+	case *ast.StructType:
+		for _, field := range expr.Fields.List {
+			if !exprComparable(field.Type) {
+				return false
+			}
+		}
+	case *ast.ParenExpr:
+		return exprComparable(expr.X)
+	}
+	return true
+}
+
+// typeTraits is the typed counterpart of exprTraits, returning the type
+// strings of t and its slice elements qualified by the names of their
+// packages, recorded in imports by name
+func typeTraits(t types.Type, pkg *types.Package, imports map[string]string) (goType, elemType string, comparable bool) {
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		imports[p.Name()] = p.Path()
+		return p.Name()
+	}
+	if _, ok := types.Unalias(t).(*types.Pointer); !ok {
+		if slice, ok := t.Underlying().(*types.Slice); ok {
+			elemType = types.TypeString(slice.Elem(), qualifier)
+		}
+	}
+	return types.TypeString(t, qualifier), elemType, types.Comparable(t)
+}
+
+// checkPatch fails if the patch of the struct structName, when enabled, can't
+// be generated: into another package than the struct, or with fields neither
+// comparable nor slices
+func checkPatch(structName string, enabled bool, outDir string, fields []fieldInfo) error {
+	if !enabled {
+		return nil
+	}
+	if outDir != "" {
+		return fmt.Errorf("struct %s: %s requires the accessors in the package of the struct", structName, patchKey)
+	}
+	for _, field := range fields {
+		if !field.comparable && field.elemType == "" {
+			return fmt.Errorf("struct %s: field %s of type %s can't be wrapped by named.Field", structName, field.name, field.goType)
+		}
+	}
+	return nil
+}
+
+// resolvePatchImports returns the imports of the patch of fields, by path,
+// with their name if file imports them under another name than their package
+// name, resolving the package names used by the fields against the imports
+// of file
+func resolvePatchImports(structName string, fields []fieldInfo, file *ast.File) (map[string]string, error) {
+	imports := make(map[string]string)
+	for _, field := range fields {
+		for _, name := range field.pkgNames {
+			found := false
+			for _, spec := range file.Imports {
+				importPath, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					continue
+				}
+				if spec.Name != nil && spec.Name.Name == name {
+					imports[importPath], found = name, true
+					break
+				}
+				if spec.Name == nil && importName(importPath) == name {
+					imports[importPath], found = "", true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("struct %s: package %s of field %s is not imported by its file, try -typed",
+					structName, name, field.name)
+			}
+		}
+	}
+	return imports, nil
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$|\.v[0-9]+$`)
+
+// importName guesses the package name of importPath, following the usual
+// conventions: the last element, without go- prefix nor version suffix (e.g.
+// gopkg.in/yaml.v3, github.com/x/y/v2)
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if versionSuffix.MatchString(name) && strings.HasPrefix(name, "v") && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	name = versionSuffix.ReplaceAllString(name, "")
+	return strings.TrimPrefix(name, "go-")
+}
+
+// patchTag returns the literal of the struct tag tag, empty if none
+func patchTag(tag string) string {
+	switch {
+	case tag == "":
+		return ""
+	case strings.Contains(tag, "`"):
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
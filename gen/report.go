@@ -0,0 +1,228 @@
+package gen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// structError is the error of an annotated struct, the others of its file
+// are still generated
+type structError struct {
+	structName string
+	err        error
+}
+
+func (e *structError) Error() string { return e.err.Error() }
+
+func (e *structError) Unwrap() error { return e.err }
+
+// failure is an error recorded with -keep-going
+type failure struct {
+	path       string // file, or package directory
+	structName string // empty for file or package errors
+	err        error
+}
+
+var (
+	failures   []failure
+	failuresMu sync.Mutex
+)
+
+// keepGoingErr records err for path, returning nil, with -keep-going.
+// Otherwise, it returns err, or its first error when joining several
+// structErrors.
+func keepGoingErr(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else {
+		errs = []error{err}
+	}
+
+	if !keepGoing {
+		return errs[0]
+	}
+
+	for _, err := range errs {
+		recordFailure(path, err)
+	}
+	return nil
+}
+
+// recordFailure records the error err of path, with the struct name of a
+// structError
+func recordFailure(path string, err error) {
+	f := failure{path: path, err: err}
+	var serr *structError
+	if errors.As(err, &serr) {
+		f.structName = serr.structName
+	}
+	failuresMu.Lock()
+	failures = append(failures, f)
+	failuresMu.Unlock()
+}
+
+// logFailures logs the summary of the recorded failures, sorted by path as
+// they are recorded in any order with -j
+func logFailures() {
+	sortFailures()
+	logger.Error(fmt.Sprintf("%d failure(s):", len(failures)), "action", "failures", "count", len(failures))
+	for _, f := range failures {
+		if f.structName != "" {
+			logger.Error(fmt.Sprintf("  %s: %s: %v", f.path, f.structName, f.err), "action", "failure", "file", f.path, "struct", f.structName, "error", f.err)
+		} else {
+			logger.Error(fmt.Sprintf("  %s: %v", f.path, f.err), "action", "failure", "path", f.path, "error", f.err)
+		}
+	}
+}
+
+func sortFailures() {
+	slices.SortStableFunc(failures, func(a, b failure) int { return strings.Compare(a.path, b.path) })
+}
+
+// Report is the summary of a run printed with -json, in place of the progress
+// lines. The paths are sorted, as packages are processed in any order with -j.
+type Report struct {
+	Scanned  []string  `json:"scanned"`  // source files
+	Structs  []Struct  `json:"structs"`  // annotated structs
+	Written  []string  `json:"written"`  // generated files
+	Removed  []string  `json:"removed"`  // with -clean
+	Stale    []string  `json:"stale"`    // with -check
+	Warnings []string  `json:"warnings"` // also printed to stderr
+	Lint     []string  `json:"lint"`     // with -lint, also printed to stderr
+	Failures []Failure `json:"failures"`
+}
+
+// Struct is a struct matching a directive
+type Struct struct {
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	Directive string `json:"directive"` // StructName of the directive: the struct name, or * for the wildcard
+	TagKey    string `json:"tagKey"`
+	Output    string `json:"output"` // methods, consts or both
+	Fields    int    `json:"fields"`
+	Generated string `json:"generated"` // generated file
+}
+
+// Failure is an error of a file, package or struct
+type Failure struct {
+	Path   string `json:"path"`
+	Struct string `json:"struct,omitempty"`
+	Error  string `json:"error"`
+}
+
+var (
+	runReport Report
+	reportMu  sync.Mutex
+)
+
+// reportScanned records the source file path as scanned
+func reportScanned(path string) {
+	reportMu.Lock()
+	runReport.Scanned = append(runReport.Scanned, path)
+	reportMu.Unlock()
+}
+
+// reportStruct records the struct s of the source file path, generated into
+// generated
+func reportStruct(path string, s structInfo, generated string) {
+	reportMu.Lock()
+	runReport.Structs = append(runReport.Structs, Struct{
+		Name:      s.name,
+		File:      path,
+		Directive: s.directive,
+		TagKey:    s.tagKey,
+		Output:    s.output,
+		Fields:    len(s.fields),
+		Generated: generated,
+	})
+	reportMu.Unlock()
+}
+
+// reportWritten logs and records the generated file path
+func reportWritten(path string) {
+	logger.Info("Generated: "+path, "action", "generated", "file", path)
+	reportMu.Lock()
+	runReport.Written = append(runReport.Written, path)
+	reportMu.Unlock()
+}
+
+// reportRemoved logs and records the removed file path
+func reportRemoved(path string) {
+	logger.Info("Removed: "+path, "action", "removed", "file", path)
+	reportMu.Lock()
+	runReport.Removed = append(runReport.Removed, path)
+	reportMu.Unlock()
+}
+
+// warnf logs a warning, and records it
+func warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	logger.Warn("Warning: "+msg, "action", "warning")
+	reportMu.Lock()
+	runReport.Warnings = append(runReport.Warnings, msg)
+	reportMu.Unlock()
+}
+
+// buildReport returns the report of the run
+func buildReport() *Report {
+	r := runReport
+	slices.Sort(r.Scanned)
+	slices.SortFunc(r.Structs, func(a, b Struct) int {
+		if c := strings.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.Sort(r.Written)
+	slices.Sort(r.Removed)
+	r.Stale = slices.Sorted(slices.Values(staleFiles))
+	r.Lint = slices.Clone(lintFindings)
+	sortFailures()
+	for _, f := range failures {
+		r.Failures = append(r.Failures, Failure{Path: f.path, Struct: f.structName, Error: f.err.Error()})
+	}
+
+	// empty lists rather than null, easier to consume
+	for _, list := range []*[]string{&r.Scanned, &r.Written, &r.Removed, &r.Stale, &r.Warnings, &r.Lint} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if r.Structs == nil {
+		r.Structs = []Struct{}
+	}
+	if r.Failures == nil {
+		r.Failures = []Failure{}
+	}
+	return &r
+}
+
+// writeReport writes the JSON report of the run to w
+func writeReport(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(buildReport())
+}
+
+// finish writes the -json report, if requested, returning the exit status
+// code of the run
+func finish(code int) int {
+	if jsonReport {
+		if err := writeReport(os.Stdout); err != nil {
+			logger.Error("Error writing the report: "+err.Error(), "action", "report", "error", err)
+			code = 1
+		}
+	}
+	return code
+}
@@ -0,0 +1,224 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The Schema option generates Register<name>Schema, registering the schema
+// of the named.Field members of the annotated struct with named.RegisterSchema
+// (paths and offsets computed at compile time, as LoadLink would at runtime
+// through reflection), called by an init function.
+
+// schemaField is a named.Field (or named.FieldSlice) member of a struct, as
+// named.SchemaField
+type schemaField struct {
+	path, wirePath []string
+	goName         string
+	selectors      []string // of the members holding the field, e.g. Address, Address.Value, Address.Value.City
+	sensitive      bool
+	quoted         bool
+	omitEmpty      bool
+}
+
+// schemaMember is a member of a struct, from its syntax or its type
+type schemaMember struct {
+	name     string
+	tag      reflect.StructTag
+	embedded bool
+	field    bool        // of type named.Field or named.FieldSlice
+	value    schemaValue // struct of the Value of a named.Field, nil otherwise
+}
+
+// schemaValue lists the members of a struct
+type schemaValue func() []schemaMember
+
+// collectSchema appends the named.Field members of the struct members to
+// fields, named as the runtime linker does with tagKey: the name of the tag
+// (regardless of its TagFormat and Fallback), or else the Go name, overridden
+// by the named tag. Embedded members are skipped, as by the runtime linker,
+// and the unexported ones unless unexported, as without
+// named.WithIncludeUnexported.
+func collectSchema(fields *[]schemaField, members []schemaMember, tagKey string, unexported bool, parent, parentWire, selectors []string) error {
+	seen := make(map[string]string)     // names of the level, to their Go name
+	seenWire := make(map[string]string) // wire names of the level, to their Go name
+	for _, m := range members {
+		if !m.field || m.embedded || !ast.IsExported(m.name) && !unexported {
+			continue
+		}
+		wire, _, _ := strings.Cut(m.tag.Get(tagKey), ",")
+		override, _, _ := strings.Cut(m.tag.Get(namedTagKey), ",")
+		if wire == "-" || override == "-" {
+			continue
+		}
+		if wire == "" {
+			wire = m.name
+		}
+		name := wire
+		if override != "" {
+			name = override
+		}
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate name %q used by both %s and %s", name, prev, m.name)
+		}
+		seen[name] = m.name
+		// names overridden by the named tag must keep distinct wire names
+		if prev, ok := seenWire[wire]; ok {
+			return fmt.Errorf("duplicate %s name %q used by both %s and %s", tagKey, wire, prev, m.name)
+		}
+		seenWire[wire] = m.name
+
+		json := m.tag.Get("json")
+		field := schemaField{
+			path:      append(parent[:len(parent):len(parent)], name),
+			wirePath:  append(parentWire[:len(parentWire):len(parentWire)], wire),
+			goName:    m.name,
+			sensitive: m.tag.Get("sensitive") == "true",
+			quoted:    hasTagOption(json, "string"),
+			omitEmpty: hasTagOption(json, "omitempty") || hasTagOption(json, "omitzero"),
+		}
+		selector := m.name
+		if len(selectors) > 0 {
+			selector = selectors[len(selectors)-1] + "." + m.name
+		}
+		field.selectors = append(selectors[:len(selectors):len(selectors)], selector)
+		*fields = append(*fields, field)
+
+		if m.value != nil {
+			valueSelectors := append(slices.Clip(field.selectors), selector+".Value")
+			if err := collectSchema(fields, m.value(), tagKey, unexported, field.path, field.wirePath, valueSelectors); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasTagOption reports whether the tag value lists option after its name
+func hasTagOption(value, option string) bool {
+	_, options, _ := strings.Cut(value, ",")
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// astMembers returns the members of structType. The Value of named.Field
+// members is followed into the structs of the package (pkgStructs) and inline
+// ones, structs of other packages are assumed to have no named.Field members.
+func astMembers(structType *ast.StructType, pkgStructs map[string]*ast.StructType) schemaValue {
+	return func() []schemaMember {
+		var members []schemaMember
+		for _, field := range structType.Fields.List {
+			var tag string
+			if field.Tag != nil {
+				tag, _ = strconv.Unquote(field.Tag.Value)
+			}
+			m := schemaMember{tag: reflect.StructTag(tag)}
+			value, isField := astFieldValue(field.Type)
+			m.field = isField
+			if isField {
+				switch value := value.(type) {
+				case *ast.Ident:
+					if nested, ok := pkgStructs[value.Name]; ok {
+						m.value = astMembers(nested, pkgStructs)
+					}
+				case *ast.StructType:
+					m.value = astMembers(value, pkgStructs)
+				}
+			}
+			if len(field.Names) == 0 {
+				m.name, _ = embeddedTypeName(field.Type)
+				m.embedded = true
+				members = append(members, m)
+				continue
+			}
+			for _, name := range field.Names {
+				m.name = name.Name
+				members = append(members, m)
+			}
+		}
+		return members
+	}
+}
+
+// astFieldValue returns the type of the Value of the named.Field (or
+// named.FieldSlice) type expression expr, reporting whether it is one
+func astFieldValue(expr ast.Expr) (ast.Expr, bool) {
+	var generic ast.Expr
+	var value ast.Expr
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		generic, value = t.X, t.Index
+	case *ast.IndexListExpr:
+		generic, value = t.X, t.Indices[0]
+	default:
+		return nil, false
+	}
+	var name string
+	switch x := generic.(type) {
+	case *ast.Ident:
+		name = x.Name
+	case *ast.SelectorExpr:
+		name = x.Sel.Name
+	}
+	return value, name == "Field" || name == "FieldSlice"
+}
+
+// typeMembers is the typed counterpart of astMembers, following the Value of
+// named.Field members into any struct
+func typeMembers(structType *types.Struct) schemaValue {
+	return func() []schemaMember {
+		var members []schemaMember
+		for i := 0; i < structType.NumFields(); i++ {
+			field := structType.Field(i)
+			m := schemaMember{name: field.Name(), tag: reflect.StructTag(structType.Tag(i)), embedded: field.Embedded()}
+			if named, ok := types.Unalias(field.Type()).(*types.Named); ok && named.Obj().Pkg() != nil &&
+				named.Obj().Pkg().Path() == namedImportPath && named.TypeArgs().Len() > 0 {
+				switch named.Obj().Name() {
+				case "Field":
+					m.field = true
+					if value, ok := named.TypeArgs().At(0).Underlying().(*types.Struct); ok {
+						m.value = typeMembers(value)
+					}
+				case "FieldSlice":
+					m.field = true
+				}
+			}
+			members = append(members, m)
+		}
+		return members
+	}
+}
+
+// optionSchema returns the schema fields of the struct structName with the
+// Schema option of dir, named after its TagKey, failing without named.Field
+// members or when generated into another package
+func optionSchema(structName string, dir directive, out, outDir string, members schemaValue) ([]schemaField, error) {
+	enabled, err := methodsOption(structName, schemaKey, dir.schema, out)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	if outDir != "" {
+		return nil, fmt.Errorf("struct %s: %s requires the accessors in the package of the struct", structName, schemaKey)
+	}
+	if strings.Contains(dir.tagKey, "|") {
+		return nil, fmt.Errorf("struct %s: %s requires a single %s, as named.LoadLink", structName, schemaKey, tagKeyKey)
+	}
+
+	var fields []schemaField
+	if err := collectSchema(&fields, members(), dir.tagKey, dir.unexported == "true", nil, nil, nil); err != nil {
+		return nil, fmt.Errorf("struct %s: %v", structName, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("struct %s: %s requires named.Field members", structName, schemaKey)
+	}
+	return fields, nil
+}
@@ -0,0 +1,533 @@
+package gen
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// defaultTemplate renders the generated files, unless replaced by the
+// -template flag or the Template directive option
+//
+//go:embed default.tmpl
+var defaultTemplate string
+
+// templateFuncs are the functions available to the templates, besides the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+	"join":  strings.Join,
+}
+
+var (
+	templates   = make(map[string]*template.Template) // by path, "" for the default one
+	templatesMu sync.Mutex
+)
+
+// loadTemplate returns the template of the file path, parsed once, or the
+// default template if path is empty
+func loadTemplate(path string) (*template.Template, error) {
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+
+	if tmpl, ok := templates[path]; ok {
+		return tmpl, nil
+	}
+
+	text := defaultTemplate
+	name := "default.tmpl"
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text, name = string(data), filepath.Base(path)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	templates[path] = tmpl
+	return tmpl, nil
+}
+
+// resetTemplates forgets the parsed templates, parsed again by the next run
+// as they may be edited in between
+func resetTemplates() {
+	templatesMu.Lock()
+	templates = make(map[string]*template.Template)
+	templatesMu.Unlock()
+}
+
+// resolveTemplate returns the template file of a struct of the package at
+// dir given its Template option, relative to dir, or else the -template flag
+func resolveTemplate(dir, template string) string {
+	if template == "" {
+		return templatePath
+	}
+	if filepath.IsAbs(template) {
+		return template
+	}
+	return filepath.Join(dir, template)
+}
+
+// templateFile is the data of the templates: a generated file
+type templateFile struct {
+	Header     string            // "Code generated ... DO NOT EDIT." comment, followed by the -header lines
+	Constraint string            // build constraint of the source file and -tags, if any
+	Package    string            // package name
+	Imports    []string          // import paths
+	Aliases    map[string]string // names of the imports differing from their package name, by path
+	Structs    []templateStruct
+}
+
+// templateStruct is an annotated struct
+type templateStruct struct {
+	Name       string // e.g. Order
+	TagKey     string
+	Methods    bool              // Output:methods or both
+	Consts     bool              // Output:consts or both, or FieldType
+	FieldType  string            // type of the field names, e.g. OrderField, with the FieldType option
+	Interface  string            // interface of the accessor, e.g. OrderNamer, with the Interface option
+	Method     bool              // Named method of the struct, with the NamedMethod option
+	Receiver   string            // receiver type of the Named method, e.g. Page[T, C] for a generic struct
+	InfoType   string            // named.FieldInfo, qualified as needed, with the FieldInfos option
+	Infos      []templateInfo    // every field, as All, with FieldInfos
+	Qualifier  string            // of the named package, e.g. "named.", empty within it
+	Patch      []templatePatch   // top level fields, with the Patch option
+	Schema     []templateSchema  // named.Field members, with the Schema option
+	Var        string            // accessor variable, e.g. OrderNamed, prefixing the maps
+	Accessor   templateAccessor  // accessor type of Var
+	Constants  []templateConst   // with Consts
+	Pointers   []templateConst   // JSON Pointers of the fields, with the Pointers option
+	Structs    []templateConst   // STRUCT field names of the nested fields, with the StructFields option
+	All        []templateName    // every field, nested ones following their parent
+	Options    []templateOptions // fields with tag options, nested ones following their parent
+	TagOptions bool              // Options method of the accessor, with the TagOptions option
+	Table      string            // SQL table, with the Table option
+	Columns    []string          // names of the top level fields without nested ones, with Table
+	Mongo      bool              // MongoDB helpers, with the Mongo option
+}
+
+// templateAccessor is the accessor type of a struct or of a nested field
+type templateAccessor struct {
+	Type      string   // e.g. orderNamed, orderNamedCustomer
+	Selector  string   // e.g. Order, Order.Customer
+	Name      string   // dotted path of the nested field, empty for a struct
+	Path      []string // path of the nested field
+	Fields    []templateField
+	Result    string         // result type of the field methods: string, or the FieldType
+	HasNested bool           // some fields are nested
+	Leaves    []templateName // fields without children, recursively
+	Rules     bool           // Rules methods of the fields, with the Rules option
+	FieldName string         // type of the fields listed by All, e.g. OrderFieldName, empty without the Listing option
+}
+
+// templateField is a field of an accessor type
+type templateField struct {
+	GoName string            // Go field name
+	Name   string            // dotted path
+	Number string            // field number of its protobuf tag, empty without one
+	Rules  string            // validation rules, from the tag key of the Rules option
+	Nested *templateAccessor // accessor of a nested struct, nil otherwise
+
+	// name qualified by the table (users.email) and by its alias (u.email),
+	// empty without the Qualified option, a table or alias, or for nested
+	// fields
+	Qualified, Aliased string
+}
+
+// templateConst is a constant holding the dotted path of a field
+type templateConst struct {
+	Name  string // e.g. OrderName_Customer_Email
+	Value string
+}
+
+// templateOptions are the options of the tag of a field, e.g. omitempty
+type templateOptions struct {
+	GoName  string // joined with its parents' ones, as in templateName
+	Options []string
+}
+
+// templateInfo describes a field, as named.FieldInfo
+type templateInfo struct {
+	GoName, Name   string // as in templateName
+	Type           string
+	Pointer, Slice bool
+}
+
+// templatePatch is a field of a patch struct
+type templatePatch struct {
+	GoName string
+	Name   string // dotted path
+	Type   string // e.g. named.Field[int]
+	Tag    string // literal, empty without tag
+}
+
+// newTemplatePatch returns the patch field of field: its type wrapped by
+// named.Field, or named.FieldSlice for the slices that aren't comparable
+func newTemplatePatch(field fieldInfo, qualifier string) templatePatch {
+	typ := qualifier + "Field[" + field.goType + "]"
+	if !field.comparable {
+		typ = qualifier + "FieldSlice[" + field.goType + ", " + field.elemType + "]"
+	}
+	return templatePatch{
+		GoName: field.name,
+		Name:   strings.Join(field.path, "."),
+		Type:   typ,
+		Tag:    patchTag(field.tag),
+	}
+}
+
+// templateSchema is a named.Field member of a struct, as named.SchemaField
+type templateSchema struct {
+	Path                         []string
+	WirePath                     []string // nil if equal to Path
+	GoName                       string
+	Offset                       string // constant expression, e.g. unsafe.Offsetof(User{}.Name)
+	Member                       string // expression of the member, e.g. User{}.Name
+	Sensitive, Quoted, OmitEmpty bool
+}
+
+// newTemplateSchema returns the template data of the schema field of the
+// struct structName, its offset summing the offsets of the members holding it
+func newTemplateSchema(structName string, field schemaField) templateSchema {
+	ts := templateSchema{
+		Path:      field.path,
+		GoName:    field.goName,
+		Member:    structName + "{}." + field.selectors[len(field.selectors)-1],
+		Sensitive: field.sensitive,
+		Quoted:    field.quoted,
+		OmitEmpty: field.omitEmpty,
+	}
+	if !slices.Equal(field.path, field.wirePath) {
+		ts.WirePath = field.wirePath
+	}
+	offsets := make([]string, len(field.selectors))
+	for i, selector := range field.selectors {
+		offsets[i] = "unsafe.Offsetof(" + structName + "{}." + selector + ")"
+	}
+	ts.Offset = strings.Join(offsets, " + ")
+	return ts
+}
+
+// templateName pairs the Go name of a field, joined with its parents' ones
+// (e.g. Customer.Email), with its dotted path
+type templateName struct {
+	GoName string
+	Name   string
+}
+
+// newTemplateFile returns the template data of structs, generated in the
+// package at dir
+func newTemplateFile(dir string, structs []structInfo) (*templateFile, error) {
+	file := &templateFile{
+		Header:  generatedHeader,
+		Package: structs[0].pkgName,
+	}
+	header, tags, err := fileSettings(dir)
+	if err != nil {
+		return nil, err
+	}
+	if header != "" {
+		file.Header += "\n" + header
+	}
+	if file.Constraint, err = withBuildTags(structs[0].constraint, tags); err != nil {
+		return nil, err
+	}
+
+	// The FieldInfos functions, the patches and the schemas refer to the
+	// named package, the default output doesn't
+	qualifier := ""
+	for _, s := range structs {
+		if s.fieldInfos || s.patch || len(s.schema) > 0 {
+			if qualifier = namedQualifier(dir); qualifier != "" {
+				file.Imports = append(file.Imports, namedImportPath)
+			}
+			break
+		}
+	}
+
+	// The MongoDB helpers return bson.D
+	for _, s := range structs {
+		if s.mongo != "" && !slices.Contains(file.Imports, s.mongo) {
+			file.Imports = append(file.Imports, s.mongo)
+		}
+	}
+
+	// The schemas compute the offsets and types of the members
+	for _, s := range structs {
+		if len(s.schema) > 0 {
+			for _, importPath := range []string{"reflect", "unsafe"} {
+				if !slices.Contains(file.Imports, importPath) {
+					file.Imports = append(file.Imports, importPath)
+				}
+			}
+			break
+		}
+	}
+
+	// The patches declare the field types
+	for _, s := range structs {
+		for _, importPath := range slices.Sorted(maps.Keys(s.imports)) {
+			if !slices.Contains(file.Imports, importPath) {
+				file.Imports = append(file.Imports, importPath)
+			}
+			if name := s.imports[importPath]; name != "" {
+				if file.Aliases == nil {
+					file.Aliases = make(map[string]string)
+				}
+				file.Aliases[importPath] = name
+			}
+		}
+	}
+
+	for _, s := range structs {
+		// Validate struct name to prevent panic
+		if len(s.name) == 0 {
+			return nil, fmt.Errorf("invalid struct name: empty string")
+		}
+
+		ts := templateStruct{
+			Name:    s.name,
+			TagKey:  s.tagKey,
+			Methods: s.output != outputConsts,
+			Consts:  s.output == outputConsts || s.output == outputBoth || s.fieldType,
+			Var:     s.name + s.varSuffix,
+			Mongo:   s.mongo != "",
+			Method:  s.method,
+
+			TagOptions: s.tagOptions,
+
+			Qualifier: qualifier,
+			Receiver:  s.name,
+		}
+		if len(s.typeParams) > 0 {
+			ts.Receiver += "[" + strings.Join(s.typeParams, ", ") + "]"
+		}
+
+		// Create private struct name (lowercase first letter), unless prefixed
+		typeName := s.typePrefix + s.name + s.varSuffix
+		if s.typePrefix == "" {
+			typeName = strings.ToLower(s.name[:1]) + s.name[1:] + s.varSuffix
+		}
+		result := "string"
+		if s.fieldType {
+			ts.FieldType = s.name + "Field"
+			result = ts.FieldType
+		}
+		if s.iface {
+			ts.Interface = s.name + "Namer"
+		}
+		fieldName := ""
+		if s.listing {
+			fieldName = s.name + "FieldName"
+		}
+		ts.Accessor = newTemplateAccessor(typeName, s.name, nil, s.fields, fieldName, result, s.rules)
+		if s.qualified {
+			for i := range ts.Accessor.Fields {
+				if field := &ts.Accessor.Fields[i]; field.Nested == nil {
+					field.Qualified = qualify(s.table, field.Name)
+					field.Aliased = qualify(s.alias, field.Name)
+				}
+			}
+		}
+
+		addConsts(&ts.Constants, s.name+"Name", s.fields)
+		if s.pointers {
+			addPointers(&ts.Pointers, s.name+"Pointer", s.fields)
+		}
+		if s.structs {
+			for _, field := range s.fields {
+				addStructFields(&ts.Structs, s.name+"Struct_"+field.name, field.children)
+			}
+		}
+		addNames(&ts.All, s.fields, "")
+		addOptions(&ts.Options, s.fields, "")
+		if s.fieldInfos {
+			ts.InfoType = qualifier + "FieldInfo"
+			addInfos(&ts.Infos, s.fields, "")
+		}
+		if s.patch {
+			for _, field := range s.fields {
+				ts.Patch = append(ts.Patch, newTemplatePatch(field, qualifier))
+			}
+		}
+		for _, field := range s.schema {
+			ts.Schema = append(ts.Schema, newTemplateSchema(s.name, field))
+		}
+
+		// nested structs are not columns
+		if ts.Table = s.table; ts.Table != "" {
+			for _, field := range s.fields {
+				if len(field.children) == 0 {
+					ts.Columns = append(ts.Columns, field.tagName)
+				}
+			}
+		}
+		file.Structs = append(file.Structs, ts)
+	}
+	return file, nil
+}
+
+// qualify returns name qualified by table (or alias), empty without table
+func qualify(table, name string) string {
+	if table == "" {
+		return ""
+	}
+	return table + "." + name
+}
+
+// newTemplateAccessor returns the accessor type typeName of fields: a method
+// per field returning its dotted path, or a member holding the accessors of
+// nested fields, listed by the Fields and All methods when fieldName (the type
+// of the fields listed by All) is set
+func newTemplateAccessor(typeName, selector string, path []string, fields []fieldInfo, fieldName, result, rules string) templateAccessor {
+	a := templateAccessor{
+		Type:      typeName,
+		Selector:  selector,
+		Name:      strings.Join(path, "."),
+		Path:      path,
+		Result:    result,
+		FieldName: fieldName,
+		Rules:     rules != "",
+	}
+	for _, field := range fields {
+		tf := templateField{GoName: field.name, Name: strings.Join(field.path, "."), Number: protobufNumber(field.tag)}
+		if rules != "" {
+			tf.Rules = reflect.StructTag(field.tag).Get(rules)
+		}
+		if len(field.children) > 0 {
+			nested := newTemplateAccessor(typeName+field.name, selector+"."+field.name, field.path, field.children, fieldName, result, rules)
+			tf.Nested = &nested
+			a.HasNested = true
+		}
+		a.Fields = append(a.Fields, tf)
+	}
+
+	// nested fields are listed by their leaves
+	var leaves []fieldInfo
+	var goNames []string
+	collectLeaves(fields, nil, &leaves, &goNames)
+	for i, field := range leaves {
+		a.Leaves = append(a.Leaves, templateName{GoName: goNames[i], Name: strings.Join(field.path, ".")})
+	}
+	return a
+}
+
+// collectLeaves appends the fields without children, recursively, along with
+// their Go names joined with the Go names of their parents
+func collectLeaves(fields []fieldInfo, parent []string, leaves *[]fieldInfo, goNames *[]string) {
+	for _, field := range fields {
+		goPath := append(parent[:len(parent):len(parent)], field.name)
+		if len(field.children) > 0 {
+			collectLeaves(field.children, goPath, leaves, goNames)
+			continue
+		}
+		*leaves = append(*leaves, field)
+		*goNames = append(*goNames, strings.Join(goPath, "."))
+	}
+}
+
+// addConsts appends a constant per field holding its dotted path, named after
+// the struct and the Go names of the path, e.g. OrderName_Customer_Email
+func addConsts(consts *[]templateConst, prefix string, fields []fieldInfo) {
+	for _, field := range fields {
+		name := prefix + "_" + field.name
+		*consts = append(*consts, templateConst{Name: name, Value: strings.Join(field.path, ".")})
+		addConsts(consts, name, field.children)
+	}
+}
+
+// pointerEscaper escapes the reference tokens of JSON Pointers (RFC 6901)
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// addPointers appends a constant per field holding its JSON Pointer, named
+// as by addConsts, e.g. OrderPointer_Customer_Email = "/customer/email"
+func addPointers(consts *[]templateConst, prefix string, fields []fieldInfo) {
+	for _, field := range fields {
+		name := prefix + "_" + field.name
+		var pointer strings.Builder
+		for _, token := range field.path {
+			pointer.WriteString("/" + pointerEscaper.Replace(token))
+		}
+		*consts = append(*consts, templateConst{Name: name, Value: pointer.String()})
+		addPointers(consts, name, field.children)
+	}
+}
+
+// addStructFields appends a constant per nested field holding its name in the
+// STRUCT of its parent (e.g. a Cloud Spanner STRUCT), named as by addConsts,
+// e.g. OrderStruct_Customer_Email = "email"
+func addStructFields(consts *[]templateConst, prefix string, fields []fieldInfo) {
+	for _, field := range fields {
+		name := prefix + "_" + field.name
+		*consts = append(*consts, templateConst{Name: name, Value: field.path[len(field.path)-1]})
+		addStructFields(consts, name, field.children)
+	}
+}
+
+// addNames appends the Go names of the fields (joined with their parents'
+// ones, e.g. "Customer.Email") and their dotted paths
+func addNames(names *[]templateName, fields []fieldInfo, parent string) {
+	for _, field := range fields {
+		goName := parent + field.name
+		*names = append(*names, templateName{GoName: goName, Name: strings.Join(field.path, ".")})
+		addNames(names, field.children, goName+".")
+	}
+}
+
+// addInfos appends the descriptors of the fields, in the order of addNames
+func addInfos(infos *[]templateInfo, fields []fieldInfo, parent string) {
+	for _, field := range fields {
+		goName := parent + field.name
+		*infos = append(*infos, templateInfo{
+			GoName:  goName,
+			Name:    strings.Join(field.path, "."),
+			Type:    field.goType,
+			Pointer: field.pointer,
+			Slice:   field.slice,
+		})
+		addInfos(infos, field.children, goName+".")
+	}
+}
+
+// addOptions appends the tag options of the fields having some, recursively
+func addOptions(options *[]templateOptions, fields []fieldInfo, parent string) {
+	for _, field := range fields {
+		goName := parent + field.name
+		if len(field.options) > 0 {
+			*options = append(*options, templateOptions{GoName: goName, Options: field.options})
+		}
+		addOptions(options, field.children, goName+".")
+	}
+}
+
+// executeTemplate renders structs with the template of the file path (the
+// default one if empty)
+func executeTemplate(path, dir string, structs []structInfo) ([]byte, error) {
+	tmpl, err := loadTemplate(path)
+	if err != nil {
+		return nil, fmt.Errorf("template: %v", err)
+	}
+	data, err := newTemplateFile(dir, structs)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,11 @@
+package users
+
+// GENERATE-NAMED TagKey:json
+type User struct {
+	Email string `json:"email_address"`
+	Name  string `json:"name"`
+}
+
+type Untagged struct {
+	ID int
+}
@@ -0,0 +1,545 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// processTyped generates the accessors of the packages matching patterns
+// (e.g. "./..."), loaded and type checked with go/packages. Unlike the
+// default mode, it sees through type aliases and named types declared in other
+// files or packages.
+func processTyped(patterns []string) error {
+	for _, pattern := range patterns {
+		cfg := &packages.Config{
+			Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+				packages.NeedTypes | packages.NeedTypesInfo | packages.NeedModule,
+			Tests: tests,
+		}
+
+		// directory patterns are loaded from their directory, so they may
+		// belong to another module than the working directory
+		if isDirPattern(pattern) {
+			root, recursive := strings.CutSuffix(pattern, "/...")
+			cfg.Dir, pattern = root, "."
+			if recursive {
+				pattern = "./..."
+
+				// a workspace root, as its modules
+				modules, err := workspaceModules(root)
+				if err != nil {
+					return err
+				}
+				for _, module := range modules {
+					moduleCfg := *cfg
+					moduleCfg.Dir = module
+					if err := loadTyped(&moduleCfg, pattern); err != nil {
+						return err
+					}
+				}
+				if modules != nil {
+					continue
+				}
+
+				// the nested modules, skipped by go/packages
+				if allModules {
+					nested, err := nestedModules(root)
+					if err != nil {
+						return err
+					}
+					for _, module := range nested {
+						moduleCfg := *cfg
+						moduleCfg.Dir = module
+						if err := loadTyped(&moduleCfg, pattern); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+
+		if err := loadTyped(cfg, pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTyped loads the packages of pattern with cfg and processes them
+func loadTyped(cfg *packages.Config, pattern string) error {
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(pkgs, func(a, b *packages.Package) int { return strings.Compare(a.ID, b.ID) })
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			if err := keepGoingErr(pkg.PkgPath, pkg.Errors[0]); err != nil {
+				return fmt.Errorf("error loading %s: %v", pkg.PkgPath, err)
+			}
+			continue
+		}
+		if err := keepGoingErr(pkg.PkgPath, processTypedPackage(pkg)); err != nil {
+			return fmt.Errorf("error processing %s: %v", pkg.PkgPath, err)
+		}
+	}
+	return nil
+}
+
+// isDirPattern reports whether the package pattern is a directory, or
+// directory tree (e.g. "./..."), rather than an import path
+func isDirPattern(pattern string) bool {
+	return pattern == "." || pattern == ".." || filepath.IsAbs(pattern) ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../")
+}
+
+func processTypedPackage(pkg *packages.Package) error {
+	// with -tests, the test files are processed in the test variants of the
+	// packages (e.g. "x [x.test]"), the other files in the packages themselves
+	testVariant := strings.Contains(pkg.ID, " [")
+	if tests && !testVariant && strings.HasSuffix(pkg.ID, ".test") {
+		return nil // generated test main
+	}
+
+	logger.Debug("Processing package: "+pkg.ID, "action", "process", "package", pkg.ID)
+
+	// Collect the directives of the package, as processDir does
+	globalDirectives := make(directives)
+	var files []*ast.File
+	for _, file := range pkg.Syntax {
+		filename := pkg.Fset.Position(file.Package).Filename
+		if isGeneratedFile(filename) || excludedPath(filename) {
+			continue
+		}
+
+		fileDirectives := parseGenerateComments(file)
+		for _, structName := range slices.Sorted(maps.Keys(fileDirectives)) {
+			dir := fileDirectives[structName]
+			if existing, exists := globalDirectives[structName]; exists && existing != dir {
+				return fmt.Errorf("conflicting GENERATE-NAMED directives for struct %s: %+v vs %+v",
+					structName, existing, dir)
+			}
+			globalDirectives[structName] = dir
+		}
+
+		if testVariant == strings.HasSuffix(filename, testFileSuffix) {
+			files = append(files, file)
+			reportScanned(filename)
+		}
+	}
+
+	// the configuration file covers the structs without comment directive
+	if len(files) > 0 {
+		dir := filepath.Dir(pkg.Fset.Position(files[0].Package).Filename)
+		if err := addConfigDirectives(globalDirectives, dir, pkg.Types.Scope().Names()); err != nil {
+			return err
+		}
+	}
+
+	c := typedCollector{pkg: pkg}
+	var generated []fileStructs
+	for _, file := range files {
+		filename := pkg.Fset.Position(file.Package).Filename
+
+		directives, err := mergeDocDirectives(globalDirectives, file)
+		if err != nil {
+			if err := keepGoingErr(filename, err); err != nil {
+				return fmt.Errorf("error processing %s: %v", filename, err)
+			}
+			continue
+		}
+		if len(directives) == 0 {
+			continue
+		}
+
+		constraint := buildConstraint(filename, file)
+
+		var structs []structInfo
+		var structErrs []error
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				dir, found := directives.lookup(typeSpec.Name.Name)
+				if !found {
+					continue
+				}
+
+				// the declared type may be an alias, or a named type defined
+				// elsewhere, as long as it is a struct
+				obj := pkg.TypesInfo.Defs[typeSpec.Name]
+				if obj == nil {
+					continue
+				}
+				structType, ok := obj.Type().Underlying().(*types.Struct)
+				if !ok {
+					continue
+				}
+
+				out, outDir, outFile, err := structOutput(typeSpec.Name.Name, dir)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				if err := checkGeneric(typeSpec, dir); err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				varSuffix, typePrefix, err := structAffixes(typeSpec.Name.Name, dir)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				n, err := dir.naming(typeSpec.Name.Name, outDir)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				mongoImport, err := structMongo(typeSpec.Name.Name, dir, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				fieldType, err := boolOption(typeSpec.Name.Name, fieldTypeKey, dir.fieldType)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				iface, err := methodsOption(typeSpec.Name.Name, interfaceKey, dir.iface, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				method, err := methodsOption(typeSpec.Name.Name, methodKey, dir.method, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				fieldInfos, err := methodsOption(typeSpec.Name.Name, fieldInfosKey, dir.fieldInfos, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				listing, err := methodsOption(typeSpec.Name.Name, listingKey, dir.listing, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				tagOptions, err := methodsOption(typeSpec.Name.Name, tagOptionsKey, dir.tagOptions, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				patch, err := methodsOption(typeSpec.Name.Name, patchKey, dir.patch, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				rules, err := structRules(typeSpec.Name.Name, dir, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				qualified, err := structQualified(typeSpec.Name.Name, dir, out)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+
+				visiting := map[types.Type]bool{types.Unalias(obj.Type()): true}
+				candidates, err := c.collectCandidates(typeSpec.Name.Name, structType, n, nil, visiting, 0)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				fields, err := resolveCandidates(c.pkg.Fset, typeSpec.Name.Name, candidates)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				if err := checkReservedNames(typeSpec.Name.Name, out, rules, listing, tagOptions, fields); err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				if err := checkNamedMethod(typeSpec, method, outDir, fields); err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				if err := checkPatch(typeSpec.Name.Name, patch, outDir, fields); err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				if err := checkRenames(typeSpec.Name.Name, n, patch, fields); err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				if qualified {
+					if err := checkQualifiedNames(typeSpec.Name.Name, dir.table, dir.alias, fields); err != nil {
+						structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+						continue
+					}
+				}
+				var imports map[string]string
+				if patch {
+					imports = make(map[string]string)
+					for _, field := range fields {
+						for _, importPath := range field.imports {
+							imports[importPath] = ""
+						}
+					}
+				}
+				schema, err := optionSchema(typeSpec.Name.Name, dir, out, outDir, typeMembers(structType))
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				pointers, err := boolOption(typeSpec.Name.Name, pointersKey, dir.pointers)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+				structFields, err := boolOption(typeSpec.Name.Name, structFieldsKey, dir.structFields)
+				if err != nil {
+					structErrs = append(structErrs, &structError{typeSpec.Name.Name, err})
+					continue
+				}
+
+				if len(fields) > 0 {
+					structs = append(structs, structInfo{
+						name:       typeSpec.Name.Name,
+						tagKey:     dir.tagKey,
+						fields:     fields,
+						pkgName:    pkg.Name,
+						output:     out,
+						constraint: constraint,
+						outDir:     outDir,
+						outFile:    outFile,
+						directive:  directives.key(typeSpec.Name.Name),
+						template:   dir.template,
+						varSuffix:  varSuffix,
+						typePrefix: typePrefix,
+						table:      dir.table,
+						alias:      dir.alias,
+						qualified:  qualified,
+						mongo:      mongoImport,
+						fieldType:  fieldType,
+						iface:      iface,
+						method:     method,
+						fieldInfos: fieldInfos,
+						listing:    listing,
+						tagOptions: tagOptions,
+						patch:      patch,
+						imports:    imports,
+						schema:     schema,
+						pointers:   pointers,
+						structs:    structFields,
+						rules:      rules,
+						typeParams: typeParamNames(typeSpec),
+					})
+				}
+			}
+		}
+
+		// as findAnnotatedStructs, the structs without errors with -keep-going
+		if err := keepGoingErr(filename, errors.Join(structErrs...)); err != nil {
+			return fmt.Errorf("error processing %s: %v", filename, err)
+		}
+
+		if len(structs) > 0 {
+			logVerbose("Found %d struct(s) in %s", len(structs), filepath.Base(filename))
+			generated = append(generated, fileStructs{filename, structs})
+		}
+	}
+
+	if len(generated) == 0 {
+		return nil
+	}
+	return generatePackage(filepath.Dir(generated[0].path), generated)
+}
+
+// typedCollector collects the fields of the structs of pkg from their types
+type typedCollector struct {
+	pkg *packages.Package
+}
+
+// collectCandidates is the typed counterpart of collectCandidates: embedded
+// structs are flattened whatever their package, and fields whose type is a
+// struct of the module get nested accessors.
+func (c typedCollector) collectCandidates(structName string, structType *types.Struct, n naming, parent []string,
+	visiting map[types.Type]bool, depth int) ([]candidate, error) {
+
+	var candidates []candidate
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		tagValue, options := n.parseTag(structType.Tag(i))
+		override := tagName(structType.Tag(i), namedTagKey)
+
+		// Skip fields with tag:"-"
+		if n.skips(tagValue, override, options) {
+			continue
+		}
+
+		if field.Embedded() && n.flattens(tagValue, override, options) {
+			// Untagged (or squashed) embedded structs are flattened
+			t := derefType(field.Type())
+			if embedded, ok := t.Underlying().(*types.Struct); ok {
+				if visiting[t] {
+					continue
+				}
+				visiting[t] = true
+				promoted, err := c.collectCandidates(typeName(t), embedded, n, parent, visiting, depth+1)
+				delete(visiting, t)
+				if err != nil {
+					return nil, err
+				}
+				candidates = append(candidates, promoted...)
+				continue
+			}
+		}
+
+		// Skip unexported fields (unless IncludeUnexported), embedded ones are
+		// named after their type
+		fieldName := field.Name()
+		if !ast.IsExported(fieldName) && !n.unexported {
+			continue
+		}
+
+		tagged := tagValue != "" || override != ""
+
+		// Use field name if no tag specified, in the fallback case
+		if tagValue == "" {
+			tagValue = n.fallbackName(fieldName)
+		}
+
+		// The named tag overrides the name, as in the runtime linker, and
+		// the Rename option both
+		if rename := n.rename(fieldName, parent); rename != "" {
+			tagged, override = true, rename
+		}
+		if override != "" {
+			tagValue = override
+		}
+
+		info := fieldInfo{
+			name:    fieldName,
+			tagName: tagValue,
+			path:    append(append([]string(nil), parent...), tagValue),
+			options: options,
+			tag:     structType.Tag(i),
+			imports: make(map[string]string),
+		}
+		info.pointer, info.slice = typeKind(field.Type())
+		info.goType, info.elemType, info.comparable = typeTraits(field.Type(), c.pkg.Types, info.imports)
+
+		// Nested struct of the module
+		if t, nested, ok := c.nestedStruct(field.Type()); ok && !visiting[t] {
+			visiting[t] = true
+			nestedCandidates, err := c.collectCandidates(typeName(t), nested, n, info.path, visiting, 0)
+			delete(visiting, t)
+			if err != nil {
+				return nil, err
+			}
+			children, err := resolveCandidates(c.pkg.Fset, typeName(t), nestedCandidates)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkNestedNames(structName, fieldName, children); err != nil {
+				return nil, err
+			}
+			info.children = children
+		}
+
+		candidates = append(candidates, candidate{info: info, owner: structName, depth: depth, tagged: tagged, pos: field.Pos()})
+	}
+
+	return candidates, nil
+}
+
+// nestedStruct returns the named struct type of a field of type t (possibly a
+// pointer, alias or named.Field), if declared in the module of the package.
+// Structs of other modules (e.g. time.Time) are not nested.
+func (c typedCollector) nestedStruct(t types.Type) (types.Type, *types.Struct, bool) {
+	t = derefType(t)
+
+	// see through named.Field
+	if named, ok := t.(*types.Named); ok && named.TypeArgs().Len() > 0 && named.Obj().Name() == "Field" {
+		t = derefType(named.TypeArgs().At(0))
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil, nil, false
+	}
+	structType, ok := named.Underlying().(*types.Struct)
+	if !ok || !c.inModule(named.Obj().Pkg().Path()) {
+		return nil, nil, false
+	}
+	return named, structType, true
+}
+
+// inModule reports whether the package pkgPath belongs to the module of the
+// processed package, or is the package itself when not in a module
+func (c typedCollector) inModule(pkgPath string) bool {
+	if c.pkg.Module == nil {
+		return pkgPath == c.pkg.PkgPath
+	}
+	return pkgPath == c.pkg.Module.Path || strings.HasPrefix(pkgPath, c.pkg.Module.Path+"/")
+}
+
+// typeKind is the typed counterpart of exprKind: aliases are resolved, named
+// slice types are not
+func typeKind(t types.Type) (pointer, slice bool) {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		pointer, t = true, ptr.Elem()
+	}
+	_, slice = types.Unalias(t).(*types.Slice)
+	return pointer, slice
+}
+
+// derefType removes the aliases and pointer of t
+func derefType(t types.Type) types.Type {
+	t = types.Unalias(t)
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = types.Unalias(ptr.Elem())
+	}
+	return t
+}
+
+// typeName returns the name of t, used in error messages
+func typeName(t types.Type) string {
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return t.String()
+}
+
+// excludedPath reports whether filename, or one of its directories relative
+// to the working directory, is excluded (see excluded) or pruned
+func excludedPath(filename string) bool {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, filename); err == nil && !strings.HasPrefix(rel, "..") {
+			filename = rel
+		}
+	}
+	for path := filename; path != "." && path != string(filepath.Separator); path = filepath.Dir(path) {
+		if excluded(path) || path != filename && pruned[filepath.Base(path)] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package gen
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// typeScriptFile is the TypeScript file of the names of the annotated
+// structs of a run, with -ts
+var typeScriptFile string
+
+// typeScriptStruct is an annotated struct of the TypeScript file, from its
+// source file
+type typeScriptStruct struct {
+	path string
+	s    structInfo
+}
+
+var (
+	typeScriptStructs   []typeScriptStruct
+	typeScriptStructsMu sync.Mutex
+)
+
+// jsIdentifier matches the object keys that don't need quotes
+var jsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// addTypeScriptStruct records the struct s of the source file path for the
+// TypeScript file, unless declared by a test file
+func addTypeScriptStruct(path string, s structInfo) {
+	if typeScriptFile == "" || strings.HasSuffix(path, testFileSuffix) {
+		return
+	}
+	typeScriptStructsMu.Lock()
+	typeScriptStructs = append(typeScriptStructs, typeScriptStruct{path, s})
+	typeScriptStructsMu.Unlock()
+}
+
+// writeTypeScript writes (or checks) the TypeScript file of the recorded
+// structs, once the packages are processed
+func writeTypeScript() error {
+	content, err := renderTypeScript(typeScriptStructs)
+	if err != nil {
+		return err
+	}
+	return writeGenerated(typeScriptFile, content)
+}
+
+// renderTypeScript returns the TypeScript definitions of structs: a constant
+// object per struct (e.g. UserFields), sorted by struct name, holding the
+// names of its fields by name, or the object of the fields of a nested struct
+func renderTypeScript(structs []typeScriptStruct) ([]byte, error) {
+	structs = slices.Clone(structs)
+	slices.SortFunc(structs, func(a, b typeScriptStruct) int {
+		return cmp.Or(strings.Compare(a.s.name, b.s.name), strings.Compare(a.path, b.path))
+	})
+
+	var b strings.Builder
+	b.WriteString(generatedHeader + "\n")
+	for i, ts := range structs {
+		if i > 0 && structs[i-1].s.name == ts.s.name {
+			return nil, fmt.Errorf("structs %s of %s and %s would both declare %sFields", ts.s.name, structs[i-1].path, ts.path, ts.s.name)
+		}
+		fmt.Fprintf(&b, "\n// names of the fields of %s\nexport const %sFields = {\n", ts.s.name, ts.s.name)
+		writeTypeScriptFields(&b, ts.s.fields, "  ")
+		b.WriteString("} as const;\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// writeTypeScriptFields writes the object members of fields to b, indented
+func writeTypeScriptFields(b *strings.Builder, fields []fieldInfo, indent string) {
+	for _, field := range fields {
+		key := field.tagName
+		if !jsIdentifier.MatchString(key) {
+			key = typeScriptString(key)
+		}
+		if len(field.children) > 0 {
+			fmt.Fprintf(b, "%s%s: {\n", indent, key)
+			writeTypeScriptFields(b, field.children, indent+"  ")
+			fmt.Fprintf(b, "%s},\n", indent)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s: %s,\n", indent, key, typeScriptString(strings.Join(field.path, ".")))
+	}
+}
+
+// typeScriptString returns the string literal of s
+func typeScriptString(s string) string {
+	data, _ := json.Marshal(s) // can't fail for a string
+	return string(data)
+}
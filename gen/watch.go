@@ -0,0 +1,179 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounceDelay is the time without changes waited before regenerating, so
+// editors saving several files (or writing them in several steps) trigger a
+// single generation
+const debounceDelay = 200 * time.Millisecond
+
+// watchStop ends watchPaths when closed, watching until the watcher fails
+// otherwise (nil)
+var watchStop chan struct{}
+
+// watchPaths regenerates the packages of paths when their Go files change,
+// until the watcher fails. Errors are reported per package, without stopping.
+func watchPaths(paths []string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// single files are watched through their directory
+	files := make(map[string]bool)
+	visited := make(map[string]bool) // with -follow-symlinks
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files[filepath.Clean(path)] = true
+			if err := watcher.Add(filepath.Dir(path)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := watchDirs(watcher, path, visited); err != nil {
+			return err
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Watching %s for changes...", strings.Join(paths, ", ")), "action", "watch")
+
+	pending := make(map[string]bool) // package directories or files to regenerate
+	timer := time.NewTimer(debounceDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// watch the directories created in watched trees
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Lstat(event.Name); err == nil && info.IsDir() && !files[event.Name] {
+					if err := watchDirs(watcher, event.Name, visited); err != nil {
+						logError("Error watching", event.Name, err)
+					}
+					continue
+				}
+			}
+
+			name := event.Name
+			if !isSourceFile(name) || excluded(name) || event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+
+			dir := filepath.Dir(name)
+			if files[filepath.Clean(name)] {
+				pending[filepath.Clean(name)] = true
+			} else if !watchesFilesOnly(files, dir, paths) {
+				pending[dir] = true
+			} else {
+				continue
+			}
+			logVerbose("Changed: %s", name)
+			timer.Reset(debounceDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+
+		case <-watchStop:
+			return nil
+
+		case <-timer.C:
+			for path := range pending {
+				if err := regenerate(path, files[path]); err != nil {
+					logError("Error processing", path, err)
+				}
+				delete(pending, path)
+			}
+		}
+	}
+}
+
+// watchDirs adds root and its subdirectories to watcher, skipping hidden
+// directories, symlinks (unless -follow-symlinks, each directory once by
+// visited) and nested modules as walkGoPackages does
+func watchDirs(watcher *fsnotify.Watcher, root string, visited map[string]bool) error {
+	boundary := !allModules && moduleRoot(root) != ""
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		symlink := d.Type()&os.ModeSymlink != 0 && followSymlinks && path != root
+		if symlink {
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				return nil
+			}
+		} else if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || pruned[d.Name()] || excluded(path) || boundary && isModuleRoot(path)) {
+			if symlink {
+				return nil // SkipDir would skip the rest of its directory
+			}
+			return filepath.SkipDir
+		}
+		if symlink {
+			return watchDirs(watcher, path, visited) // not walked by filepath.WalkDir
+		}
+		if followSymlinks {
+			first, err := firstVisit(visited, path)
+			if err != nil {
+				return err
+			}
+			if !first {
+				return filepath.SkipDir
+			}
+		}
+		logVerbose("Watching directory: %s", path)
+		return watcher.Add(path)
+	})
+}
+
+// watchesFilesOnly reports whether dir is only watched for single files
+// given as paths, not as a directory itself
+func watchesFilesOnly(files map[string]bool, dir string, paths []string) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, path := range paths {
+		if files[filepath.Clean(path)] {
+			continue
+		}
+		if rel, err := filepath.Rel(path, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return false
+		}
+	}
+	return true
+}
+
+// regenerate processes a changed package directory, or single file
+func regenerate(path string, file bool) error {
+	if file {
+		return processFile(path, nil)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil // removed
+	}
+	if typed {
+		return processTyped([]string{path})
+	}
+	return processDir(path)
+}
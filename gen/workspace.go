@@ -0,0 +1,72 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// workspaceModules returns the module directories of the go.work file of
+// dir, nil if dir isn't a workspace root (or GOWORK=off). The modules are
+// processed in place of dir, each one up to its boundary (see isModuleRoot).
+func workspaceModules(dir string) ([]string, error) {
+	if os.Getenv("GOWORK") == "off" {
+		return nil, nil
+	}
+	workFile := filepath.Join(dir, "go.work")
+	data, err := os.ReadFile(workFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	work, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(work.Use) == 0 {
+		return nil, fmt.Errorf("%s: no modules to use", workFile)
+	}
+	modules := make([]string, 0, len(work.Use))
+	for _, use := range work.Use {
+		module := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(module) {
+			module = filepath.Join(dir, module)
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// isModuleRoot reports whether dir has a go.mod file: the root of a module,
+// another one than the module of its parent directory
+func isModuleRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// nestedModules returns the directories of the modules nested in root, at
+// any depth, skipping the directories as walkGoPackages does
+func nestedModules(root string) ([]string, error) {
+	var modules []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || pruned[d.Name()] || excluded(path) {
+			return filepath.SkipDir
+		}
+		if isModuleRoot(path) {
+			modules = append(modules, path)
+		}
+		return nil
+	})
+	return modules, err
+}
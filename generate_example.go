@@ -8,9 +8,9 @@ package named
 // (these directives can be in any file)
 //
 // GENERATE-NAMED=StructName:TestStruct,TagKey:json
-// GENERATE-NAMED=StructName:Person,TagKey:json
-// GENERATE-NAMED=StructName:User,TagKey:db
-// GENERATE-NAMED=StructName:Product,TagKey:json
+// GENERATE-NAMED=StructName:Person,TagKey:json,Interface:true,Patch:true,Listing:true
+// GENERATE-NAMED=StructName:User,TagKey:db,Table:users
+// GENERATE-NAMED=StructName:Product,TagKey:json,Output:both,TagOptions:true
 
 // Struct definitions
 
@@ -36,6 +36,160 @@ type User struct {
 type Product struct {
 	SKU         string  `json:"sku"`
 	Name        string  `json:"product_name"`
-	Price       float64 `json:"price"`
+	Price       float64 `json:"price,omitempty"`
 	Description string  // no tag, should use field name
 }
+
+// nested structs of the package get nested accessors, typed as OrderField and
+// described with their Go types by OrderFieldInfos, with JSON Pointer constants
+
+// GENERATE-NAMED TagKey:json,FieldType:true,FieldInfos:true,Pointers:true,Listing:true
+type Order struct {
+	ID       int       `json:"id"`
+	Customer *Customer `json:"customer"`
+}
+
+type Customer struct {
+	Email string `json:"email"`
+}
+
+// embedded structs are flattened, as encoding/json does, the accessor is also
+// returned by invoice.Named()
+
+// GENERATE-NAMED TagKey:json,NamedMethod:true
+type Invoice struct {
+	Timestamps
+	Number string `json:"number"`
+}
+
+type Timestamps struct {
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// untagged fields can be named in another case than their Go name
+
+// GENERATE-NAMED TagKey:db,Fallback:snake,Listing:true
+type Account struct {
+	AccountID   int
+	DisplayName string
+	Email       string `db:"email_address"`
+}
+
+// structs of Field members can have their schema registered at init, in place
+// of LoadLink, without reflection
+
+// GENERATE-NAMED TagKey:json,Schema:true
+type Contact struct {
+	Phone   Field[string]  `json:"phone"`
+	Address Field[Address] `json:"address"`
+}
+
+type Address struct {
+	City Field[string] `json:"city" named:"town"`
+}
+
+// generic structs get the same accessors, the names of the fields not
+// depending on the type arguments
+
+// GENERATE-NAMED TagKey:json,NamedMethod:true,Listing:true
+type Pair[K comparable, V ~string | ~int] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// fields tagged for several formats are named after the first tag key of the
+// chain naming them
+
+// GENERATE-NAMED TagKey:json|yaml|field,Fallback:snake,Listing:true
+type Settings struct {
+	Host     string `json:"host" yaml:"hostname"`
+	Port     int    `yaml:"port"`
+	LogLevel string
+}
+
+// mapstructure (Viper) nests the embedded structs under their type name
+// unless squashed, and leaves out the field collecting the other keys
+
+// GENERATE-NAMED TagKey:mapstructure,Listing:true
+type ServerConfig struct {
+	ServerListener `mapstructure:",squash"`
+	ServerLimits
+	Extra map[string]any `mapstructure:",remain"`
+}
+
+type ServerListener struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type ServerLimits struct {
+	MaxConns int `mapstructure:"max_conns"`
+}
+
+// structs of protoc-gen-go, or tagged alike, get the field numbers too
+
+// GENERATE-NAMED TagKey:protobuf
+type Ticket struct {
+	ID    int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Owner *Owner `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+}
+
+type Owner struct {
+	Email string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+}
+
+// the validation rules of the fields can be generated along with their names,
+// e.g. for error messages
+
+// GENERATE-NAMED TagKey:json,Rules:true
+type SignUp struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=12"`
+	Referrer string `json:"referrer,omitempty"`
+}
+
+// the unexported fields can be named too, e.g. to build the queries of the
+// package, their accessors unexported as well
+
+// GENERATE-NAMED TagKey:db,IncludeUnexported:true,Listing:true
+type Session struct {
+	ID        string `db:"id"`
+	userID    string `db:"user_id"`
+	expiresAt int64  `db:"expires_at"`
+}
+
+// single fields can be named apart from their tag, e.g. the column of a field
+// whose only tag is the JSON one
+
+// GENERATE-NAMED TagKey:json,Rename:Password=pwd_hash
+type Credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// the names qualified by the table, or by its alias, tell apart the columns of
+// the joined tables
+
+// GENERATE-NAMED TagKey:db,Table:payments,Alias:p,Qualified:true
+type Payment struct {
+	ID     int    `db:"id"`
+	UserID int    `db:"user_id"`
+	Status string `db:"status"`
+}
+
+// the names of the nested fields in the STRUCT of their parent build the
+// queries reading STRUCT values, e.g. the @address.City query parameter of
+// Cloud Spanner (see spannerutil)
+
+// GENERATE-NAMED TagKey:spanner,StructFields:true
+type Singer struct {
+	SingerID int           `spanner:"SingerId"`
+	Address  SingerAddress `spanner:"Address"`
+}
+
+type SingerAddress struct {
+	City string `spanner:"City"`
+	Zip  string `spanner:"ZipCode"`
+}
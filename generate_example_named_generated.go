@@ -1,7 +1,13 @@
 // Code generated by generate-named. DO NOT EDIT.
+// Content hash: sha256:e162f407dafd692aba9b897b7a1d60c12d626cd8767dc56ab184e7e3bc831f07
 
 package named
 
+import (
+	"reflect"
+	"unsafe"
+)
+
 // testStructNamed provides methods to access field names of TestStruct
 type testStructNamed struct{}
 
@@ -11,6 +17,31 @@ func (testStructNamed) Field2() string { return "field2" }
 // TestStructNamed is the exported variable for accessing TestStruct field names
 var TestStructNamed testStructNamed
 
+// TestStructNamedMap maps the Go names of the fields of TestStruct to their names
+var TestStructNamedMap = map[string]string{
+	"Field1": "field1",
+	"Field2": "field2",
+}
+
+// TestStructNamedReverseMap maps the names of the fields of TestStruct to their Go names
+var TestStructNamedReverseMap = map[string]string{
+	"field1": "Field1",
+	"field2": "Field2",
+}
+
+// ResolveTestStructField returns the Go name of the field of TestStruct named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveTestStructField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = TestStructNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// PersonFieldName is a field listed by the All methods of the accessors of Person
+type PersonFieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
 // personNamed provides methods to access field names of Person
 type personNamed struct{}
 
@@ -18,9 +49,113 @@ func (personNamed) Name() string  { return "name" }
 func (personNamed) Age() string   { return "age" }
 func (personNamed) Email() string { return "email" }
 
+// Fields returns the names of the fields, in declaration order
+func (personNamed) Fields() []string {
+	return []string{
+		"name",
+		"age",
+		"email",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (personNamed) All() []PersonFieldName {
+	return []PersonFieldName{
+		{GoName: "Name", Name: "name"},
+		{GoName: "Age", Name: "age"},
+		{GoName: "Email", Name: "email"},
+	}
+}
+
 // PersonNamed is the exported variable for accessing Person field names
 var PersonNamed personNamed
 
+// PersonNamer is implemented by personNamed, so consumers can accept, or mock, the
+// accessor of Person
+type PersonNamer interface {
+	Name() string
+	Age() string
+	Email() string
+	Fields() []string
+	All() []PersonFieldName
+}
+
+var _ PersonNamer = PersonNamed
+
+// PersonNamedMap maps the Go names of the fields of Person to their names
+var PersonNamedMap = map[string]string{
+	"Name":  "name",
+	"Age":   "age",
+	"Email": "email",
+}
+
+// PersonNamedReverseMap maps the names of the fields of Person to their Go names
+var PersonNamedReverseMap = map[string]string{
+	"name":  "Name",
+	"age":   "Age",
+	"email": "Email",
+}
+
+// ResolvePersonField returns the Go name of the field of Person named tag, e.g. to
+// translate wire names back to Go fields
+func ResolvePersonField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = PersonNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// PersonPatch is the patch of Person: its fields wrapped by Field, with the
+// same tags, e.g. decoded by DecodePatch
+type PersonPatch struct {
+	Name  Field[string] `json:"name"`
+	Age   Field[int]    `json:"age"`
+	Email Field[string] `json:"email"`
+}
+
+// PersonToPatch returns the patch setting every field of s
+func PersonToPatch(s Person) PersonPatch {
+	var p PersonPatch
+	p.Name.Value = s.Name
+	p.Age.Value = s.Age
+	p.Email.Value = s.Email
+	return p
+}
+
+// ApplyPersonPatch sets the fields of s present in p, as recorded by Presence
+// (decoding p with DecodePatch or Decoder), or else having a value
+func ApplyPersonPatch(s *Person, p *PersonPatch) {
+	present := Presence(p)
+	if present != nil && present.Has("name") || present == nil && !p.Name.NoValue() {
+		s.Name = p.Name.Value
+	}
+	if present != nil && present.Has("age") || present == nil && !p.Age.NoValue() {
+		s.Age = p.Age.Value
+	}
+	if present != nil && present.Has("email") || present == nil && !p.Email.NoValue() {
+		s.Email = p.Email.Value
+	}
+}
+
+// UserTable returns the SQL table of User
+func UserTable() string { return "users" }
+
+// UserColumns returns the columns of User, in declaration order
+func UserColumns() []string {
+	return []string{
+		"user_id",
+		"username",
+		"is_active",
+	}
+}
+
+// UserQualifiedColumns returns the columns of User qualified by its table
+func UserQualifiedColumns() []string {
+	return []string{
+		"users.user_id",
+		"users.username",
+		"users.is_active",
+	}
+}
+
 // userNamed provides methods to access field names of User
 type userNamed struct{}
 
@@ -31,6 +166,35 @@ func (userNamed) Active() string   { return "is_active" }
 // UserNamed is the exported variable for accessing User field names
 var UserNamed userNamed
 
+// UserNamedMap maps the Go names of the fields of User to their names
+var UserNamedMap = map[string]string{
+	"ID":       "user_id",
+	"Username": "username",
+	"Active":   "is_active",
+}
+
+// UserNamedReverseMap maps the names of the fields of User to their Go names
+var UserNamedReverseMap = map[string]string{
+	"user_id":   "ID",
+	"username":  "Username",
+	"is_active": "Active",
+}
+
+// ResolveUserField returns the Go name of the field of User named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveUserField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = UserNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// Field names of Product
+const (
+	ProductName_SKU         = "sku"
+	ProductName_Name        = "product_name"
+	ProductName_Price       = "price"
+	ProductName_Description = "Description"
+)
+
 // productNamed provides methods to access field names of Product
 type productNamed struct{}
 
@@ -39,5 +203,776 @@ func (productNamed) Name() string        { return "product_name" }
 func (productNamed) Price() string       { return "price" }
 func (productNamed) Description() string { return "Description" }
 
+// Options returns the options of the tag of the field named field (its Go
+// name, e.g. "Customer.Email"), such as omitempty
+func (productNamed) Options(field string) []string {
+	switch field {
+	case "Price":
+		return []string{"omitempty"}
+	}
+	return nil
+}
+
 // ProductNamed is the exported variable for accessing Product field names
 var ProductNamed productNamed
+
+// ProductNamedMap maps the Go names of the fields of Product to their names
+var ProductNamedMap = map[string]string{
+	"SKU":         "sku",
+	"Name":        "product_name",
+	"Price":       "price",
+	"Description": "Description",
+}
+
+// ProductNamedReverseMap maps the names of the fields of Product to their Go names
+var ProductNamedReverseMap = map[string]string{
+	"sku":          "SKU",
+	"product_name": "Name",
+	"price":        "Price",
+	"Description":  "Description",
+}
+
+// ResolveProductField returns the Go name of the field of Product named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveProductField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = ProductNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// OrderField is the name of a field of Order
+type OrderField string
+
+// Field names of Order
+const (
+	OrderName_ID             OrderField = "id"
+	OrderName_Customer       OrderField = "customer"
+	OrderName_Customer_Email OrderField = "customer.email"
+)
+
+// JSON Pointers of the fields of Order
+const (
+	OrderPointer_ID             = "/id"
+	OrderPointer_Customer       = "/customer"
+	OrderPointer_Customer_Email = "/customer/email"
+)
+
+// OrderFieldName is a field listed by the All methods of the accessors of Order
+type OrderFieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
+// orderNamed provides methods to access field names of Order
+type orderNamed struct {
+	Customer orderNamedCustomer
+}
+
+func (orderNamed) ID() OrderField { return "id" }
+
+// Fields returns the names of the fields, in declaration order
+func (orderNamed) Fields() []string {
+	return []string{
+		"id",
+		"customer.email",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (orderNamed) All() []OrderFieldName {
+	return []OrderFieldName{
+		{GoName: "ID", Name: "id"},
+		{GoName: "Customer.Email", Name: "customer.email"},
+	}
+}
+
+// OrderNamed is the exported variable for accessing Order field names
+var OrderNamed orderNamed
+
+// OrderFieldInfos returns the descriptors of the fields of Order, with their Go
+// types, nested fields following their parent
+func OrderFieldInfos() []FieldInfo {
+	return []FieldInfo{
+		{GoName: "ID", Name: "id", Type: "int"},
+		{GoName: "Customer", Name: "customer", Type: "*Customer", Pointer: true},
+		{GoName: "Customer.Email", Name: "customer.email", Type: "string"},
+	}
+}
+
+// OrderNamedMap maps the Go names of the fields of Order to their names
+var OrderNamedMap = map[string]string{
+	"ID":             "id",
+	"Customer":       "customer",
+	"Customer.Email": "customer.email",
+}
+
+// OrderNamedReverseMap maps the names of the fields of Order to their Go names
+var OrderNamedReverseMap = map[string]string{
+	"id":             "ID",
+	"customer":       "Customer",
+	"customer.email": "Customer.Email",
+}
+
+// ResolveOrderField returns the Go name of the field of Order named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveOrderField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = OrderNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// orderNamedCustomer provides methods to access field names of Order.Customer
+type orderNamedCustomer struct{}
+
+func (orderNamedCustomer) Email() OrderField { return "customer.email" }
+
+// Fields returns the names of the fields, in declaration order
+func (orderNamedCustomer) Fields() []string {
+	return []string{
+		"customer.email",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (orderNamedCustomer) All() []OrderFieldName {
+	return []OrderFieldName{
+		{GoName: "Email", Name: "customer.email"},
+	}
+}
+
+// String returns the path of Order.Customer
+func (orderNamedCustomer) String() string { return "customer" }
+
+// Path returns the path of Order.Customer as a slice
+func (orderNamedCustomer) Path() []string { return []string{"customer"} }
+
+// invoiceNamed provides methods to access field names of Invoice
+type invoiceNamed struct{}
+
+func (invoiceNamed) CreatedAt() string { return "created_at" }
+func (invoiceNamed) UpdatedAt() string { return "updated_at" }
+func (invoiceNamed) Number() string    { return "number" }
+
+// InvoiceNamed is the exported variable for accessing Invoice field names
+var InvoiceNamed invoiceNamed
+
+// Named returns the accessor of the field names of Invoice, InvoiceNamed
+func (Invoice) Named() invoiceNamed { return InvoiceNamed }
+
+// InvoiceNamedMap maps the Go names of the fields of Invoice to their names
+var InvoiceNamedMap = map[string]string{
+	"CreatedAt": "created_at",
+	"UpdatedAt": "updated_at",
+	"Number":    "number",
+}
+
+// InvoiceNamedReverseMap maps the names of the fields of Invoice to their Go names
+var InvoiceNamedReverseMap = map[string]string{
+	"created_at": "CreatedAt",
+	"updated_at": "UpdatedAt",
+	"number":     "Number",
+}
+
+// ResolveInvoiceField returns the Go name of the field of Invoice named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveInvoiceField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = InvoiceNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// AccountFieldName is a field listed by the All methods of the accessors of Account
+type AccountFieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
+// accountNamed provides methods to access field names of Account
+type accountNamed struct{}
+
+func (accountNamed) AccountID() string   { return "account_id" }
+func (accountNamed) DisplayName() string { return "display_name" }
+func (accountNamed) Email() string       { return "email_address" }
+
+// Fields returns the names of the fields, in declaration order
+func (accountNamed) Fields() []string {
+	return []string{
+		"account_id",
+		"display_name",
+		"email_address",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (accountNamed) All() []AccountFieldName {
+	return []AccountFieldName{
+		{GoName: "AccountID", Name: "account_id"},
+		{GoName: "DisplayName", Name: "display_name"},
+		{GoName: "Email", Name: "email_address"},
+	}
+}
+
+// AccountNamed is the exported variable for accessing Account field names
+var AccountNamed accountNamed
+
+// AccountNamedMap maps the Go names of the fields of Account to their names
+var AccountNamedMap = map[string]string{
+	"AccountID":   "account_id",
+	"DisplayName": "display_name",
+	"Email":       "email_address",
+}
+
+// AccountNamedReverseMap maps the names of the fields of Account to their Go names
+var AccountNamedReverseMap = map[string]string{
+	"account_id":    "AccountID",
+	"display_name":  "DisplayName",
+	"email_address": "Email",
+}
+
+// ResolveAccountField returns the Go name of the field of Account named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveAccountField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = AccountNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// contactNamed provides methods to access field names of Contact
+type contactNamed struct {
+	Address contactNamedAddress
+}
+
+func (contactNamed) Phone() string { return "phone" }
+
+// ContactNamed is the exported variable for accessing Contact field names
+var ContactNamed contactNamed
+
+// ContactNamedMap maps the Go names of the fields of Contact to their names
+var ContactNamedMap = map[string]string{
+	"Phone":        "phone",
+	"Address":      "address",
+	"Address.City": "address.town",
+}
+
+// ContactNamedReverseMap maps the names of the fields of Contact to their Go names
+var ContactNamedReverseMap = map[string]string{
+	"phone":        "Phone",
+	"address":      "Address",
+	"address.town": "Address.City",
+}
+
+// ResolveContactField returns the Go name of the field of Contact named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveContactField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = ContactNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// RegisterContactSchema registers the schema of Contact, in place of LoadLink
+// with the tag key json, the paths and offsets of its Field members computed at compile
+// time. It is called with no options by init.
+func RegisterContactSchema(opts ...LinkOption) error {
+	return RegisterSchema[Contact](Schema{
+		TagKey: "json",
+		Fields: []SchemaField{
+			{
+				Path:   []string{"phone"},
+				Offset: unsafe.Offsetof(Contact{}.Phone),
+				GoName: "Phone",
+				Type:   reflect.TypeOf(Contact{}.Phone),
+			},
+			{
+				Path:   []string{"address"},
+				Offset: unsafe.Offsetof(Contact{}.Address),
+				GoName: "Address",
+				Type:   reflect.TypeOf(Contact{}.Address),
+			},
+			{
+				Path:     []string{"address", "town"},
+				WirePath: []string{"address", "city"},
+				Offset:   unsafe.Offsetof(Contact{}.Address) + unsafe.Offsetof(Contact{}.Address.Value) + unsafe.Offsetof(Contact{}.Address.Value.City),
+				GoName:   "City",
+				Type:     reflect.TypeOf(Contact{}.Address.Value.City),
+			},
+		},
+	}, opts...)
+}
+
+func init() {
+	if err := RegisterContactSchema(); err != nil {
+		panic(err)
+	}
+}
+
+// contactNamedAddress provides methods to access field names of Contact.Address
+type contactNamedAddress struct{}
+
+func (contactNamedAddress) City() string { return "address.town" }
+
+// String returns the path of Contact.Address
+func (contactNamedAddress) String() string { return "address" }
+
+// Path returns the path of Contact.Address as a slice
+func (contactNamedAddress) Path() []string { return []string{"address"} }
+
+// PairFieldName is a field listed by the All methods of the accessors of Pair
+type PairFieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
+// pairNamed provides methods to access field names of Pair
+type pairNamed struct{}
+
+func (pairNamed) Key() string   { return "key" }
+func (pairNamed) Value() string { return "value" }
+
+// Fields returns the names of the fields, in declaration order
+func (pairNamed) Fields() []string {
+	return []string{
+		"key",
+		"value",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (pairNamed) All() []PairFieldName {
+	return []PairFieldName{
+		{GoName: "Key", Name: "key"},
+		{GoName: "Value", Name: "value"},
+	}
+}
+
+// PairNamed is the exported variable for accessing Pair field names
+var PairNamed pairNamed
+
+// Named returns the accessor of the field names of Pair, PairNamed
+func (Pair[K, V]) Named() pairNamed { return PairNamed }
+
+// PairNamedMap maps the Go names of the fields of Pair to their names
+var PairNamedMap = map[string]string{
+	"Key":   "key",
+	"Value": "value",
+}
+
+// PairNamedReverseMap maps the names of the fields of Pair to their Go names
+var PairNamedReverseMap = map[string]string{
+	"key":   "Key",
+	"value": "Value",
+}
+
+// ResolvePairField returns the Go name of the field of Pair named tag, e.g. to
+// translate wire names back to Go fields
+func ResolvePairField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = PairNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// SettingsFieldName is a field listed by the All methods of the accessors of Settings
+type SettingsFieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
+// settingsNamed provides methods to access field names of Settings
+type settingsNamed struct{}
+
+func (settingsNamed) Host() string     { return "host" }
+func (settingsNamed) Port() string     { return "port" }
+func (settingsNamed) LogLevel() string { return "log_level" }
+
+// Fields returns the names of the fields, in declaration order
+func (settingsNamed) Fields() []string {
+	return []string{
+		"host",
+		"port",
+		"log_level",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (settingsNamed) All() []SettingsFieldName {
+	return []SettingsFieldName{
+		{GoName: "Host", Name: "host"},
+		{GoName: "Port", Name: "port"},
+		{GoName: "LogLevel", Name: "log_level"},
+	}
+}
+
+// SettingsNamed is the exported variable for accessing Settings field names
+var SettingsNamed settingsNamed
+
+// SettingsNamedMap maps the Go names of the fields of Settings to their names
+var SettingsNamedMap = map[string]string{
+	"Host":     "host",
+	"Port":     "port",
+	"LogLevel": "log_level",
+}
+
+// SettingsNamedReverseMap maps the names of the fields of Settings to their Go names
+var SettingsNamedReverseMap = map[string]string{
+	"host":      "Host",
+	"port":      "Port",
+	"log_level": "LogLevel",
+}
+
+// ResolveSettingsField returns the Go name of the field of Settings named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveSettingsField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = SettingsNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// ServerConfigFieldName is a field listed by the All methods of the accessors of ServerConfig
+type ServerConfigFieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
+// serverConfigNamed provides methods to access field names of ServerConfig
+type serverConfigNamed struct {
+	ServerLimits serverConfigNamedServerLimits
+}
+
+func (serverConfigNamed) Host() string { return "host" }
+func (serverConfigNamed) Port() string { return "port" }
+
+// Fields returns the names of the fields, in declaration order
+func (serverConfigNamed) Fields() []string {
+	return []string{
+		"host",
+		"port",
+		"ServerLimits.max_conns",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (serverConfigNamed) All() []ServerConfigFieldName {
+	return []ServerConfigFieldName{
+		{GoName: "Host", Name: "host"},
+		{GoName: "Port", Name: "port"},
+		{GoName: "ServerLimits.MaxConns", Name: "ServerLimits.max_conns"},
+	}
+}
+
+// ServerConfigNamed is the exported variable for accessing ServerConfig field names
+var ServerConfigNamed serverConfigNamed
+
+// ServerConfigNamedMap maps the Go names of the fields of ServerConfig to their names
+var ServerConfigNamedMap = map[string]string{
+	"Host":                  "host",
+	"Port":                  "port",
+	"ServerLimits":          "ServerLimits",
+	"ServerLimits.MaxConns": "ServerLimits.max_conns",
+}
+
+// ServerConfigNamedReverseMap maps the names of the fields of ServerConfig to their Go names
+var ServerConfigNamedReverseMap = map[string]string{
+	"host":                   "Host",
+	"port":                   "Port",
+	"ServerLimits":           "ServerLimits",
+	"ServerLimits.max_conns": "ServerLimits.MaxConns",
+}
+
+// ResolveServerConfigField returns the Go name of the field of ServerConfig named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveServerConfigField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = ServerConfigNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// serverConfigNamedServerLimits provides methods to access field names of ServerConfig.ServerLimits
+type serverConfigNamedServerLimits struct{}
+
+func (serverConfigNamedServerLimits) MaxConns() string { return "ServerLimits.max_conns" }
+
+// Fields returns the names of the fields, in declaration order
+func (serverConfigNamedServerLimits) Fields() []string {
+	return []string{
+		"ServerLimits.max_conns",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (serverConfigNamedServerLimits) All() []ServerConfigFieldName {
+	return []ServerConfigFieldName{
+		{GoName: "MaxConns", Name: "ServerLimits.max_conns"},
+	}
+}
+
+// String returns the path of ServerConfig.ServerLimits
+func (serverConfigNamedServerLimits) String() string { return "ServerLimits" }
+
+// Path returns the path of ServerConfig.ServerLimits as a slice
+func (serverConfigNamedServerLimits) Path() []string { return []string{"ServerLimits"} }
+
+// ticketNamed provides methods to access field names of Ticket
+type ticketNamed struct {
+	Owner ticketNamedOwner
+}
+
+func (ticketNamed) ID() string         { return "id" }
+func (ticketNamed) IDNumber() int32    { return 1 }
+func (ticketNamed) Title() string      { return "title" }
+func (ticketNamed) TitleNumber() int32 { return 2 }
+func (ticketNamed) OwnerNumber() int32 { return 3 }
+
+// TicketNamed is the exported variable for accessing Ticket field names
+var TicketNamed ticketNamed
+
+// TicketNamedMap maps the Go names of the fields of Ticket to their names
+var TicketNamedMap = map[string]string{
+	"ID":          "id",
+	"Title":       "title",
+	"Owner":       "owner",
+	"Owner.Email": "owner.email",
+}
+
+// TicketNamedReverseMap maps the names of the fields of Ticket to their Go names
+var TicketNamedReverseMap = map[string]string{
+	"id":          "ID",
+	"title":       "Title",
+	"owner":       "Owner",
+	"owner.email": "Owner.Email",
+}
+
+// ResolveTicketField returns the Go name of the field of Ticket named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveTicketField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = TicketNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// ticketNamedOwner provides methods to access field names of Ticket.Owner
+type ticketNamedOwner struct{}
+
+func (ticketNamedOwner) Email() string      { return "owner.email" }
+func (ticketNamedOwner) EmailNumber() int32 { return 1 }
+
+// String returns the path of Ticket.Owner
+func (ticketNamedOwner) String() string { return "owner" }
+
+// Path returns the path of Ticket.Owner as a slice
+func (ticketNamedOwner) Path() []string { return []string{"owner"} }
+
+// signUpNamed provides methods to access field names of SignUp
+type signUpNamed struct{}
+
+func (signUpNamed) Email() string         { return "email" }
+func (signUpNamed) EmailRules() string    { return "required,email" }
+func (signUpNamed) Password() string      { return "password" }
+func (signUpNamed) PasswordRules() string { return "required,min=12" }
+func (signUpNamed) Referrer() string      { return "referrer" }
+func (signUpNamed) ReferrerRules() string { return "" }
+
+// SignUpNamed is the exported variable for accessing SignUp field names
+var SignUpNamed signUpNamed
+
+// SignUpNamedMap maps the Go names of the fields of SignUp to their names
+var SignUpNamedMap = map[string]string{
+	"Email":    "email",
+	"Password": "password",
+	"Referrer": "referrer",
+}
+
+// SignUpNamedReverseMap maps the names of the fields of SignUp to their Go names
+var SignUpNamedReverseMap = map[string]string{
+	"email":    "Email",
+	"password": "Password",
+	"referrer": "Referrer",
+}
+
+// ResolveSignUpField returns the Go name of the field of SignUp named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveSignUpField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = SignUpNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// SessionFieldName is a field listed by the All methods of the accessors of Session
+type SessionFieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
+// sessionNamed provides methods to access field names of Session
+type sessionNamed struct{}
+
+func (sessionNamed) ID() string        { return "id" }
+func (sessionNamed) userID() string    { return "user_id" }
+func (sessionNamed) expiresAt() string { return "expires_at" }
+
+// Fields returns the names of the fields, in declaration order
+func (sessionNamed) Fields() []string {
+	return []string{
+		"id",
+		"user_id",
+		"expires_at",
+	}
+}
+
+// All returns the Go names and names of the fields, in declaration order
+func (sessionNamed) All() []SessionFieldName {
+	return []SessionFieldName{
+		{GoName: "ID", Name: "id"},
+		{GoName: "userID", Name: "user_id"},
+		{GoName: "expiresAt", Name: "expires_at"},
+	}
+}
+
+// SessionNamed is the exported variable for accessing Session field names
+var SessionNamed sessionNamed
+
+// SessionNamedMap maps the Go names of the fields of Session to their names
+var SessionNamedMap = map[string]string{
+	"ID":        "id",
+	"userID":    "user_id",
+	"expiresAt": "expires_at",
+}
+
+// SessionNamedReverseMap maps the names of the fields of Session to their Go names
+var SessionNamedReverseMap = map[string]string{
+	"id":         "ID",
+	"user_id":    "userID",
+	"expires_at": "expiresAt",
+}
+
+// ResolveSessionField returns the Go name of the field of Session named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveSessionField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = SessionNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// credentialsNamed provides methods to access field names of Credentials
+type credentialsNamed struct{}
+
+func (credentialsNamed) Login() string    { return "login" }
+func (credentialsNamed) Password() string { return "pwd_hash" }
+
+// CredentialsNamed is the exported variable for accessing Credentials field names
+var CredentialsNamed credentialsNamed
+
+// CredentialsNamedMap maps the Go names of the fields of Credentials to their names
+var CredentialsNamedMap = map[string]string{
+	"Login":    "login",
+	"Password": "pwd_hash",
+}
+
+// CredentialsNamedReverseMap maps the names of the fields of Credentials to their Go names
+var CredentialsNamedReverseMap = map[string]string{
+	"login":    "Login",
+	"pwd_hash": "Password",
+}
+
+// ResolveCredentialsField returns the Go name of the field of Credentials named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveCredentialsField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = CredentialsNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// PaymentTable returns the SQL table of Payment
+func PaymentTable() string { return "payments" }
+
+// PaymentColumns returns the columns of Payment, in declaration order
+func PaymentColumns() []string {
+	return []string{
+		"id",
+		"user_id",
+		"status",
+	}
+}
+
+// PaymentQualifiedColumns returns the columns of Payment qualified by its table
+func PaymentQualifiedColumns() []string {
+	return []string{
+		"payments.id",
+		"payments.user_id",
+		"payments.status",
+	}
+}
+
+// paymentNamed provides methods to access field names of Payment
+type paymentNamed struct{}
+
+func (paymentNamed) ID() string              { return "id" }
+func (paymentNamed) IDQualified() string     { return "payments.id" }
+func (paymentNamed) IDAliased() string       { return "p.id" }
+func (paymentNamed) UserID() string          { return "user_id" }
+func (paymentNamed) UserIDQualified() string { return "payments.user_id" }
+func (paymentNamed) UserIDAliased() string   { return "p.user_id" }
+func (paymentNamed) Status() string          { return "status" }
+func (paymentNamed) StatusQualified() string { return "payments.status" }
+func (paymentNamed) StatusAliased() string   { return "p.status" }
+
+// PaymentNamed is the exported variable for accessing Payment field names
+var PaymentNamed paymentNamed
+
+// PaymentNamedMap maps the Go names of the fields of Payment to their names
+var PaymentNamedMap = map[string]string{
+	"ID":     "id",
+	"UserID": "user_id",
+	"Status": "status",
+}
+
+// PaymentNamedReverseMap maps the names of the fields of Payment to their Go names
+var PaymentNamedReverseMap = map[string]string{
+	"id":      "ID",
+	"user_id": "UserID",
+	"status":  "Status",
+}
+
+// ResolvePaymentField returns the Go name of the field of Payment named tag, e.g. to
+// translate wire names back to Go fields
+func ResolvePaymentField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = PaymentNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// STRUCT field names of the nested fields of Singer
+const (
+	SingerStruct_Address_City = "City"
+	SingerStruct_Address_Zip  = "ZipCode"
+)
+
+// singerNamed provides methods to access field names of Singer
+type singerNamed struct {
+	Address singerNamedAddress
+}
+
+func (singerNamed) SingerID() string { return "SingerId" }
+
+// SingerNamed is the exported variable for accessing Singer field names
+var SingerNamed singerNamed
+
+// SingerNamedMap maps the Go names of the fields of Singer to their names
+var SingerNamedMap = map[string]string{
+	"SingerID":     "SingerId",
+	"Address":      "Address",
+	"Address.City": "Address.City",
+	"Address.Zip":  "Address.ZipCode",
+}
+
+// SingerNamedReverseMap maps the names of the fields of Singer to their Go names
+var SingerNamedReverseMap = map[string]string{
+	"SingerId":        "SingerID",
+	"Address":         "Address",
+	"Address.City":    "Address.City",
+	"Address.ZipCode": "Address.Zip",
+}
+
+// ResolveSingerField returns the Go name of the field of Singer named tag, e.g. to
+// translate wire names back to Go fields
+func ResolveSingerField(tag string) (goFieldName string, ok bool) {
+	goFieldName, ok = SingerNamedReverseMap[tag]
+	return goFieldName, ok
+}
+
+// singerNamedAddress provides methods to access field names of Singer.Address
+type singerNamedAddress struct{}
+
+func (singerNamedAddress) City() string { return "Address.City" }
+func (singerNamedAddress) Zip() string  { return "Address.ZipCode" }
+
+// String returns the path of Singer.Address
+func (singerNamedAddress) String() string { return "Address" }
+
+// Path returns the path of Singer.Address as a slice
+func (singerNamedAddress) Path() []string { return []string{"Address"} }
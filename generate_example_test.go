@@ -1,6 +1,11 @@
 package named
 
-import "testing"
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestPersonNamed(t *testing.T) {
 	n := PersonNamed
@@ -62,6 +67,18 @@ func TestProductNamed(t *testing.T) {
 	}
 }
 
+func TestProductName_Consts(t *testing.T) {
+	// constants are usable where methods are not, e.g. switch cases
+	switch ProductNamed.Name() {
+	case ProductName_Name:
+	default:
+		t.Errorf("Expected %q, got %q", ProductNamed.Name(), ProductName_Name)
+	}
+	if ProductName_Description != "Description" {
+		t.Errorf("Expected %q, got %q", "Description", ProductName_Description)
+	}
+}
+
 func TestGeneratedNamedWithActualStruct(t *testing.T) {
 	// Test that the Named struct provides correct field name access
 	n := PersonNamed
@@ -79,3 +96,255 @@ func TestGeneratedNamedWithActualStruct(t *testing.T) {
 		t.Errorf("Email(): expected %q, got %q", "email", got)
 	}
 }
+
+func TestOrderNamed_Nested(t *testing.T) {
+	n := OrderNamed
+
+	if n.ID() != "id" || n.Customer.Email() != "customer.email" {
+		t.Errorf("Unexpected names %q, %q", n.ID(), n.Customer.Email())
+	}
+	if n.Customer.String() != "customer" || len(n.Customer.Path()) != 1 || n.Customer.Path()[0] != "customer" {
+		t.Errorf("Unexpected nested path %q, %v", n.Customer.String(), n.Customer.Path())
+	}
+}
+
+func TestInvoiceNamed_Embedded(t *testing.T) {
+	n := InvoiceNamed
+
+	if n.CreatedAt() != "created_at" || n.UpdatedAt() != "updated_at" || n.Number() != "number" {
+		t.Errorf("Unexpected names %q, %q, %q", n.CreatedAt(), n.UpdatedAt(), n.Number())
+	}
+}
+
+func TestOrderNamed_Fields(t *testing.T) {
+	if got, want := OrderNamed.Fields(), []string{"id", "customer.email"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got, want := OrderNamed.Customer.Fields(), []string{"customer.email"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	want := []OrderFieldName{{GoName: "ID", Name: "id"}, {GoName: "Customer.Email", Name: "customer.email"}}
+	if got := OrderNamed.All(); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestOrderNamedMap(t *testing.T) {
+	if got := OrderNamedMap["Customer.Email"]; got != "customer.email" {
+		t.Errorf("Expected %q, got %q", "customer.email", got)
+	}
+	if got := OrderNamedReverseMap["id"]; got != "ID" {
+		t.Errorf("Expected %q, got %q", "ID", got)
+	}
+	if len(OrderNamedMap) != 3 || len(OrderNamedReverseMap) != 3 {
+		t.Errorf("Unexpected maps %v, %v", OrderNamedMap, OrderNamedReverseMap)
+	}
+}
+
+func TestResolveOrderField(t *testing.T) {
+	if goName, ok := ResolveOrderField("customer.email"); !ok || goName != "Customer.Email" {
+		t.Errorf("Expected %q, got %q (%v)", "Customer.Email", goName, ok)
+	}
+	if _, ok := ResolveOrderField("Customer"); ok {
+		t.Error("Expected Go names not to resolve")
+	}
+}
+
+func TestAccountNamed_Fallback(t *testing.T) {
+	want := []string{"account_id", "display_name", "email_address"}
+	if got := AccountNamed.Fields(); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSettingsNamed_TagKeys(t *testing.T) {
+	want := []string{"host", "port", "log_level"}
+	if got := SettingsNamed.Fields(); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestServerConfigNamed_Squash(t *testing.T) {
+	want := []string{"host", "port", "ServerLimits.max_conns"}
+	if got := ServerConfigNamed.Fields(); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := ServerConfigNamed.ServerLimits.MaxConns(); got != "ServerLimits.max_conns" {
+		t.Errorf("Expected ServerLimits.max_conns, got %s", got)
+	}
+}
+
+func TestTicketNamed_Numbers(t *testing.T) {
+	n := TicketNamed
+	if n.Title() != "title" || n.TitleNumber() != 2 || n.OwnerNumber() != 3 || n.Owner.EmailNumber() != 1 {
+		t.Errorf("Unexpected names or numbers %q %d, %d, %d", n.Title(), n.TitleNumber(), n.OwnerNumber(), n.Owner.EmailNumber())
+	}
+}
+
+func TestSignUpNamed_Rules(t *testing.T) {
+	n := SignUpNamed
+	if n.Email() != "email" || n.EmailRules() != "required,email" || n.PasswordRules() != "required,min=12" || n.ReferrerRules() != "" {
+		t.Errorf("Unexpected rules %q, %q, %q", n.EmailRules(), n.PasswordRules(), n.ReferrerRules())
+	}
+}
+
+func TestSessionNamed_Unexported(t *testing.T) {
+	n := SessionNamed
+	if n.ID() != "id" || n.userID() != "user_id" || n.expiresAt() != "expires_at" {
+		t.Errorf("Unexpected names %q, %q, %q", n.ID(), n.userID(), n.expiresAt())
+	}
+	if got, want := n.Fields(), []string{"id", "user_id", "expires_at"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestCredentialsNamed_Rename(t *testing.T) {
+	if CredentialsNamed.Login() != "login" || CredentialsNamed.Password() != "pwd_hash" {
+		t.Errorf("Unexpected names %q, %q", CredentialsNamed.Login(), CredentialsNamed.Password())
+	}
+}
+
+func TestPaymentNamed_Qualified(t *testing.T) {
+	n := PaymentNamed
+	if n.UserID() != "user_id" || n.UserIDQualified() != "payments.user_id" || n.UserIDAliased() != "p.user_id" {
+		t.Errorf("Unexpected names %q, %q, %q", n.UserID(), n.UserIDQualified(), n.UserIDAliased())
+	}
+}
+
+func TestProductNamed_Options(t *testing.T) {
+	if got, want := ProductNamed.Options("Price"), []string{"omitempty"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := ProductNamed.Options("SKU"); got != nil {
+		t.Errorf("Expected no options, got %v", got)
+	}
+}
+
+func TestUserColumns(t *testing.T) {
+	if got := UserTable(); got != "users" {
+		t.Errorf("Expected %q, got %q", "users", got)
+	}
+	if got, want := UserColumns(), []string{"user_id", "username", "is_active"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got, want := UserQualifiedColumns(), []string{"users.user_id", "users.username", "users.is_active"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestOrderField(t *testing.T) {
+	columns := func(fields ...OrderField) []OrderField { return fields }
+
+	got := columns(OrderNamed.ID(), OrderNamed.Customer.Email(), OrderName_Customer)
+	if want := []OrderField{"id", "customer.email", "customer"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+type mockPersonNamer struct{ PersonNamer }
+
+func (mockPersonNamer) Email() string { return "mail" }
+
+func TestPersonNamer(t *testing.T) {
+	var n PersonNamer = PersonNamed
+	if n.Email() != "email" {
+		t.Errorf("Expected %q, got %q", "email", n.Email())
+	}
+
+	n = mockPersonNamer{PersonNamed}
+	if n.Email() != "mail" || n.Name() != "name" {
+		t.Errorf("Unexpected names %q, %q", n.Email(), n.Name())
+	}
+}
+
+func TestInvoice_Named(t *testing.T) {
+	var invoice Invoice
+	if got := invoice.Named().Number(); got != "number" {
+		t.Errorf("Expected %q, got %q", "number", got)
+	}
+}
+
+func TestPair_Named(t *testing.T) {
+	if got := (Pair[string, int]{}).Named().Value(); got != "value" {
+		t.Errorf("Expected %q, got %q", "value", got)
+	}
+	if got, want := (Pair[int, string]{}).Named().Fields(), []string{"key", "value"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestOrderFieldInfos(t *testing.T) {
+	want := []FieldInfo{
+		{GoName: "ID", Name: "id", Type: "int"},
+		{GoName: "Customer", Name: "customer", Type: "*Customer", Pointer: true},
+		{GoName: "Customer.Email", Name: "customer.email", Type: "string"},
+	}
+	if got := OrderFieldInfos(); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestApplyPersonPatch(t *testing.T) {
+	person := Person{Name: "Ann", Age: 30}
+
+	p := PersonToPatch(person)
+	if p.Name.Value != "Ann" || p.Age.Value != 30 {
+		t.Errorf("Unexpected patch %+v", p)
+	}
+
+	// without presence, the fields having a value are applied
+	var update PersonPatch
+	update.Email.Value = "ann@example.com"
+	ApplyPersonPatch(&person, &update)
+	if person != (Person{Name: "Ann", Age: 30, Email: "ann@example.com"}) {
+		t.Errorf("Unexpected person %+v", person)
+	}
+
+	// with presence, the present fields are applied, even zero
+	if err := LoadLink[PersonPatch]("json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded PersonPatch
+	if _, err := DecodePatch(strings.NewReader(`{"age":0}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	ApplyPersonPatch(&person, &decoded)
+	if person != (Person{Name: "Ann", Email: "ann@example.com"}) {
+		t.Errorf("Unexpected person %+v", person)
+	}
+}
+
+func TestRegisterContactSchema(t *testing.T) {
+	var c Contact
+	if !Link(&c) {
+		t.Fatal("Expected Contact to be registered by init")
+	}
+	if got := c.Address.Value.City.FullName(""); got != "address.town" {
+		t.Errorf("Expected %q, got %q", "address.town", got)
+	}
+
+	generated, _ := SchemaOf[Contact]()
+	if err := LoadLink[Contact]("json"); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ := SchemaOf[Contact]()
+	if !reflect.DeepEqual(generated, loaded) {
+		t.Errorf("Expected %+v, got %+v", loaded, generated)
+	}
+}
+
+func TestOrderPointers(t *testing.T) {
+	if OrderPointer_ID != "/id" || OrderPointer_Customer_Email != "/customer/email" {
+		t.Errorf("Unexpected pointers %q, %q", OrderPointer_ID, OrderPointer_Customer_Email)
+	}
+	if OrderName_Customer_Email != "customer.email" {
+		t.Errorf("Unexpected path %q", OrderName_Customer_Email)
+	}
+}
+
+func TestSingerStructFields(t *testing.T) {
+	if SingerStruct_Address_City != "City" || SingerStruct_Address_Zip != "ZipCode" {
+		t.Errorf("Unexpected STRUCT field names %q, %q", SingerStruct_Address_City, SingerStruct_Address_Zip)
+	}
+}
@@ -0,0 +1,104 @@
+package named
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash"
+	"reflect"
+	"unsafe"
+)
+
+type hashConfig struct {
+	exclude []string
+}
+
+// HashOption configures Hash.
+type HashOption func(*hashConfig)
+
+// ExcludePaths leaves the fields at paths (and everything nested in them) out of the hash.
+func ExcludePaths(paths ...string) HashOption {
+	return func(c *hashConfig) {
+		c.exclude = append(c.exclude, paths...)
+	}
+}
+
+// Hash writes the Field members of s to h in schema order, as length prefixed
+// full path and JSON encoded value pairs, so equal structs always produce the
+// same digest (e.g. for change detection or ETags).
+// Fields holding nested fields are represented by their nested fields, and by
+// the other exported members of their Value (JSON encoded by Go name), so a
+// change of a plain member of a nested struct changes the hash too.
+// Returns an error wrapping ErrUnknownPath for unknown excluded paths.
+func Hash[T any](s *T, h hash.Hash, opts ...HashOption) error {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return ErrSchemaNotLoaded
+	}
+
+	c := &hashConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := sch.checkPaths(c.exclude); err != nil {
+		return err
+	}
+
+	ps := sch.pathSelector(c.exclude, false)
+
+	ptr := unsafe.Pointer(s)
+
+	var lenBuf [8]byte
+	writeChunk := func(b []byte) {
+		binary.BigEndian.PutUint64(lenBuf[:], uint64(len(b)))
+		h.Write(lenBuf[:])
+		h.Write(b)
+	}
+
+	for i := 0; i < len(sch.fields); {
+		field := &sch.fields[i]
+		end := sch.subtreeEnd(i)
+
+		if ps.mode(field) == selectSkip {
+			i = end
+			continue
+		}
+
+		var data []byte
+		var err error
+		if end == i+1 {
+			// no nested fields
+			data, err = json.Marshal(field.fielder(ptr))
+		} else if plain := plainMembers(field.value(ptr)); len(plain) > 0 {
+			// the other members of the nested value, its Field members following
+			data, err = json.Marshal(plain)
+		}
+		if err != nil {
+			return field.error(err)
+		}
+		if data != nil {
+			writeChunk([]byte(field.fullName))
+			writeChunk(data)
+		}
+		i++
+	}
+
+	return nil
+}
+
+// plainMembers returns the exported members of the struct v that are not
+// Field members, by Go name, nil if none.
+func plainMembers(v reflect.Value) map[string]any {
+	var members map[string]any
+	for i := 0; i < v.NumField(); i++ {
+		member := v.Type().Field(i)
+		if !member.IsExported() || isFieldType(member.Type) {
+			continue
+		}
+		if members == nil {
+			members = make(map[string]any)
+		}
+		members[member.Name] = v.Field(i).Interface()
+	}
+	return members
+}
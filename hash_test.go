@@ -0,0 +1,87 @@
+package named
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func digest(t *testing.T, s *sampleMarshal, opts ...HashOption) string {
+	t.Helper()
+	h := sha256.New()
+	if err := Hash(s, h, opts...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return string(h.Sum(nil))
+}
+
+func TestHash(t *testing.T) {
+	a, b := newSampleMarshal(), newSampleMarshal()
+
+	if digest(t, a) != digest(t, b) {
+		t.Error("Expected equal structs to hash equally")
+	}
+
+	b.Address.Value.Zip.Value = "999"
+	if digest(t, a) == digest(t, b) {
+		t.Error("Expected a nested change to change the hash")
+	}
+	if digest(t, a, ExcludePaths("address.zip")) != digest(t, b, ExcludePaths("address.zip")) {
+		t.Error("Expected excluded fields to be ignored")
+	}
+	if digest(t, a, ExcludePaths("address")) != digest(t, b, ExcludePaths("address")) {
+		t.Error("Expected fields nested in excluded fields to be ignored")
+	}
+
+	// values moving between fields must not collide
+	c, d := &sampleMarshal{}, &sampleMarshal{}
+	c.Name.Value = "x"
+	d.Note.Value = "x"
+	if digest(t, c) == digest(t, d) {
+		t.Error("Expected the path to be part of the hash")
+	}
+
+	if err := Hash(a, sha256.New(), ExcludePaths("nope")); !errors.Is(err, ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+}
+
+type sampleHashAddress struct {
+	City  Field[string] `json:"city"`
+	Floor int           `json:"floor"`
+	note  string
+}
+
+type sampleHash struct {
+	Address Field[sampleHashAddress] `json:"address"`
+}
+
+func TestHash_PlainMembers(t *testing.T) {
+	LoadLink[sampleHash]("json")
+	sum := func(s *sampleHash, opts ...HashOption) string {
+		t.Helper()
+		h := sha256.New()
+		if err := Hash(s, h, opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return string(h.Sum(nil))
+	}
+
+	a, b := &sampleHash{}, &sampleHash{}
+	b.Address.Value.Floor = 3
+	if sum(a) == sum(b) {
+		t.Error("Expected a change of a plain nested member to change the hash")
+	}
+	if sum(a, ExcludePaths("address.city")) == sum(b, ExcludePaths("address.city")) {
+		t.Error("Expected the plain members to be hashed when a nested field is excluded")
+	}
+	if sum(a, ExcludePaths("address")) != sum(b, ExcludePaths("address")) {
+		t.Error("Expected the plain members of an excluded field to be ignored")
+	}
+
+	b.Address.Value.Floor = 0
+	b.Address.Value.note = "x"
+	if sum(a) != sum(b) {
+		t.Error("Expected the unexported members to be ignored")
+	}
+}
@@ -1,24 +1,154 @@
 package named
 
 import (
+	"encoding"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"unsafe"
 )
 
 type fieldInfo struct {
-	pathPtr *[]string // Full hierarchical path: ["parent", "child"]
-	offset  uintptr
+	pathPtr   *[]string // Full hierarchical path: ["parent", "child"]
+	offset    uintptr
+	fullName  string       // path joined with DefaultFullNameSeparator
+	wirePath  []string     // path made of the tag key names, differs from the path with the named tag
+	goName    string       // Go struct field name
+	typ       reflect.Type // Field[T] or FieldSlice[T, E] type
+	sensitive bool         // tagged with `sensitive:"true"`
+	omitEmpty bool         // json "omitempty" or "omitzero" tag option
+}
+
+// value returns the addressable Value member of the field inside the struct at ptr.
+func (f *fieldInfo) value(ptr unsafe.Pointer) reflect.Value {
+	return reflect.NewAt(f.typ, unsafe.Add(ptr, f.offset)).Elem().Field(2) // Value is at index 2
+}
+
+// present returns the presence flag of the field inside the struct at ptr, see
+// Presence.
+func (f *fieldInfo) present(ptr unsafe.Pointer) *bool {
+	member, ok := f.typ.FieldByName("present")
+	if !ok || member.Type.Kind() != reflect.Bool {
+		return nil
+	}
+	return (*bool)(unsafe.Add(ptr, f.offset+member.Offset))
+}
+
+// error returns err tied to the field.
+func (f *fieldInfo) error(err error) *FieldError {
+	return &FieldError{Path: *f.pathPtr, WirePath: f.wirePath, Err: err}
+}
+
+// fielder returns the field inside the struct at ptr.
+func (f *fieldInfo) fielder(ptr unsafe.Pointer) fielder {
+	return reflect.NewAt(f.typ, unsafe.Add(ptr, f.offset)).Interface().(fielder)
 }
 
 type schema struct {
-	fields []fieldInfo
-	TagKey string
+	fields     []fieldInfo
+	TagKey     string
+	goType     reflect.Type  // the type described by the schema
+	style      FullNameStyle // default style of FullNameAs, dotted if unset
+	separator  string        // default separator of FullName, global default if empty
+	unexported bool          // Field members of unexported fields collected, see WithIncludeUnexported
+}
+
+// LinkOption configures the schema built by LoadLink.
+type LinkOption func(*schema)
+
+// WithSeparator sets the separator used by FullName("") for the fields of the type.
+func WithSeparator(sep string) LinkOption {
+	return func(s *schema) {
+		s.separator = sep
+	}
+}
+
+// WithFullNameStyle sets the style used by FullNameAs(StyleDefault) for the fields of the type.
+func WithFullNameStyle(style FullNameStyle) LinkOption {
+	return func(s *schema) {
+		s.style = style
+	}
+}
+
+// WithIncludeUnexported also links the Field members of unexported fields,
+// skipped otherwise, as the IncludeUnexported option of generate-named does
+// for the generated accessors.
+func WithIncludeUnexported() LinkOption {
+	return func(s *schema) {
+		s.unexported = true
+	}
+}
+
+// fieldPath is the allocation behind every path pointer built by collectFields.
+// names goes first, so a path pointer can be cast back to its *fieldPath
+// to reach the schema that owns it.
+type fieldPath struct {
+	names  []string
+	sch    *schema
+	quoted bool // json ",string" tag option
+}
+
+// pathSchema returns the schema owning a path pointer built by collectFields.
+func pathSchema(pathPtr *[]string) *schema {
+	if pathPtr == nil {
+		return nil
+	}
+	return (*fieldPath)(unsafe.Pointer(pathPtr)).sch
+}
+
+// pathQuoted reports whether the field owning a path pointer built by
+// collectFields has the json ",string" tag option, and a quotable Value.
+func pathQuoted(pathPtr *[]string) bool {
+	return pathPtr != nil && (*fieldPath)(unsafe.Pointer(pathPtr)).quoted
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// quotable reports whether the json ",string" tag option applies to the
+// values of type t, as encoding/json does: bools, numbers and strings (or
+// pointers to them) not encoded by their own marshaler.
+func quotable(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
 }
 
 var cachedSchemaMap = make(map[uintptr]*schema)
 
+// cachedTagSchemaMap keeps every schema loaded for a type, by tag key,
+// so a type can be named after several tag keys (e.g. json and db).
+var cachedTagSchemaMap = make(map[uintptr]map[string]*schema)
+
+// ErrSchemaNotLoaded is returned when an operation needs the schema of a type
+// that was not registered with LoadLink.
+var ErrSchemaNotLoaded = errors.New("named: schema not loaded, call LoadLink first")
+
+// ErrUnknownPath is returned when a path does not match any field of the schema.
+var ErrUnknownPath = errors.New("named: unknown field path")
+
+// ErrDuplicateName is returned by LoadLink when two fields at the same level
+// resolve to the same name.
+var ErrDuplicateName = errors.New("named: duplicate field name")
+
 // emptyInterface mimics the internal memory layout of a Go empty interface (any).
 // In the standard Go runtime, an interface is a pair of pointers: {type, data}.
 //
@@ -31,36 +161,79 @@ type emptyInterface struct {
 
 // LoadLink generates and loads the schema for type T using the specified tagKey.
 // The generated schema is cached for future Link calls. T must be a struct type.
+// opts can set per type defaults such as WithSeparator.
+// A type can be loaded for several tag keys (see ConvertKeys), Link uses the last one loaded.
 // not async safe, should be called before any Link calls.
-func LoadLink[T any](tagKey string) error {
+func LoadLink[T any](tagKey string, opts ...LinkOption) error {
 	var zero T
 	tVal := reflect.TypeOf(zero)
 
-	if tVal.Kind() != reflect.Struct {
-		return errors.New("CacheSchema: T must be a struct type")
-	}
-
 	// Get type ID for fast lookup
 	var gen any = (*T)(nil)
 	typeID := uintptr((*emptyInterface)(unsafe.Pointer(&gen)).typ)
 
-	// Build schema
-	var sch *schema
-	{
-		var fields []fieldInfo
-		collectFields(tVal, tagKey, 0, nil, &fields)
-		sch = &schema{
-			fields: fields,
-			TagKey: tagKey,
+	return loadLink(tVal, typeID, tagKey, opts)
+}
+
+// LoadLinkAll works like LoadLink for each type of types, given as values or
+// pointers (e.g. User{} or (*User)(nil)), so many types can be registered at once.
+// Every type is attempted, the returned error lists all the failing ones.
+// not async safe, should be called before any Link calls.
+func LoadLinkAll(tagKey string, types ...any) error {
+	var errs []error
+	for _, t := range types {
+		tVal := reflect.TypeOf(t)
+		if tVal != nil && tVal.Kind() == reflect.Pointer {
+			tVal = tVal.Elem()
+		}
+		if tVal == nil {
+			errs = append(errs, errors.New("LoadLinkAll: nil type"))
+			continue
+		}
+
+		// type ID of *T, same as the one computed by LoadLink
+		gen := reflect.New(tVal).Interface()
+		typeID := uintptr((*emptyInterface)(unsafe.Pointer(&gen)).typ)
+
+		if err := loadLink(tVal, typeID, tagKey, nil); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tVal, err))
 		}
 	}
+	return errors.Join(errs...)
+}
 
-	// Cache schema
-	cachedSchemaMap[typeID] = sch
+func loadLink(tVal reflect.Type, typeID uintptr, tagKey string, opts []LinkOption) error {
+	if tVal == nil || tVal.Kind() != reflect.Struct {
+		return errors.New("CacheSchema: T must be a struct type")
+	}
+
+	// Build schema
+	sch := &schema{
+		TagKey: tagKey,
+		goType: tVal,
+	}
+	for _, opt := range opts {
+		opt(sch)
+	}
+	if err := collectFields(tVal, tagKey, 0, nil, nil, sch); err != nil {
+		return err
+	}
+
+	cacheSchema(typeID, sch)
+	onLoad(sch)
 
 	return nil
 }
 
+// cacheSchema caches sch for the type with typeID, the last cached one is used by Link.
+func cacheSchema(typeID uintptr, sch *schema) {
+	cachedSchemaMap[typeID] = sch
+	if cachedTagSchemaMap[typeID] == nil {
+		cachedTagSchemaMap[typeID] = make(map[string]*schema)
+	}
+	cachedTagSchemaMap[typeID][sch.TagKey] = sch
+}
+
 // Link populates all Field[T] fields in the struct pointed to by s with their path information.
 // T must be a struct type previously registered with LoadLink.
 // returns true if linking was successful, false otherwise.
@@ -73,6 +246,9 @@ func Link[T any](s *T) bool {
 
 	// load from cache
 	sch, ok := cachedSchemaMap[typeID]
+	if linkHooks.OnLink != nil {
+		onLink[T](sch, ok)
+	}
 	if !ok {
 		return false
 	}
@@ -106,6 +282,9 @@ func LinkWithPath[T any](s *T, path *[]string) bool {
 
 	// load from cache
 	sch, ok := cachedSchemaMap[typeID]
+	if linkHooks.OnLink != nil {
+		onLink[T](sch, ok)
+	}
 	if !ok {
 		return false
 	}
@@ -122,64 +301,183 @@ func LinkWithPath[T any](s *T, path *[]string) bool {
 	return true
 }
 
+// typeIDOf returns the cache key for type T.
+func typeIDOf[T any]() uintptr {
+	var gen any = (*T)(nil)
+	return uintptr((*emptyInterface)(unsafe.Pointer(&gen)).typ)
+}
+
+// loadSchema returns the cached schema for type T.
+func loadSchema[T any]() (*schema, bool) {
+	sch, ok := cachedSchemaMap[typeIDOf[T]()]
+	return sch, ok
+}
+
+// hasTagOption reports whether the tag value has option after its name, e.g. "id,string".
+func hasTagOption(tag, option string) bool {
+	if i := strings.Index(tag, ","); i != -1 {
+		for _, opt := range strings.Split(tag[i+1:], ",") {
+			if opt == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FieldPaths returns the full paths of the Field members of T in schema order,
+// joined with the separator of T (see WithSeparator), nil if T was not
+// registered with LoadLink.
+func FieldPaths[T any]() []string {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil
+	}
+
+	paths := make([]string, len(sch.fields))
+	for i := range sch.fields {
+		paths[i] = sch.fullName(&sch.fields[i])
+	}
+	return paths
+}
+
+// FieldValues returns pointers to the Value of every Field member of s, in
+// the same order as FieldPaths, e.g. to be used as sql.Rows.Scan destinations.
+// Returns nil if T was not registered with LoadLink.
+func FieldValues[T any](s *T) []any {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil
+	}
+
+	ptr := unsafe.Pointer(s)
+
+	values := make([]any, len(sch.fields))
+	for i := range sch.fields {
+		values[i] = sch.fields[i].value(ptr).Addr().Interface()
+	}
+	return values
+}
+
+var sliceStringPtrType = reflect.TypeOf((*[]string)(nil))
+
+// isFieldType reports whether t follows the Field[T] / FieldSlice[T, E] layout.
+func isFieldType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return false
+	}
+	firstField := t.Field(0)
+	return firstField.Type == sliceStringPtrType && firstField.Name == "path"
+}
+
+// loadTagSchema returns the schema loaded for type T with tagKey.
+func loadTagSchema[T any](tagKey string) (*schema, bool) {
+	sch, ok := cachedTagSchemaMap[typeIDOf[T]()][tagKey]
+	return sch, ok
+}
+
+// appendPath returns a new path made of parent and name.
+func appendPath(parent []string, name string) []string {
+	path := make([]string, len(parent)+1)
+	copy(path, parent)
+	path[len(parent)] = name
+	return path
+}
+
 // collectFields recursively collects all Field[T] fields with absolute offsets
-func collectFields(tVal reflect.Type, tagKey string, baseOffset uintptr, parentPath []string, fields *[]fieldInfo) {
-	sliceStringPtrType := reflect.TypeOf((*[]string)(nil))
+// returns an error wrapping ErrDuplicateName if two fields of a level share a name.
+func collectFields(tVal reflect.Type, tagKey string, baseOffset uintptr, parentPath, parentWirePath []string, sch *schema) error {
+	// names (and wire names) already used at this level, mapped to their Go field name
+	seen := make(map[string]string)
+	seenWire := make(map[string]string)
 
 	for i := 0; i < tVal.NumField(); i++ {
 		field := tVal.Field(i)
 
-		// skip unexported fields
-		if !field.IsExported() {
+		// skip unexported fields, unless WithIncludeUnexported
+		if !field.IsExported() && !sch.unexported {
+			debugSkipped(tVal, field, "unexported")
 			continue
 		}
 
 		// skip fields with tag "-"
 		tagName := strings.Split(field.Tag.Get(tagKey), ",")[0]
 		if tagName == "-" {
+			debugSkipped(tVal, field, tagKey+` tag is "-"`)
 			continue
 		}
 
 		// check for Field[T] pattern
-		if field.Type.Kind() == reflect.Struct && field.Type.NumField() > 0 {
-			firstField := field.Type.Field(0)
-			if firstField.Type == sliceStringPtrType && firstField.Name == "path" {
-				// Found a Field[T]
-				n := strings.Split(field.Tag.Get(tagKey), ",")[0]
-				if n == "" {
-					n = field.Name
-				}
+		if !isFieldType(field.Type) {
+			debugSkipped(tVal, field, "not a Field or FieldSlice layout: "+field.Type.String())
+			continue
+		}
 
-				// Build hierarchical path as slice
-				var currentPath []string
-				if len(parentPath) > 0 {
-					currentPath = make([]string, len(parentPath)+1)
-					copy(currentPath, parentPath)
-					currentPath[len(parentPath)] = n
-				} else {
-					currentPath = []string{n}
-				}
+		// Found a Field[T]
+		wire := tagName
+		if wire == "" {
+			wire = field.Name
+		}
+
+		// the named tag overrides the name, but not the wire name
+		n := wire
+		if name := strings.Split(field.Tag.Get(NamedTagKey), ",")[0]; name == "-" {
+			debugSkipped(tVal, field, NamedTagKey+` tag is "-"`)
+			continue
+		} else if name != "" {
+			n = name
+		}
+
+		if prev, ok := seen[n]; ok {
+			return fmt.Errorf("%w: %q used by both %s and %s in %s", ErrDuplicateName, n, prev, field.Name, tVal)
+		}
+		seen[n] = field.Name
+
+		// names overridden by the named tag must keep distinct wire names
+		if prev, ok := seenWire[wire]; ok {
+			return fmt.Errorf("%w: %s name %q used by both %s and %s in %s", ErrDuplicateName, tagKey, wire, prev, field.Name, tVal)
+		}
+		seenWire[wire] = field.Name
 
-				// Allocate path slice on heap to ensure it persists
-				pathPtr := new([]string)
-				*pathPtr = currentPath
-
-				// Add to flat list with absolute offset
-				*fields = append(*fields, fieldInfo{
-					pathPtr: pathPtr,
-					offset:  baseOffset + field.Offset,
-				})
-
-				// Check if Value is a struct that might contain more Field[T] fields
-				if field.Type.NumField() >= 3 {
-					valueField := field.Type.Field(2) // Value is at index 2 (path=0, parentPath=1, Value=2)
-					if valueField.Name == "Value" && valueField.Type.Kind() == reflect.Struct {
-						// Recursively collect fields from nested struct, passing current path
-						nestedBaseOffset := baseOffset + field.Offset + valueField.Offset
-						collectFields(valueField.Type, tagKey, nestedBaseOffset, currentPath, fields)
-					}
+		// Build hierarchical path as slice
+		currentPath := appendPath(parentPath, n)
+		currentWirePath := currentPath
+		if n != wire || !slices.Equal(parentPath, parentWirePath) {
+			currentWirePath = appendPath(parentWirePath, wire)
+		}
+
+		// Allocate path slice on heap to ensure it persists
+		pathPtr := &(&fieldPath{
+			names:  currentPath,
+			sch:    sch,
+			quoted: hasTagOption(field.Tag.Get("json"), "string") && quotable(field.Type.Field(2).Type),
+		}).names
+
+		// Add to flat list with absolute offset
+		sch.fields = append(sch.fields, fieldInfo{
+			pathPtr:   pathPtr,
+			offset:    baseOffset + field.Offset,
+			fullName:  strings.Join(currentPath, DefaultFullNameSeparator),
+			wirePath:  currentWirePath,
+			goName:    field.Name,
+			typ:       field.Type,
+			sensitive: field.Tag.Get(SensitiveTagKey) == "true",
+			omitEmpty: hasTagOption(field.Tag.Get("json"), "omitempty") || hasTagOption(field.Tag.Get("json"), "omitzero"),
+		})
+		debugCollected(tVal, &sch.fields[len(sch.fields)-1])
+
+		// Check if Value is a struct that might contain more Field[T] fields
+		if field.Type.NumField() >= 3 {
+			valueField := field.Type.Field(2) // Value is at index 2 (path=0, parentPath=1, Value=2)
+			if valueField.Name == "Value" && valueField.Type.Kind() == reflect.Struct {
+				// Recursively collect fields from nested struct, passing current path
+				nestedBaseOffset := baseOffset + field.Offset + valueField.Offset
+				if err := collectFields(valueField.Type, tagKey, nestedBaseOffset, currentPath, currentWirePath, sch); err != nil {
+					return err
 				}
 			}
 		}
 	}
+
+	return nil
 }
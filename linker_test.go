@@ -2,6 +2,9 @@ package named
 
 import (
 	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"unsafe"
 )
@@ -422,3 +425,151 @@ func TestLinkWithPath(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadLink_DuplicateName(t *testing.T) {
+	type Dup struct {
+		A Field[int] `json:"B"`
+		B Field[int] // untagged, uses "B" too
+	}
+
+	err := LoadLink[Dup]("json")
+	if !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("Expected ErrDuplicateName, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "A") || !strings.Contains(err.Error(), "B") {
+		t.Errorf("Expected error to name both fields, got %v", err)
+	}
+
+	if _, ok := loadSchema[Dup](); ok {
+		t.Error("Expected schema not to be cached on error")
+	}
+
+	// same name at different levels is fine
+	type Inner struct {
+		A Field[int] `json:"a"`
+	}
+	type Outer struct {
+		A Field[Inner] `json:"a"`
+	}
+	if err := LoadLink[Outer]("json"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestLoadLink_IncludeUnexported(t *testing.T) {
+	type Query struct {
+		Name  Field[string] `db:"name"`
+		limit Field[int]    `db:"limit"`
+	}
+
+	if err := LoadLink[Query]("db"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	q := Query{}
+	Link(&q)
+	if q.limit.Name() != "" {
+		t.Errorf("Expected the unexported field to be skipped, got %q", q.limit.Name())
+	}
+
+	if err := LoadLink[Query]("db", WithIncludeUnexported()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	q = Query{}
+	Link(&q)
+	if q.Name.Name() != "name" || q.limit.Name() != "limit" {
+		t.Errorf("Expected both fields to be linked, got %q, %q", q.Name.Name(), q.limit.Name())
+	}
+}
+
+func TestLoadLinkAll(t *testing.T) {
+	type First struct {
+		A Field[int] `json:"a"`
+	}
+	type Second struct {
+		B Field[int] `json:"b"`
+	}
+	type Broken struct {
+		A Field[int] `json:"B"`
+		B Field[int]
+	}
+
+	err := LoadLinkAll("json", First{}, (*Second)(nil), Broken{}, 42)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if !errors.Is(err, ErrDuplicateName) || !strings.Contains(err.Error(), "Broken") || !strings.Contains(err.Error(), "int") {
+		t.Errorf("Expected the error to list every failing type, got %v", err)
+	}
+
+	f, s := First{}, Second{}
+	if !Link(&f) || !Link(&s) {
+		t.Fatal("Expected valid types to be registered")
+	}
+	if f.A.Name() != "a" || s.B.Name() != "b" {
+		t.Errorf("Unexpected names %q and %q", f.A.Name(), s.B.Name())
+	}
+}
+
+func TestLoadLink_NamedTag(t *testing.T) {
+	type Address struct {
+		City Field[string] `json:"city_name" named:"city"`
+	}
+	type Account struct {
+		Email   Field[string]  `json:"email_address" named:"email"`
+		Address Field[Address] `json:"addr" named:"address"`
+		Secret  Field[string]  `json:"secret" named:"-"`
+	}
+
+	if err := LoadLink[Account]("json"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	a := Account{}
+	a.Email.Value = "a@b.c"
+	a.Address.Value.City.Value = "X"
+	if !Link(&a) {
+		t.Fatal("Link failed")
+	}
+
+	if a.Email.Name() != "email" || a.Address.Value.City.FullName(".") != "address.city" {
+		t.Errorf("Unexpected names %q and %q", a.Email.Name(), a.Address.Value.City.FullName("."))
+	}
+	if a.Secret.Name() != "" {
+		t.Errorf("Expected Secret to be skipped, got %q", a.Secret.Name())
+	}
+
+	// encoding keeps the tag key names
+	data, err := MarshalOnly(&a, "email", "address.city")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := `{"email_address":"a@b.c","addr":{"city_name":"X"}}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	s, _ := SchemaOf[Account]()
+	if !slices.Equal(s.Fields[2].WirePath, []string{"addr", "city_name"}) || !slices.Equal(s.Fields[2].Path, []string{"address", "city"}) {
+		t.Errorf("Unexpected paths %v and %v", s.Fields[2].Path, s.Fields[2].WirePath)
+	}
+
+	// duplicates are detected on the overridden names
+	type Dup struct {
+		A Field[int] `json:"a" named:"b"`
+		B Field[int] `json:"b"`
+	}
+	if err := LoadLink[Dup]("json"); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("Expected ErrDuplicateName, got %v", err)
+	}
+
+	// and on the wire names of the tag key, the overrides being distinct
+	type DupWire struct {
+		A Field[int] `db:"email" json:"email_a" named:"a"`
+		B Field[int] `db:"email" json:"email_b" named:"b"`
+	}
+	if err := LoadLink[DupWire]("db"); !errors.Is(err, ErrDuplicateName) || !strings.Contains(err.Error(), `db name "email"`) {
+		t.Errorf("Expected ErrDuplicateName for the db name, got %v", err)
+	}
+	if err := LoadLink[DupWire]("json"); err != nil {
+		t.Errorf("Expected the json names to be distinct, got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package named
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// MarshalOnly returns the JSON encoding of the Field members of s selected by
+// paths (full paths such as "address.city"). Selecting a field selects its whole
+// value, selecting a nested field emits only the selected members of its parents.
+// Members that are not Field members are never emitted.
+// Returns an error wrapping ErrUnknownPath if a path is not part of the schema.
+func MarshalOnly[T any](s *T, paths ...string) ([]byte, error) {
+	return marshalSelected(s, paths, true)
+}
+
+// MarshalExcept returns the JSON encoding of the Field members of s, leaving out
+// the fields at paths (and everything nested in them).
+// Returns an error wrapping ErrUnknownPath if a path is not part of the schema.
+func MarshalExcept[T any](s *T, paths ...string) ([]byte, error) {
+	return marshalSelected(s, paths, false)
+}
+
+type selectMode uint8
+
+const (
+	selectSkip selectMode = iota
+	selectFull
+	selectPartial
+)
+
+// pathSelector decides what to emit for each path of a schema.
+type pathSelector struct {
+	paths map[string]struct{}
+	only  bool // paths are the ones to keep, otherwise the ones to drop
+}
+
+func (ps *pathSelector) mode(field *fieldInfo) selectMode {
+	// selected (or dropped) through the field itself or one of its parents
+	n := 0
+	for _, elem := range *field.pathPtr {
+		n += len(elem)
+		if _, ok := ps.paths[field.fullName[:n]]; ok {
+			if ps.only {
+				return selectFull
+			}
+			return selectSkip
+		}
+		n += len(DefaultFullNameSeparator)
+	}
+
+	// selected (or dropped) through one of its children
+	for p := range ps.paths {
+		if len(p) > len(field.fullName) && strings.HasPrefix(p, field.fullName) &&
+			strings.HasPrefix(p[len(field.fullName):], DefaultFullNameSeparator) {
+			return selectPartial
+		}
+	}
+
+	if ps.only {
+		return selectSkip
+	}
+	return selectFull
+}
+
+func marshalSelected[T any](s *T, paths []string, only bool) ([]byte, error) {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil, ErrSchemaNotLoaded
+	}
+
+	if err := sch.checkPaths(paths); err != nil {
+		return nil, err
+	}
+
+	ps := sch.pathSelector(paths, only)
+
+	var buf bytes.Buffer
+	if err := writeSelected(&buf, sch, unsafe.Pointer(s), 0, len(sch.fields), 0, ps); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkPaths returns an error wrapping ErrUnknownPath for the first path
+// that does not match a field of the schema.
+func (sch *schema) checkPaths(paths []string) error {
+	for _, p := range paths {
+		if sch.fieldByPath(p) == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownPath, p)
+		}
+	}
+	return nil
+}
+
+// fieldByPath returns the field with the full path p, joined with the
+// separator of the schema (see sep).
+func (sch *schema) fieldByPath(p string) *fieldInfo {
+	sep := sch.sep()
+	for i := range sch.fields {
+		if pathEquals(*sch.fields[i].pathPtr, sep, p) {
+			return &sch.fields[i]
+		}
+	}
+	return nil
+}
+
+// pathEquals reports whether path joined with sep equals p, without joining it.
+func pathEquals(path []string, sep, p string) bool {
+	for i, elem := range path {
+		if i > 0 {
+			if !strings.HasPrefix(p, sep) {
+				return false
+			}
+			p = p[len(sep):]
+		}
+		if !strings.HasPrefix(p, elem) {
+			return false
+		}
+		p = p[len(elem):]
+	}
+	return p == ""
+}
+
+// sep returns the separator of the full paths taken and returned by the path
+// helpers (MarshalOnly, FieldPaths, Presence...): the one set with WithSeparator,
+// or else the global default (see SetDefaultSeparator), as FullName("") does.
+func (sch *schema) sep() string {
+	if sch.separator != "" {
+		return sch.separator
+	}
+	return defaultSeparator
+}
+
+// fullName returns the full path of field joined with sch.sep().
+func (sch *schema) fullName(field *fieldInfo) string {
+	if sep := sch.sep(); sep != DefaultFullNameSeparator {
+		return strings.Join(*field.pathPtr, sep)
+	}
+	return field.fullName
+}
+
+// pathSelector returns a pathSelector for the full paths of the schema,
+// unknown paths being ignored.
+func (sch *schema) pathSelector(paths []string, only bool) *pathSelector {
+	ps := &pathSelector{paths: make(map[string]struct{}, len(paths)), only: only}
+	for _, p := range paths {
+		if field := sch.fieldByPath(p); field != nil {
+			ps.paths[field.fullName] = struct{}{}
+		}
+	}
+	return ps
+}
+
+// subtreeEnd returns the index following the last field nested in sch.fields[i].
+// Nested fields always follow their parent, see collectFields.
+func (sch *schema) subtreeEnd(i int) int {
+	depth := len(*sch.fields[i].pathPtr)
+	j := i + 1
+	for j < len(sch.fields) && len(*sch.fields[j].pathPtr) > depth {
+		j++
+	}
+	return j
+}
+
+// writeSelected writes a JSON object holding the fields of sch.fields[start:end]
+// found at depth, according to ps.
+func writeSelected(buf *bytes.Buffer, sch *schema, ptr unsafe.Pointer, start, end, depth int, ps *pathSelector) error {
+	buf.WriteByte('{')
+
+	first := true
+	for i := start; i < end; i = sch.subtreeEnd(i) {
+		field := &sch.fields[i]
+
+		mode := ps.mode(field)
+		if mode == selectSkip || (mode == selectFull && field.omitEmpty && field.fielder(ptr).IsZero()) {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		key, _ := json.Marshal(field.wirePath[depth])
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		if mode == selectPartial {
+			if err := writeSelected(buf, sch, ptr, i+1, sch.subtreeEnd(i), depth+1, ps); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := json.Marshal(field.fielder(ptr))
+		if err != nil {
+			return field.error(err)
+		}
+		buf.Write(data)
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
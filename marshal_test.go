@@ -0,0 +1,79 @@
+package named
+
+import (
+	"errors"
+	"testing"
+)
+
+type sampleMarshalAddress struct {
+	City Field[string] `json:"city"`
+	Zip  Field[string] `json:"zip"`
+}
+
+type sampleMarshal struct {
+	ID      Field[int]                  `json:"id"`
+	Name    Field[string]               `json:"name"`
+	Note    Field[string]               `json:"note,omitempty"`
+	Address Field[sampleMarshalAddress] `json:"address"`
+}
+
+func init() {
+	LoadLink[sampleMarshal]("json")
+}
+
+func newSampleMarshal() *sampleMarshal {
+	s := &sampleMarshal{}
+	s.ID.Value = 1
+	s.Name.Value = "bob"
+	s.Address.Value.City.Value = "x"
+	s.Address.Value.Zip.Value = "123"
+	return s
+}
+
+func TestMarshalOnly(t *testing.T) {
+	tests := []struct {
+		paths    []string
+		expected string
+	}{
+		{nil, `{}`},
+		{[]string{"id"}, `{"id":1}`},
+		{[]string{"name", "address.city"}, `{"name":"bob","address":{"city":"x"}}`},
+		{[]string{"address"}, `{"address":{"city":"x","zip":"123"}}`},
+		{[]string{"note"}, `{}`},
+	}
+
+	for _, tt := range tests {
+		data, err := MarshalOnly(newSampleMarshal(), tt.paths...)
+		if err != nil {
+			t.Fatalf("MarshalOnly(%v): Unexpected error: %v", tt.paths, err)
+		}
+		if string(data) != tt.expected {
+			t.Errorf("MarshalOnly(%v): Expected %s, got %s", tt.paths, tt.expected, data)
+		}
+	}
+
+	if _, err := MarshalOnly(newSampleMarshal(), "address.country"); !errors.Is(err, ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+}
+
+func TestMarshalExcept(t *testing.T) {
+	tests := []struct {
+		paths    []string
+		expected string
+	}{
+		{nil, `{"id":1,"name":"bob","address":{"city":"x","zip":"123"}}`},
+		{[]string{"address"}, `{"id":1,"name":"bob"}`},
+		{[]string{"id", "address.zip"}, `{"name":"bob","address":{"city":"x"}}`},
+	}
+
+	for _, tt := range tests {
+		data, err := MarshalExcept(newSampleMarshal(), tt.paths...)
+		if err != nil {
+			t.Fatalf("MarshalExcept(%v): Unexpected error: %v", tt.paths, err)
+		}
+		if string(data) != tt.expected {
+			t.Errorf("MarshalExcept(%v): Expected %s, got %s", tt.paths, tt.expected, data)
+		}
+	}
+}
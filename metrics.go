@@ -0,0 +1,110 @@
+package named
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// LinkHooks are optional callbacks observing the linker, see SetLinkHooks.
+// They are called synchronously, from the goroutine calling Link, so they
+// must be cheap and async safe.
+type LinkHooks struct {
+	// OnLink is called by Link and LinkWithPath, cached reports whether the
+	// schema of t was found (Link fails otherwise).
+	OnLink func(t reflect.Type, cached bool)
+	// OnLoad is called when a schema of t is cached by LoadLink, LoadLinkAll
+	// or RegisterSchema.
+	OnLoad func(t reflect.Type, tagKey string)
+}
+
+var linkHooks LinkHooks
+
+// SetLinkHooks installs h, the zero LinkHooks removes them.
+// not async safe, should be called before any Link calls.
+func SetLinkHooks(h LinkHooks) {
+	linkHooks = h
+}
+
+// onLink must only be called when linkHooks.OnLink is set, checked inline by
+// Link to keep its fast path.
+func onLink[T any](sch *schema, cached bool) {
+	if cached {
+		linkHooks.OnLink(sch.goType, true)
+		return
+	}
+	linkHooks.OnLink(reflect.TypeFor[T](), false)
+}
+
+func onLoad(sch *schema) {
+	if linkHooks.OnLoad != nil {
+		linkHooks.OnLoad(sch.goType, sch.TagKey)
+	}
+}
+
+// LinkCounts are the counters of a type in LinkCounters.
+type LinkCounts struct {
+	Hits   uint64 `json:"hits"`   // Link calls with a cached schema
+	Misses uint64 `json:"misses"` // Link calls without schema
+	Loads  uint64 `json:"loads"`  // schemas cached
+}
+
+type linkCounters struct {
+	hits, misses, loads atomic.Uint64
+}
+
+// LinkCounters counts the linker events per type. It is an expvar.Var:
+//
+//	c := &named.LinkCounters{}
+//	named.SetLinkHooks(c.Hooks())
+//	expvar.Publish("named", c)
+type LinkCounters struct {
+	types sync.Map // type name -> *linkCounters
+}
+
+func (c *LinkCounters) counters(t reflect.Type) *linkCounters {
+	name := t.String()
+	if v, ok := c.types.Load(name); ok {
+		return v.(*linkCounters)
+	}
+	v, _ := c.types.LoadOrStore(name, &linkCounters{})
+	return v.(*linkCounters)
+}
+
+// Hooks returns the LinkHooks updating c.
+func (c *LinkCounters) Hooks() LinkHooks {
+	return LinkHooks{
+		OnLink: func(t reflect.Type, cached bool) {
+			if cached {
+				c.counters(t).hits.Add(1)
+			} else {
+				c.counters(t).misses.Add(1)
+			}
+		},
+		OnLoad: func(t reflect.Type, _ string) {
+			c.counters(t).loads.Add(1)
+		},
+	}
+}
+
+// Snapshot returns the current counts by type name (package qualified).
+func (c *LinkCounters) Snapshot() map[string]LinkCounts {
+	out := make(map[string]LinkCounts)
+	c.types.Range(func(k, v any) bool {
+		counters := v.(*linkCounters)
+		out[k.(string)] = LinkCounts{
+			Hits:   counters.hits.Load(),
+			Misses: counters.misses.Load(),
+			Loads:  counters.loads.Load(),
+		}
+		return true
+	})
+	return out
+}
+
+// String returns the snapshot as JSON, implementing expvar.Var.
+func (c *LinkCounters) String() string {
+	data, _ := json.Marshal(c.Snapshot())
+	return string(data)
+}
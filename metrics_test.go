@@ -0,0 +1,49 @@
+package named
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type metricsItem struct {
+	A Field[int] `json:"a"`
+}
+
+type metricsSample struct {
+	Items FieldSlice[[]metricsItem, metricsItem] `json:"items"`
+}
+
+func TestLinkCounters(t *testing.T) {
+	c := &LinkCounters{}
+	SetLinkHooks(c.Hooks())
+	defer SetLinkHooks(LinkHooks{})
+
+	type unknown struct{}
+	Link(&unknown{})
+
+	if err := LoadLinkAll("json", metricsSample{}, metricsItem{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s := metricsSample{}
+	s.Items.Value = make([]metricsItem, 1)
+	Link(&s)
+	Link(&s)
+	s.Items.At(0)
+
+	counts := c.Snapshot()
+	if got := counts["named.unknown"]; got != (LinkCounts{Misses: 1}) {
+		t.Errorf("Unexpected counts for unknown %+v", got)
+	}
+	if got := counts["named.metricsSample"]; got != (LinkCounts{Hits: 2, Loads: 1}) {
+		t.Errorf("Unexpected counts for metricsSample %+v", got)
+	}
+	// linked with LinkWithPath by At
+	if got := counts["named.metricsItem"]; got != (LinkCounts{Hits: 1, Loads: 1}) {
+		t.Errorf("Unexpected counts for metricsItem %+v", got)
+	}
+
+	var decoded map[string]LinkCounts
+	if err := json.Unmarshal([]byte(c.String()), &decoded); err != nil || decoded["named.metricsSample"].Hits != 2 {
+		t.Errorf("Unexpected expvar output %s (%v)", c.String(), err)
+	}
+}
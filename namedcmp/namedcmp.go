@@ -0,0 +1,140 @@
+// Package namedcmp reports the differences found by github.com/google/go-cmp
+// using the names of the fields (as derived from a tag key) instead of Go
+// selectors, so a failing test prints
+//
+//	address.city: "X" != "Y"
+//
+// rather than {T}.Address.Value.City.Value.
+package namedcmp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alvarolm/named"
+	"github.com/google/go-cmp/cmp"
+)
+
+var sliceStringPtrType = reflect.TypeOf((*[]string)(nil))
+
+// isFieldType reports whether t is a named.Field or named.FieldSlice (or a
+// type with their layout), see the linker.
+func isFieldType(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Struct || t.NumField() < 3 {
+		return false
+	}
+	first := t.Field(0)
+	return first.Type == sliceStringPtrType && first.Name == "path"
+}
+
+// IgnoreLinks ignores the path information of Field and FieldSlice members,
+// so only their Value is compared (and cmp doesn't panic on their unexported
+// members), linked or not.
+func IgnoreLinks() cmp.Option {
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		sf, ok := p.Last().(cmp.StructField)
+		return ok && isFieldType(p.Index(-2).Type()) && sf.Name() != "Value"
+	}, cmp.Ignore())
+}
+
+// Reporter is a cmp.Reporter collecting the differences with named paths:
+//
+//	r := namedcmp.NewReporter("json")
+//	if !cmp.Equal(want, got, namedcmp.IgnoreLinks(), cmp.Reporter(r)) {
+//		t.Error(r)
+//	}
+type Reporter struct {
+	tagKey string
+	path   cmp.Path
+	diffs  []string
+}
+
+// NewReporter returns a Reporter naming fields after tagKey, or after the
+// named tag when present (see named.NamedTagKey).
+func NewReporter(tagKey string) *Reporter {
+	return &Reporter{tagKey: tagKey}
+}
+
+func (r *Reporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *Reporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	vx, vy := r.path.Last().Values()
+	r.diffs = append(r.diffs, fmt.Sprintf("%s: %s != %s", r.name(), formatValue(vx), formatValue(vy)))
+}
+
+func (r *Reporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// Diffs returns the differences reported so far, one per line.
+func (r *Reporter) Diffs() []string {
+	return r.diffs
+}
+
+// String returns the differences reported so far, one per line.
+func (r *Reporter) String() string {
+	return strings.Join(r.diffs, "\n")
+}
+
+// name renders the current path, skipping the Value member of fields.
+func (r *Reporter) name() string {
+	var names []string
+	for i, step := range r.path {
+		switch s := step.(type) {
+		case cmp.StructField:
+			parent := r.path[i-1].Type()
+			if isFieldType(parent) {
+				continue // Value
+			}
+			names = append(names, fieldName(parent.Field(s.Index()), r.tagKey))
+		case cmp.SliceIndex:
+			kx, ky := s.SplitKeys()
+			if kx == -1 {
+				kx = ky
+			}
+			names = append(names, fmt.Sprint(kx))
+		case cmp.MapIndex:
+			names = append(names, fmt.Sprint(s.Key()))
+		}
+	}
+
+	if len(names) == 0 {
+		return "{" + r.path.Index(0).Type().String() + "}"
+	}
+	return strings.Join(names, named.DefaultFullNameSeparator)
+}
+
+// fieldName returns the name of field as the linker does.
+func fieldName(field reflect.StructField, tagKey string) string {
+	if n := strings.Split(field.Tag.Get(named.NamedTagKey), ",")[0]; n != "" && n != "-" {
+		return n
+	}
+	if n := strings.Split(field.Tag.Get(tagKey), ",")[0]; n != "" && n != "-" {
+		return n
+	}
+	return field.Name
+}
+
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<missing>"
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+	return v.String()
+}
+
+// Diff returns the differences between x and y with named paths, one per line,
+// or an empty string if they are equal. IgnoreLinks is always applied.
+func Diff(x, y any, tagKey string, opts ...cmp.Option) string {
+	r := NewReporter(tagKey)
+	cmp.Equal(x, y, append(opts, IgnoreLinks(), cmp.Reporter(r))...)
+	return r.String()
+}
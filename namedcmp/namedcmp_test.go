@@ -0,0 +1,59 @@
+package namedcmp
+
+import (
+	"testing"
+
+	"github.com/alvarolm/named"
+	"github.com/google/go-cmp/cmp"
+)
+
+type address struct {
+	City named.Field[string] `json:"city"`
+}
+
+type user struct {
+	Email   named.Field[string]                `json:"email_address" named:"email"`
+	Age     named.Field[int]                   `json:"age"`
+	Address named.Field[address]               `json:"address"`
+	Tags    named.FieldSlice[[]string, string] `json:"tags"`
+	Note    string
+}
+
+func TestDiff(t *testing.T) {
+	named.LoadLink[user]("json")
+
+	x, y := user{}, user{}
+	x.Email.Value, y.Email.Value = "a", "a"
+	x.Age.Value, y.Age.Value = 1, 2
+	x.Address.Value.City.Value, y.Address.Value.City.Value = "X", "Y"
+	x.Tags.Value, y.Tags.Value = []string{"a"}, []string{"b"}
+	x.Note = "n"
+
+	named.Link(&y) // linking doesn't matter
+
+	expected := `age: 1 != 2
+address.city: "X" != "Y"
+tags.0: "a" != "b"
+Note: "n" != ""`
+	if diff := Diff(x, y, "json"); diff != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, diff)
+	}
+
+	y = x
+	if diff := Diff(x, y, "json"); diff != "" {
+		t.Errorf("Expected no differences, got:\n%s", diff)
+	}
+}
+
+func TestReporter(t *testing.T) {
+	x, y := user{}, user{}
+	x.Email.Value = "a"
+
+	r := NewReporter("json")
+	if cmp.Equal(x, y, IgnoreLinks(), cmp.Reporter(r)) {
+		t.Fatal("Expected a difference")
+	}
+	if diffs := r.Diffs(); len(diffs) != 1 || diffs[0] != `email: "a" != ""` {
+		t.Errorf("Unexpected differences %q", diffs)
+	}
+}
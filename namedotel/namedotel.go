@@ -0,0 +1,149 @@
+// Package namedotel converts types registered with named.LoadLink into
+// OpenTelemetry attributes, keeping the OpenTelemetry dependency out of the
+// named package:
+//
+//	span.SetAttributes(namedotel.Attrs(&order)...)
+package namedotel
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/alvarolm/named"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// zeroer is implemented by named.Field and named.FieldSlice.
+type zeroer interface {
+	IsZero() bool
+}
+
+// Attrs converts the non-zero Field members of s into OpenTelemetry
+// attributes keyed by their full path (e.g. "address.city").
+// Sensitive fields are reported with named.RedactedValue, their nested fields
+// included, and struct values holding nested fields are skipped as their
+// members are reported on their own.
+// Returns nil if T was not registered with named.LoadLink.
+func Attrs[T any](s *T) []attribute.KeyValue {
+	sch, ok := named.SchemaOf[T]()
+	if !ok {
+		return nil
+	}
+
+	ptr := unsafe.Pointer(s)
+
+	attrs := make([]attribute.KeyValue, 0, len(sch.Fields))
+	for i := 0; i < len(sch.Fields); i++ {
+		field := &sch.Fields[i]
+		member := reflect.NewAt(field.Type, unsafe.Add(ptr, field.Offset))
+		if member.Interface().(zeroer).IsZero() {
+			continue
+		}
+
+		key := attribute.Key(strings.Join(field.Path, "."))
+
+		if field.Sensitive {
+			attrs = append(attrs, key.String(named.RedactedValue))
+			i = subtreeEnd(sch.Fields, i) - 1 // the nested fields are sensitive too
+			continue
+		}
+
+		if attr, ok := otelAttr(key, member.Elem().Field(2)); ok { // Value is at index 2
+			attrs = append(attrs, attr)
+		}
+	}
+
+	return attrs
+}
+
+// subtreeEnd returns the index following the last nested field of fields[i].
+func subtreeEnd(fields []named.SchemaField, i int) int {
+	depth := len(fields[i].Path)
+	j := i + 1
+	for j < len(fields) && len(fields[j].Path) > depth {
+		j++
+	}
+	return j
+}
+
+// otelAttr returns the attribute of v: the basic kinds keep their type
+// (uint64 values beyond the range of int64 are reported as strings), the
+// other values implementing fmt.Stringer (pointer receivers included) are
+// reported as strings.
+func otelAttr(key attribute.Key, v reflect.Value) (attribute.KeyValue, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return key.String(v.String()), true
+	case reflect.Bool:
+		return key.Bool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return key.Int64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n > math.MaxInt64 {
+			return key.String(strconv.FormatUint(n, 10)), true
+		}
+		return key.Int64(int64(v.Uint())), true
+	case reflect.Float32, reflect.Float64:
+		return key.Float64(v.Float()), true
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return attribute.KeyValue{}, false
+		}
+		return otelAttr(key, v.Elem())
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return key.String(s.String()), true
+	}
+	if v.CanAddr() {
+		if s, ok := v.Addr().Interface().(fmt.Stringer); ok {
+			return key.String(s.String()), true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return otelSliceAttr(key, v)
+	case reflect.Struct, reflect.Map, reflect.Chan, reflect.Func:
+		return attribute.KeyValue{}, false
+	}
+	return key.String(fmt.Sprint(v.Interface())), true
+}
+
+func otelSliceAttr(key attribute.Key, v reflect.Value) (attribute.KeyValue, bool) {
+	n := v.Len()
+
+	switch v.Type().Elem().Kind() {
+	case reflect.String:
+		out := make([]string, n)
+		for i := range n {
+			out[i] = v.Index(i).String()
+		}
+		return key.StringSlice(out), true
+	case reflect.Bool:
+		out := make([]bool, n)
+		for i := range n {
+			out[i] = v.Index(i).Bool()
+		}
+		return key.BoolSlice(out), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		out := make([]int64, n)
+		for i := range n {
+			out[i] = v.Index(i).Int()
+		}
+		return key.Int64Slice(out), true
+	case reflect.Float32, reflect.Float64:
+		out := make([]float64, n)
+		for i := range n {
+			out[i] = v.Index(i).Float()
+		}
+		return key.Float64Slice(out), true
+	case reflect.Uint8:
+		return key.String(fmt.Sprintf("%x", v.Bytes())), true
+	}
+	return attribute.KeyValue{}, false
+}
@@ -0,0 +1,135 @@
+package namedotel
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alvarolm/named"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestAttrs(t *testing.T) {
+	type Inner struct {
+		City named.Field[string] `json:"city"`
+	}
+	type Traced struct {
+		ID       named.Field[int]                   `json:"id"`
+		Password named.Field[string]                `json:"password" sensitive:"true"`
+		Empty    named.Field[string]                `json:"empty"`
+		Tags     named.FieldSlice[[]string, string] `json:"tags"`
+		Address  named.Field[Inner]                 `json:"address"`
+	}
+
+	named.LoadLink[Traced]("json")
+
+	s := Traced{}
+	s.ID.Value = 7
+	s.Password.Value = "secret"
+	s.Tags.Value = []string{"a"}
+	s.Address.Value.City.Value = "x"
+
+	attrs := Attrs(&s)
+
+	expected := []attribute.KeyValue{
+		attribute.Int64("id", 7),
+		attribute.String("password", named.RedactedValue),
+		attribute.StringSlice("tags", []string{"a"}),
+		attribute.String("address.city", "x"),
+	}
+
+	if len(attrs) != len(expected) {
+		t.Fatalf("Expected %d attributes, got %d: %v", len(expected), len(attrs), attrs)
+	}
+	for i := range expected {
+		if attrs[i].Key != expected[i].Key || attrs[i].Value.Emit() != expected[i].Value.Emit() {
+			t.Errorf("Expected attribute %v, got %v", expected[i], attrs[i])
+		}
+	}
+}
+
+func TestAttrs_SensitiveParent(t *testing.T) {
+	type Card struct {
+		Number named.Field[string] `json:"number"`
+		Holder named.Field[string] `json:"holder"`
+	}
+	type Payment struct {
+		ID   named.Field[int]  `json:"id"`
+		Card named.Field[Card] `json:"card" sensitive:"true"`
+	}
+
+	named.LoadLink[Payment]("json")
+
+	s := Payment{}
+	s.ID.Value = 1
+	s.Card.Value.Number.Value = "4111111111111111"
+	s.Card.Value.Holder.Value = "Jane"
+
+	expected := []attribute.KeyValue{
+		attribute.Int64("id", 1),
+		attribute.String("card", named.RedactedValue),
+	}
+
+	attrs := Attrs(&s)
+	if len(attrs) != len(expected) {
+		t.Fatalf("Expected %d attributes, got %d: %v", len(expected), len(attrs), attrs)
+	}
+	for i := range expected {
+		if attrs[i] != expected[i] {
+			t.Errorf("Expected attribute %v, got %v", expected[i], attrs[i])
+		}
+	}
+}
+
+type sampleOTelLevel int
+
+func (l sampleOTelLevel) String() string { return "level-" + strconv.Itoa(int(l)) }
+
+type sampleOTelPoint struct{ X, Y int }
+
+func (p *sampleOTelPoint) String() string { return strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y) }
+
+func TestAttrs_Kinds(t *testing.T) {
+	type Traced struct {
+		Big     named.Field[uint64]          `json:"big"`
+		Small   named.Field[uint64]          `json:"small"`
+		Level   named.Field[sampleOTelLevel] `json:"level"`
+		Timeout named.Field[time.Duration]   `json:"timeout"`
+		At      named.Field[time.Time]       `json:"at"`
+		Point   named.Field[sampleOTelPoint] `json:"point"`
+		Ptr     named.Field[*int]            `json:"ptr"`
+	}
+
+	named.LoadLink[Traced]("json")
+
+	s := Traced{}
+	s.Big.Value = math.MaxUint64
+	s.Small.Value = 42
+	s.Level.Value = 3
+	s.Timeout.Value = time.Second
+	s.At.Value = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.Point.Value = sampleOTelPoint{1, 2}
+	n := 5
+	s.Ptr.Value = &n
+
+	expected := []attribute.KeyValue{
+		attribute.String("big", "18446744073709551615"),
+		attribute.Int64("small", 42),
+		attribute.Int64("level", 3),
+		attribute.Int64("timeout", int64(time.Second)),
+		attribute.String("at", s.At.Value.String()),
+		attribute.String("point", "1,2"),
+		attribute.Int64("ptr", 5),
+	}
+
+	attrs := Attrs(&s)
+	if len(attrs) != len(expected) {
+		t.Fatalf("Expected %d attributes, got %d: %v", len(expected), len(attrs), attrs)
+	}
+	for i := range expected {
+		if attrs[i] != expected[i] {
+			t.Errorf("Expected attribute %v, got %v", expected[i], attrs[i])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package namedtest provides test helpers for types using named: linking
+// assertions, golden-file snapshots of schemas, and checks of the accessors
+// emitted by generate-named against the runtime schemas.
+package namedtest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alvarolm/named"
+)
+
+var update = flag.Bool("named.update", false, "update the schema golden files of namedtest.SnapshotSchema")
+
+// Namer is implemented by linked named.Field and named.FieldSlice members.
+type Namer interface {
+	Name() string
+	FullName(separator string) string
+}
+
+// RequireLinked stops the test if s was not linked, listing the Field members
+// without a path (see named.VerifyLinked).
+func RequireLinked[T any](t testing.TB, s *T) {
+	t.Helper()
+	if missing := named.VerifyLinked(s); len(missing) > 0 {
+		t.Fatalf("%T is not linked: missing %s", s, strings.Join(missing, ", "))
+	}
+}
+
+// AssertName fails the test if the name of field is not name.
+func AssertName(t testing.TB, field Namer, name string) {
+	t.Helper()
+	if got := field.Name(); got != name {
+		t.Errorf("Expected name %q, got %q", name, got)
+	}
+}
+
+// AssertFullName fails the test if the full name of field (joined with
+// named.DefaultFullNameSeparator) is not fullName.
+func AssertFullName(t testing.TB, field Namer, fullName string) {
+	t.Helper()
+	if got := field.FullName(named.DefaultFullNameSeparator); got != fullName {
+		t.Errorf("Expected full name %q, got %q", fullName, got)
+	}
+}
+
+// SnapshotSchema compares the schema of T with the golden file
+// testdata/<type name>.schema.golden, failing the test on any difference so
+// schema changes (renamed tags, new fields) show up in review.
+// Run the tests with -named.update to write the golden file.
+func SnapshotSchema[T any](t testing.TB) {
+	t.Helper()
+
+	s, ok := named.SchemaOf[T]()
+	if !ok {
+		t.Fatalf("%s: %v", reflect.TypeFor[T](), named.ErrSchemaNotLoaded)
+	}
+	got := formatSchema(s)
+
+	golden := filepath.Join("testdata", reflect.TypeFor[T]().Name()+".schema.golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run with -named.update to create it)", err)
+	}
+	if string(want) != got {
+		t.Errorf("Schema of %s changed (run with -named.update to accept it):\n--- %s\n%s\n--- current\n%s",
+			reflect.TypeFor[T](), golden, want, got)
+	}
+}
+
+// formatSchema renders one line per field: its path, Go name, type and flags.
+func formatSchema(s named.Schema) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tag key: %s\n", s.TagKey)
+	for _, f := range s.Fields {
+		fmt.Fprintf(&b, "%s\t%s", strings.Join(f.Path, named.DefaultFullNameSeparator), f.GoName)
+		if f.Type != nil {
+			fmt.Fprintf(&b, "\t%s", f.Type)
+		}
+		if len(f.WirePath) > 0 && !slices.Equal(f.WirePath, f.Path) {
+			fmt.Fprintf(&b, "\twire=%s", strings.Join(f.WirePath, named.DefaultFullNameSeparator))
+		}
+		for _, flag := range []struct {
+			set  bool
+			name string
+		}{{f.Sensitive, "sensitive"}, {f.Quoted, "string"}, {f.OmitEmpty, "omitempty"}} {
+			if flag.set {
+				b.WriteString("\t" + flag.name)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// AssertGenerated fails the test if the accessors generated by generate-named
+// for T (e.g. the UserNamed variable) disagree with the runtime schema of T,
+// see named.VerifyGenerated.
+func AssertGenerated[T any](t testing.TB, accessors any) {
+	t.Helper()
+
+	var errs named.FieldErrors
+	switch err := named.VerifyGenerated[T](accessors); {
+	case errors.As(err, &errs):
+		for _, err := range errs {
+			t.Errorf("%s: %v", reflect.TypeFor[T](), err)
+		}
+	case err != nil:
+		t.Fatalf("%s: %v", reflect.TypeFor[T](), err)
+	}
+}
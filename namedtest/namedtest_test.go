@@ -0,0 +1,92 @@
+package namedtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alvarolm/named"
+)
+
+type address struct {
+	City named.Field[string] `json:"city"`
+}
+
+type Account struct {
+	Email    named.Field[string]  `json:"email_address" named:"email"`
+	Password named.Field[string]  `json:"password,omitempty" sensitive:"true"`
+	Address  named.Field[address] `json:"address"`
+}
+
+func init() {
+	named.LoadLink[Account]("json")
+}
+
+// recorder records the failures of a test helper.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	a := Account{}
+
+	r := &recorder{TB: t}
+	RequireLinked(r, &a)
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "Address.Value.City") {
+		t.Errorf("Expected an unlinked failure, got %q", r.failures)
+	}
+
+	named.Link(&a)
+	RequireLinked(t, &a)
+	AssertName(t, &a.Email, "email")
+	AssertFullName(t, &a.Address.Value.City, "address.city")
+
+	r = &recorder{TB: t}
+	AssertName(r, &a.Email, "email_address")
+	if len(r.failures) != 1 {
+		t.Errorf("Expected a name failure, got %q", r.failures)
+	}
+}
+
+func TestSnapshotSchema(t *testing.T) {
+	SnapshotSchema[Account](t)
+
+	expected := "tag key: json\n" +
+		"email\tEmail\tnamed.Field[string]\twire=email_address\n" +
+		"password\tPassword\tnamed.Field[string]\tsensitive\tomitempty\n" +
+		"address\tAddress\tnamed.Field[github.com/alvarolm/named/namedtest.address]\n" +
+		"address.city\tCity\tnamed.Field[string]\n"
+	s, _ := named.SchemaOf[Account]()
+	if got := formatSchema(s); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+type accountNamed struct{}
+
+func (accountNamed) Email() string    { return "email" }
+func (accountNamed) Password() string { return "pass" }
+func (accountNamed) Extra() string    { return "extra" }
+
+func TestAssertGenerated(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertGenerated[Account](r, accountNamed{})
+
+	expected := []string{
+		"namedtest.Account: Extra: named: generated accessor does not match the schema: no such field",
+		`namedtest.Account: Password: named: generated accessor does not match the schema: returns "pass" instead of "password"`,
+		`namedtest.Account: Address: named: generated accessor does not match the schema: no accessor for "address"`,
+	}
+	if strings.Join(r.failures, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(r.failures, "\n"))
+	}
+}
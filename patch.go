@@ -0,0 +1,96 @@
+package named
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"unsafe"
+)
+
+// DecodePatch unmarshals the JSON document read from r into s and returns the
+// full paths (joined with the separator of T, see FieldPaths) of the Field
+// members that were present in the payload, in schema order.
+// A field counts as present even when its value is an explicit null.
+// The result is also recorded for Presence.
+// T must be registered with LoadLink using the "json" tag key.
+func DecodePatch[T any](r io.Reader, s *T) (present []string, err error) {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil, ErrSchemaNotLoaded
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+
+	sep := sch.sep()
+	for _, path := range recordPresence(sch, unsafe.Pointer(s), data) {
+		present = append(present, strings.Join(path, sep))
+	}
+	return present, nil
+}
+
+// presentFields reports which fields of sch are found in the JSON document
+// data, by schema index. data must be a valid JSON document.
+func presentFields(sch *schema, data []byte) []bool {
+	present := make([]bool, len(sch.fields))
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		// not an object (e.g. null), nothing is present
+		return present
+	}
+
+	// decoded objects indexed by their joined path, so nested objects
+	// shared by many fields are decoded only once
+	objects := map[string]map[string]json.RawMessage{"": root}
+
+	for i, field := range sch.fields {
+		present[i] = jsonPathPresent(objects, field.wirePath)
+	}
+	return present
+}
+
+// jsonPathPresent reports whether path exists in the decoded objects tree,
+// decoding intermediate objects on demand.
+func jsonPathPresent(objects map[string]map[string]json.RawMessage, path []string) bool {
+	obj := objects[""]
+	for i, elem := range path {
+		raw, ok := jsonLookup(obj, elem)
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+
+		key := strings.Join(path[:i+1], DefaultFullNameSeparator)
+		next, ok := objects[key]
+		if !ok {
+			if err := json.Unmarshal(raw, &next); err != nil || next == nil {
+				return false
+			}
+			objects[key] = next
+		}
+		obj = next
+	}
+	return false
+}
+
+// jsonLookup finds key in obj, preferring an exact match but accepting a
+// case-insensitive one, like encoding/json does.
+func jsonLookup(obj map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	if raw, ok := obj[key]; ok {
+		return raw, true
+	}
+	for k, raw := range obj {
+		if strings.EqualFold(k, key) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
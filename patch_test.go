@@ -0,0 +1,53 @@
+package named
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodePatch(t *testing.T) {
+	type Address struct {
+		City Field[string] `json:"city"`
+		Zip  Field[string] `json:"zip"`
+	}
+	type Patch struct {
+		Name    Field[string]  `json:"name"`
+		Age     Field[int]     `json:"age"`
+		Address Field[Address] `json:"address"`
+	}
+
+	LoadLink[Patch]("json")
+
+	s := Patch{}
+	present, err := DecodePatch(strings.NewReader(`{"name":"bob","age":null,"address":{"city":"x"}}`), &s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"name", "age", "address", "address.city"}
+	if strings.Join(present, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected present %v, got %v", expected, present)
+	}
+
+	if s.Name.Value != "bob" {
+		t.Errorf("Expected Name to be 'bob', got '%s'", s.Name.Value)
+	}
+	if s.Address.Value.City.Value != "x" {
+		t.Errorf("Expected Address.City to be 'x', got '%s'", s.Address.Value.City.Value)
+	}
+
+	t.Run("NotLoaded", func(t *testing.T) {
+		type unknown struct {
+			A Field[int] `json:"a"`
+		}
+		if _, err := DecodePatch(strings.NewReader(`{}`), &unknown{}); err != ErrSchemaNotLoaded {
+			t.Errorf("Expected ErrSchemaNotLoaded, got %v", err)
+		}
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		if _, err := DecodePatch(strings.NewReader(`{`), &Patch{}); err == nil {
+			t.Error("Expected an error for invalid JSON")
+		}
+	})
+}
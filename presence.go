@@ -0,0 +1,102 @@
+package named
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// PresenceSet is the set of full paths (joined with the separator of the
+// struct type, see FieldPaths) that were present in a decoded JSON document, explicit nulls included.
+type PresenceSet map[string]struct{}
+
+// Has reports whether the field at path (e.g. "address.city") was present.
+func (p PresenceSet) Has(path string) bool {
+	_, ok := p[path]
+	return ok
+}
+
+// HasField reports whether the linked field f was present.
+func (p PresenceSet) HasField(f fielder) bool {
+	return p.Has(f.FullName(""))
+}
+
+// Presence returns the set of the fields of s that were present in the
+// document decoded into s by the last Decoder.Decode (or DecodePatch) call,
+// nil if none was, e.g. s was not decoded. The presence is recorded by the
+// fields themselves, so it is copied along with them.
+func Presence[T any](s *T) PresenceSet {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil
+	}
+
+	var set PresenceSet
+	for i := range sch.fields {
+		field := &sch.fields[i]
+		if present := field.present(unsafe.Pointer(s)); present != nil && *present {
+			if set == nil {
+				set = make(PresenceSet)
+			}
+			set[sch.fullName(field)] = struct{}{}
+		}
+	}
+	return set
+}
+
+// recordPresence sets the presence of the fields of the struct at ptr,
+// described by sch, to their presence in the JSON document data. Returns the
+// paths of the present fields, in schema order.
+func recordPresence(sch *schema, ptr unsafe.Pointer, data []byte) [][]string {
+	present := presentFields(sch, data)
+
+	var paths [][]string
+	for i := range sch.fields {
+		field := &sch.fields[i]
+		if flag := field.present(ptr); flag != nil {
+			*flag = present[i]
+		}
+		if present[i] {
+			paths = append(paths, *field.pathPtr)
+		}
+	}
+	return paths
+}
+
+// Decoder decodes JSON documents into linked structs, recording which fields
+// were present in the input. The record is retrieved with Presence.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next JSON document from its input into v, which must be a
+// pointer to a struct registered with LoadLink using the "json" tag key.
+func (d *Decoder) Decode(v any) error {
+	sch, ok := cachedSchemaMap[uintptr((*emptyInterface)(unsafe.Pointer(&v)).typ)]
+	if !ok {
+		return ErrSchemaNotLoaded
+	}
+
+	ptr := (*emptyInterface)(unsafe.Pointer(&v)).ptr
+	if ptr == nil {
+		return errors.New("named: Decode of nil pointer")
+	}
+
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return err
+	}
+
+	recordPresence(sch, ptr, raw)
+	return nil
+}
@@ -0,0 +1,100 @@
+package named
+
+import (
+	"strings"
+	"testing"
+)
+
+type samplePresenceInner struct {
+	City Field[string] `json:"city"`
+}
+
+type samplePresence struct {
+	Name    Field[string]              `json:"name"`
+	Age     Field[int]                 `json:"age"`
+	Address Field[samplePresenceInner] `json:"address"`
+}
+
+func init() {
+	LoadLink[samplePresence]("json")
+}
+
+func TestDecoder_Presence(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"name":null,"address":{"city":"x"}} {"age":3}`))
+
+	first := samplePresence{}
+	Link(&first)
+	if err := dec.Decode(&first); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p := Presence(&first)
+	if !p.Has("name") || !p.Has("address.city") || p.Has("age") {
+		t.Errorf("Unexpected presence set: %v", p)
+	}
+	if !p.HasField(&first.Address.Value.City) || p.HasField(&first.Age) {
+		t.Errorf("Unexpected HasField results for presence set: %v", p)
+	}
+
+	second := samplePresence{}
+	if err := dec.Decode(&second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p := Presence(&second); len(p) != 1 || !p.Has("age") {
+		t.Errorf("Unexpected presence set for the second document: %v", p)
+	}
+
+	if p := Presence(&samplePresence{}); p != nil {
+		t.Errorf("Expected no presence set for an undecoded struct, got %v", p)
+	}
+}
+
+func TestDecoder_NotLoaded(t *testing.T) {
+	type unknown struct {
+		A Field[int] `json:"a"`
+	}
+	if err := NewDecoder(strings.NewReader(`{}`)).Decode(&unknown{}); err != ErrSchemaNotLoaded {
+		t.Errorf("Expected ErrSchemaNotLoaded, got %v", err)
+	}
+}
+
+type samplePresenceBase struct {
+	ID Field[int] `json:"id"`
+}
+
+// samplePresenceOuter shares its address with its Base member
+type samplePresenceOuter struct {
+	Base samplePresenceBase `json:"base"`
+	Note Field[string]      `json:"note"`
+}
+
+func TestPresence_SharedAddress(t *testing.T) {
+	LoadLink[samplePresenceBase]("json")
+	LoadLink[samplePresenceOuter]("json")
+
+	var s samplePresenceOuter
+	Link(&s)
+	Link(&s.Base)
+	if _, err := DecodePatch(strings.NewReader(`{"note":"x"}`), &s); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p := Presence(&s.Base); p != nil {
+		t.Errorf("Expected no presence set for the undecoded member, got %v", p)
+	}
+
+	if _, err := DecodePatch(strings.NewReader(`{"id":1}`), &s.Base); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p := Presence(&s); len(p) != 1 || !p.Has("note") {
+		t.Errorf("Unexpected presence set of the struct: %v", p)
+	}
+	if p := Presence(&s.Base); len(p) != 1 || !p.Has("id") {
+		t.Errorf("Unexpected presence set of the member: %v", p)
+	}
+
+	// the presence is copied along with the fields
+	c := s
+	if p := Presence(&c); len(p) != 1 || !p.Has("note") {
+		t.Errorf("Unexpected presence set of the copy: %v", p)
+	}
+}
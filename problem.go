@@ -0,0 +1,98 @@
+package named
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document extended with an "errors"
+// member listing field level failures.
+type Problem struct {
+	Type     string         `json:"type,omitempty"`
+	Title    string         `json:"title,omitempty"`
+	Status   int            `json:"status,omitempty"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError describes the failure of a single field, located by a JSON Pointer (RFC 6901).
+type ProblemError struct {
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// NewProblem builds a Problem with the given status and title, with an
+// errors entry for each FieldError found in err (FieldErrors or *FieldError),
+// pointing to the member of the field in the JSON document: its WirePath,
+// or else its Path. Errors that are not tied to a field are reported in Detail.
+func NewProblem(status int, title string, err error) *Problem {
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+	}
+
+	if err == nil {
+		return p
+	}
+
+	var errs FieldErrors
+	var fe *FieldError
+	switch {
+	case errors.As(err, &errs):
+	case errors.As(err, &fe):
+		errs = FieldErrors{fe}
+	default:
+		p.Detail = err.Error()
+		return p
+	}
+
+	p.Errors = make([]ProblemError, len(errs))
+	for i, e := range errs {
+		path := e.WirePath
+		if len(path) == 0 {
+			path = e.Path
+		}
+		p.Errors[i] = ProblemError{
+			Pointer: JSONPointer(path),
+			Detail:  e.Err.Error(),
+		}
+	}
+
+	return p
+}
+
+// WriteProblem writes p as the response, using p.Status as the status code.
+func WriteProblem(w http.ResponseWriter, p *Problem) error {
+	status := p.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// JSONPointer returns the RFC 6901 JSON Pointer for path, e.g. ["address", "city"] -> "/address/city".
+func JSONPointer(path []string) string {
+	n := 0
+	for _, elem := range path {
+		n += len(elem) + 1
+	}
+
+	var b strings.Builder
+	b.Grow(n)
+	for _, elem := range path {
+		b.WriteByte('/')
+		jsonPointerEscaper.WriteString(&b, elem)
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package named
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestJSONPointer(t *testing.T) {
+	tests := []struct {
+		path     []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "/a"},
+		{[]string{"address", "city"}, "/address/city"},
+		{[]string{"a/b", "c~d"}, "/a~1b/c~0d"},
+	}
+
+	for _, tt := range tests {
+		if got := JSONPointer(tt.path); got != tt.expected {
+			t.Errorf("JSONPointer(%v): Expected %q, got %q", tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	errs := FieldErrors{
+		{Path: []string{"age"}, Err: errors.New("must be positive")},
+		{Path: []string{"address", "city"}, Err: errors.New("required")},
+	}
+
+	rec := httptest.NewRecorder()
+	if err := WriteProblem(rec, NewProblem(http.StatusUnprocessableEntity, "Validation failed", errs)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Expected content type %q, got %q", ProblemContentType, ct)
+	}
+
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Unexpected error decoding body: %v", err)
+	}
+
+	if len(p.Errors) != 2 || p.Errors[0].Pointer != "/age" || p.Errors[1].Pointer != "/address/city" || p.Errors[1].Detail != "required" {
+		t.Errorf("Unexpected problem errors: %+v", p.Errors)
+	}
+
+	t.Run("NonFieldError", func(t *testing.T) {
+		p := NewProblem(http.StatusBadRequest, "Bad request", errors.New("boom"))
+		if p.Detail != "boom" || len(p.Errors) != 0 {
+			t.Errorf("Expected detail 'boom' without errors, got %+v", p)
+		}
+	})
+}
+
+type problemRenamed struct {
+	Email Field[string] `json:"email_address" named:"email"`
+	Age   Field[int]    `json:"age"`
+}
+
+func TestNewProblem_WirePath(t *testing.T) {
+	if err := LoadLink[problemRenamed]("json"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var s problemRenamed
+	err := FromMap(map[string]any{"email_address": 1, "age": "x"}, &s, "json")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	// FromMap reports the errors in the iteration order of the map
+	p := NewProblem(http.StatusBadRequest, "Bad request", err)
+	pointers := make([]string, len(p.Errors))
+	for i, e := range p.Errors {
+		pointers[i] = e.Pointer
+	}
+	slices.Sort(pointers)
+	if !slices.Equal(pointers, []string{"/age", "/email_address"}) {
+		t.Errorf("Unexpected problem errors: %+v", p.Errors)
+	}
+
+	// the logical path is kept by the error
+	var errs FieldErrors
+	if !errors.As(err, &errs) || !slices.ContainsFunc(errs, func(e *FieldError) bool { return e.Path[0] == "email" }) {
+		t.Errorf("Unexpected errors %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package named
+
+import "unsafe"
+
+// Project returns a new linked instance of T where only the Field members of
+// src at paths (full paths such as "address.city") are populated, selecting a
+// field copies its whole value. Unknown paths are ignored.
+// Values are copied through the schema offsets, without any encoding round trip,
+// the copy being linked on its own (not under the parent path of src, see LinkWithPath).
+// Returns nil if T was not registered with LoadLink.
+func Project[T any](src *T, paths ...string) *T {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return nil
+	}
+
+	ps := sch.pathSelector(paths, true)
+
+	dst := new(T)
+	srcPtr, dstPtr := unsafe.Pointer(src), unsafe.Pointer(dst)
+
+	for i := 0; i < len(sch.fields); {
+		field := &sch.fields[i]
+
+		switch ps.mode(field) {
+		case selectFull:
+			field.value(dstPtr).Set(field.value(srcPtr))
+			// nested fields were copied along, with the parent paths of src
+			end := sch.subtreeEnd(i)
+			for j := i + 1; j < end; j++ {
+				(*fieldHeader)(unsafe.Add(dstPtr, sch.fields[j].offset)).parentPath = nil
+			}
+			i = end
+		case selectPartial:
+			i++ // look into the nested fields
+		default:
+			i = sch.subtreeEnd(i)
+		}
+	}
+
+	Link(dst)
+	return dst
+}
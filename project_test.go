@@ -0,0 +1,59 @@
+package named
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	src := newSampleMarshal()
+
+	p := Project(src, "name", "address.zip")
+	if p == nil {
+		t.Fatal("Expected a projection")
+	}
+
+	if p.Name.Value != "bob" || p.Address.Value.Zip.Value != "123" {
+		t.Errorf("Expected selected fields to be copied, got %+v", p)
+	}
+	if p.ID.Value != 0 || p.Address.Value.City.Value != "" {
+		t.Errorf("Expected other fields to be empty, got %+v", p)
+	}
+	if p.Address.Value.Zip.FullName("") != "address.zip" {
+		t.Errorf("Expected projection to be linked, got '%s'", p.Address.Value.Zip.FullName(""))
+	}
+
+	whole := Project(src, "address")
+	if whole.Address.Value.City.Value != "x" || whole.Address.Value.Zip.Value != "123" || whole.Name.Value != "" {
+		t.Errorf("Expected the whole address to be copied, got %+v", whole)
+	}
+
+	// src linked under a parent path, e.g. an element of a FieldSlice
+	parent := []string{"items", "0"}
+	LinkWithPath(src, &parent)
+	if got := src.Address.Value.City.FullName(""); got != "items.0.address.city" {
+		t.Fatalf("Expected src to be linked under its parent path, got '%s'", got)
+	}
+	nested := Project(src, "address")
+	if got := nested.Address.Value.City.Path(); !slices.Equal(got, []string{"address", "city"}) {
+		t.Errorf("Expected the nested field of the projection to be linked on its own, got %v", got)
+	}
+	if got := src.Address.Value.City.FullName(""); got != "items.0.address.city" {
+		t.Errorf("Expected src to be left as is, got '%s'", got)
+	}
+
+	type unknown struct {
+		A Field[int] `json:"a"`
+	}
+	if Project(&unknown{}, "a") != nil {
+		t.Error("Expected nil for a type not registered with LoadLink")
+	}
+}
+
+func BenchmarkProject(b *testing.B) {
+	src := newSampleMarshal()
+	b.ReportAllocs()
+	for b.Loop() {
+		Project(src, "name", "address.city")
+	}
+}
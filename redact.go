@@ -0,0 +1,12 @@
+package named
+
+// SensitiveTagKey is the struct tag key marking a field as sensitive:
+//
+//	Password Field[string] `json:"password" sensitive:"true"`
+//
+// Sensitive values are replaced with RedactedValue wherever the package
+// exports field values for observability purposes.
+const SensitiveTagKey = "sensitive"
+
+// RedactedValue replaces the value of sensitive fields.
+const RedactedValue = "[REDACTED]"
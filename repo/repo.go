@@ -0,0 +1,143 @@
+// Package repo is a tiny generic repository layer deriving table and column
+// names from the schemas of types registered with named.LoadLink (typically
+// using the "db" tag key), for services that don't need a full ORM.
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/alvarolm/named"
+	"github.com/alvarolm/named/sqlutil"
+)
+
+// ErrUnknownField is returned when the field used as a filter does not belong to the table.
+var ErrUnknownField = errors.New("repo: field is not a column of the table")
+
+// Querier is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// TableNamer can be implemented by a type to set its table name.
+type TableNamer interface {
+	TableName() string
+}
+
+// Namer is implemented by linked named.Field and named.FieldSlice members.
+type Namer interface {
+	Name() string
+}
+
+// TableName returns the table of T: the result of its TableName method if it
+// implements TableNamer, otherwise its type name in snake_case with an "s"
+// appended (User -> users, OrderItem -> order_items).
+func TableName[T any]() string {
+	var zero T
+	if tn, ok := any(&zero).(TableNamer); ok {
+		return tn.TableName()
+	}
+	return snakeCase(reflect.TypeOf(zero).Name()) + "s"
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// start a new word, keeping acronyms (ID, HTTP) together
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FindBy loads into dst the first row of the table of T whose column for field
+// equals value. field is a top level member of any linked T, e.g. &u.Email,
+// ErrUnknownField is returned otherwise.
+// Only top level fields that don't hold nested fields are selected.
+// Returns sql.ErrNoRows if there is no such row.
+func FindBy[T any](ctx context.Context, db Querier, dst *T, field Namer, value any, opts ...sqlutil.Option) error {
+	columns, dests, err := scanTargets(dst)
+	if err != nil {
+		return err
+	}
+
+	where, args, err := whereField[T](field, value, opts)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s LIMIT 1", strings.Join(columns, ", "), TableName[T](), where)
+	return db.QueryRowContext(ctx, query, args...).Scan(dests...)
+}
+
+// ExistsBy reports whether the table of T has a row whose column for field
+// equals value. dst is only used to infer T and is left untouched.
+func ExistsBy[T any](ctx context.Context, db Querier, dst *T, field Namer, value any, opts ...sqlutil.Option) (bool, error) {
+	where, args, err := whereField[T](field, value, opts)
+	if err != nil {
+		return false, err
+	}
+
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE %s LIMIT 1", TableName[T](), where)
+
+	var one int
+	err = db.QueryRowContext(ctx, query, args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func whereField[T any](field Namer, value any, opts []sqlutil.Option) (string, []any, error) {
+	f, ok := named.FieldOf[T](field)
+	switch {
+	case !ok:
+		return "", nil, fmt.Errorf("%w: %q is not a linked member of %s", ErrUnknownField, field.Name(), reflect.TypeFor[T]())
+	case len(f.Path) > 1:
+		return "", nil, fmt.Errorf("%w: %q is nested", ErrUnknownField, strings.Join(f.Path, named.DefaultFullNameSeparator))
+	}
+
+	// the named tag may rename the column
+	column := f.WirePath[0]
+	where, args, err := sqlutil.Where[T](map[string]any{column: value}, opts...)
+	if errors.Is(err, sqlutil.ErrUnknownColumn) {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnknownField, field.Name())
+	}
+	return where, args, err
+}
+
+// scanTargets returns the selectable columns of T and the matching scan destinations in dst.
+func scanTargets[T any](dst *T) ([]string, []any, error) {
+	paths := sqlutil.Columns[T]()
+	if paths == nil {
+		return nil, nil, named.ErrSchemaNotLoaded
+	}
+	values := named.FieldValues(dst)
+
+	var (
+		columns []string
+		dests   []any
+	)
+	for i, p := range paths {
+		if strings.Contains(p, named.DefaultFullNameSeparator) {
+			continue // nested
+		}
+		if i+1 < len(paths) && strings.HasPrefix(paths[i+1], p+named.DefaultFullNameSeparator) {
+			continue // holds nested fields
+		}
+		columns = append(columns, p)
+		dests = append(dests, values[i])
+	}
+	return columns, dests, nil
+}
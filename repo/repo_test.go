@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/alvarolm/named"
+	"github.com/alvarolm/named/sqlutil"
+)
+
+type Address struct {
+	City named.Field[string] `db:"city"`
+}
+
+type User struct {
+	ID      named.Field[int64]   `db:"user_id"`
+	Email   named.Field[string]  `db:"email"`
+	Address named.Field[Address] `db:"address"`
+}
+
+type OrderItem struct {
+	SKU named.Field[string] `db:"sku"`
+}
+
+func (OrderItem) TableName() string { return "items" }
+
+func init() {
+	named.LoadLink[User]("db")
+	named.LoadLink[OrderItem]("db")
+}
+
+// fakeDriver records the last query and answers with its rows.
+type fakeDriver struct {
+	query string
+	args  []driver.NamedValue
+	rows  [][]driver.Value
+	cols  []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *fakeDriver) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (d *fakeDriver) Close() error              { return nil }
+func (d *fakeDriver) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (d *fakeDriver) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	d.query, d.args = query, args
+	return &fakeRows{cols: d.cols, rows: d.rows}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openFake(t *testing.T, d *fakeDriver) *sql.DB {
+	name := "fake-" + t.Name()
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("Unexpected error opening fake db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTableName(t *testing.T) {
+	if got := TableName[User](); got != "users" {
+		t.Errorf("Expected 'users', got %q", got)
+	}
+	if got := TableName[OrderItem](); got != "items" {
+		t.Errorf("Expected TableName method to win, got %q", got)
+	}
+	for in, expected := range map[string]string{"UserID": "user_id", "HTTPServer": "http_server", "ID": "id"} {
+		if got := snakeCase(in); got != expected {
+			t.Errorf("snakeCase(%q): Expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestFindBy(t *testing.T) {
+	d := &fakeDriver{cols: []string{"user_id", "email"}, rows: [][]driver.Value{{int64(7), "a@b.c"}}}
+	db := openFake(t, d)
+
+	var ref User
+	named.Link(&ref)
+
+	u := User{}
+	if err := FindBy(context.Background(), db, &u, &ref.Email, "a@b.c", sqlutil.WithPlaceholder(sqlutil.Dollar)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedQuery := "SELECT user_id, email FROM users WHERE email = $1 LIMIT 1"
+	if d.query != expectedQuery {
+		t.Errorf("Expected query %q, got %q", expectedQuery, d.query)
+	}
+	if len(d.args) != 1 || !reflect.DeepEqual(d.args[0].Value, "a@b.c") {
+		t.Errorf("Unexpected args %v", d.args)
+	}
+	if u.ID.Value != 7 || u.Email.Value != "a@b.c" {
+		t.Errorf("Unexpected scanned user %+v", u)
+	}
+
+	var item OrderItem
+	named.Link(&item)
+	if err := FindBy(context.Background(), db, &u, &item.SKU, "x"); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected ErrUnknownField, got %v", err)
+	}
+
+	// not a top level column
+	if err := FindBy(context.Background(), db, &u, &ref.Address.Value.City, "x"); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected ErrUnknownField, got %v", err)
+	}
+
+	// not linked
+	if err := FindBy(context.Background(), db, &u, &u.Email, "x"); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected ErrUnknownField, got %v", err)
+	}
+}
+
+func TestExistsBy(t *testing.T) {
+	d := &fakeDriver{cols: []string{"1"}}
+	db := openFake(t, d)
+
+	var ref User
+	named.Link(&ref)
+
+	ok, err := ExistsBy(context.Background(), db, &User{}, &ref.ID, 1)
+	if err != nil || ok {
+		t.Errorf("Expected no row, got %v (%v)", ok, err)
+	}
+	if expected := "SELECT 1 FROM users WHERE user_id = ? LIMIT 1"; d.query != expected {
+		t.Errorf("Expected query %q, got %q", expected, d.query)
+	}
+
+	d.rows = [][]driver.Value{{int64(1)}}
+	if ok, err := ExistsBy(context.Background(), db, &User{}, &ref.ID, 1); err != nil || !ok {
+		t.Errorf("Expected a row, got %v (%v)", ok, err)
+	}
+}
@@ -0,0 +1,262 @@
+package named
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// Schema is the public representation of the schema of a type: the Field
+// members found by LoadLink, in linking order. It can be inspected with
+// SchemaOf or installed with RegisterSchema, e.g. by generated code.
+type Schema struct {
+	TagKey string
+	Fields []SchemaField
+}
+
+// SchemaField describes a Field (or FieldSlice) member of a type.
+type SchemaField struct {
+	// Path is the full path of the field, e.g. ["address", "city"].
+	Path []string
+	// WirePath is the path made of the names of the tag key, used by the
+	// encoding functions (MarshalOnly, ToMap, BindForm, ...). It differs from
+	// Path when the named tag overrides a name; empty means Path.
+	WirePath []string
+	// Offset is the absolute offset of the member within the type,
+	// nested members included (see unsafe.Offsetof).
+	Offset uintptr
+	// GoName is the Go name of the struct member.
+	GoName string
+	// Type is the Field[T] or FieldSlice[T, E] type of the member.
+	// Optional for RegisterSchema, which then takes the type of the Field
+	// member of T at Offset.
+	Type reflect.Type
+	// Sensitive marks the field as sensitive, see SensitiveTagKey.
+	Sensitive bool
+	// Quoted records the json ",string" tag option. As with encoding/json,
+	// it only applies to bool, number and string values without marshaler.
+	Quoted bool
+	// OmitEmpty records the json "omitempty" or "omitzero" tag options.
+	OmitEmpty bool
+}
+
+// ErrInvalidSchema is returned by RegisterSchema for malformed schemas.
+var ErrInvalidSchema = errors.New("named: invalid schema")
+
+// SchemaOf returns the schema used by Link for T, false if T was not registered.
+func SchemaOf[T any]() (Schema, bool) {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return Schema{}, false
+	}
+	return sch.public(), true
+}
+
+func (sch *schema) public() Schema {
+	out := Schema{
+		TagKey: sch.TagKey,
+		Fields: make([]SchemaField, len(sch.fields)),
+	}
+	for i := range sch.fields {
+		out.Fields[i] = sch.fields[i].public()
+	}
+	return out
+}
+
+func (f *fieldInfo) public() SchemaField {
+	return SchemaField{
+		Path:      slices.Clone(*f.pathPtr),
+		WirePath:  slices.Clone(f.wirePath),
+		Offset:    f.offset,
+		GoName:    f.goName,
+		Type:      f.typ,
+		Sensitive: f.sensitive,
+		Quoted:    pathQuoted(f.pathPtr),
+		OmitEmpty: f.omitEmpty,
+	}
+}
+
+// FieldOf returns the schema field of field, a pointer to a Field (or
+// FieldSlice) member of a linked T, e.g. &u.Address.Value.City, false if
+// field is not linked or is a member of another type.
+func FieldOf[T any](field any) (SchemaField, bool) {
+	t := reflect.TypeOf(field)
+	if t == nil || t.Kind() != reflect.Pointer || !isFieldType(t.Elem()) || reflect.ValueOf(field).IsNil() {
+		return SchemaField{}, false
+	}
+
+	path := (*fieldHeader)(reflect.ValueOf(field).UnsafePointer()).path
+	sch := pathSchema(path)
+	if sch == nil || sch.goType != reflect.TypeFor[T]() {
+		return SchemaField{}, false
+	}
+	for i := range sch.fields {
+		if sch.fields[i].pathPtr == path {
+			return sch.fields[i].public(), true
+		}
+	}
+	return SchemaField{}, false
+}
+
+// RegisterSchema installs s as the schema of T, as LoadLink does, without
+// collecting the fields of T through reflection. Nested fields must follow the
+// field holding them and offsets must be exact: Link writes at those offsets,
+// so they are checked against the layout of T.
+// Returns an error wrapping ErrInvalidSchema if paths are empty, duplicated
+// (wire paths included) or not ordered, if an offset is out of the bounds of T, or if a field Type
+// is not a Field type or not the type of the member of T at its offset (without
+// Type, if there is no Field member at its offset).
+// not async safe, should be called before any Link calls.
+func RegisterSchema[T any](s Schema, opts ...LinkOption) error {
+	sch := &schema{
+		TagKey: s.TagKey,
+		goType: reflect.TypeFor[T](),
+		fields: make([]fieldInfo, 0, len(s.Fields)),
+	}
+	for _, opt := range opts {
+		opt(sch)
+	}
+
+	seen := make(map[string]bool, len(s.Fields))
+	seenWire := make(map[string]bool, len(s.Fields))
+	var parents [][]string // path of the parents of the current field
+
+	for _, f := range s.Fields {
+		if len(f.Path) == 0 {
+			return fmt.Errorf("%w: field %s has an empty path", ErrInvalidSchema, f.GoName)
+		}
+
+		fullName := strings.Join(f.Path, DefaultFullNameSeparator)
+		if seen[fullName] {
+			return fmt.Errorf("%w: %w: %q", ErrInvalidSchema, ErrDuplicateName, fullName)
+		}
+		seen[fullName] = true
+
+		// the parent must be the last field seen at the previous depth
+		depth := len(f.Path) - 1
+		if depth > len(parents) || depth > 0 && !slices.Equal(parents[depth-1], f.Path[:depth]) {
+			return fmt.Errorf("%w: field %q does not follow its parent", ErrInvalidSchema, fullName)
+		}
+		parents = append(parents[:depth], f.Path)
+
+		if f.Offset+unsafe.Sizeof(fieldHeader{}) > sch.goType.Size() {
+			return fmt.Errorf("%w: field %q at offset %d is out of the bounds of %s", ErrInvalidSchema, fullName, f.Offset, sch.goType)
+		}
+		if f.Type == nil {
+			// the values are read and written through the type
+			if f.Type = fieldTypeAt(sch.goType, f.Offset); f.Type == nil {
+				return fmt.Errorf("%w: field %q at offset %d is not a Field member of %s", ErrInvalidSchema, fullName, f.Offset, sch.goType)
+			}
+		} else if !isFieldType(f.Type) || !hasTypeAt(sch.goType, f.Offset, f.Type) {
+			return fmt.Errorf("%w: field %q at offset %d is not a %s of %s", ErrInvalidSchema, fullName, f.Offset, f.Type, sch.goType)
+		}
+
+		wirePath := f.WirePath
+		if len(f.WirePath) == 0 {
+			wirePath = f.Path
+		} else if len(f.WirePath) != len(f.Path) {
+			return fmt.Errorf("%w: field %q has a wire path of a different depth", ErrInvalidSchema, fullName)
+		}
+		wireName := strings.Join(wirePath, DefaultFullNameSeparator)
+		if seenWire[wireName] {
+			return fmt.Errorf("%w: %w: wire path %q", ErrInvalidSchema, ErrDuplicateName, wireName)
+		}
+		seenWire[wireName] = true
+
+		pathPtr := &(&fieldPath{
+			names:  slices.Clone(f.Path),
+			sch:    sch,
+			quoted: f.Quoted && quotable(f.Type.Field(2).Type),
+		}).names
+
+		sch.fields = append(sch.fields, fieldInfo{
+			pathPtr:   pathPtr,
+			offset:    f.Offset,
+			fullName:  fullName,
+			wirePath:  slices.Clone(wirePath),
+			goName:    f.GoName,
+			typ:       f.Type,
+			sensitive: f.Sensitive,
+			omitEmpty: f.OmitEmpty,
+		})
+	}
+
+	cacheSchema(typeIDOf[T](), sch)
+	onLoad(sch)
+
+	return nil
+}
+
+// hasTypeAt reports whether t is, or holds a member of, type typ at offset,
+// members of nested structs included.
+func hasTypeAt(t reflect.Type, offset uintptr, typ reflect.Type) bool {
+	if offset == 0 && t == typ {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if offset >= field.Offset && offset < field.Offset+field.Type.Size() && hasTypeAt(field.Type, offset-field.Offset, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldTypeAt returns the Field type of t, or of a member of t, at offset,
+// members of nested structs included, nil if there is none. A Field type
+// starts with its path pointer, so there is at most one.
+func fieldTypeAt(t reflect.Type, offset uintptr) reflect.Type {
+	if offset == 0 && isFieldType(t) {
+		return t
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if offset >= field.Offset && offset < field.Offset+field.Type.Size() {
+			return fieldTypeAt(field.Type, offset-field.Offset)
+		}
+	}
+	return nil
+}
+
+// SchemaInfo summarizes a schema of the registry, see ListSchemas.
+type SchemaInfo struct {
+	Type     reflect.Type
+	TypeName string // package qualified, e.g. "api.User"
+	TagKey   string
+	Fields   int
+	// Active reports whether Link uses this schema, the last one loaded for the type.
+	Active bool
+}
+
+// ListSchemas returns every schema loaded with LoadLink or RegisterSchema,
+// sorted by type name then tag key, e.g. to check at startup that every
+// expected type was registered.
+func ListSchemas() []SchemaInfo {
+	var infos []SchemaInfo
+	for typeID, schemas := range cachedTagSchemaMap {
+		for _, sch := range schemas {
+			infos = append(infos, SchemaInfo{
+				Type:     sch.goType,
+				TypeName: sch.goType.String(),
+				TagKey:   sch.TagKey,
+				Fields:   len(sch.fields),
+				Active:   cachedSchemaMap[typeID] == sch,
+			})
+		}
+	}
+
+	slices.SortFunc(infos, func(a, b SchemaInfo) int {
+		return cmp.Or(strings.Compare(a.TypeName, b.TypeName), strings.Compare(a.TagKey, b.TagKey))
+	})
+	return infos
+}
@@ -0,0 +1,151 @@
+package named
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+type sampleRegisterInner struct {
+	City Field[string]
+}
+
+type sampleRegister struct {
+	ID      Field[int]
+	Address Field[sampleRegisterInner]
+}
+
+func TestRegisterSchema(t *testing.T) {
+	var zero sampleRegister
+
+	err := RegisterSchema[sampleRegister](Schema{
+		TagKey: "json",
+		Fields: []SchemaField{
+			{Path: []string{"id"}, Offset: unsafe.Offsetof(zero.ID), GoName: "ID", Type: reflect.TypeFor[Field[int]]()},
+			{Path: []string{"address"}, Offset: unsafe.Offsetof(zero.Address), GoName: "Address"},
+			{Path: []string{"address", "city"}, Offset: unsafe.Offsetof(zero.Address) + unsafe.Offsetof(zero.Address.Value) + unsafe.Offsetof(zero.Address.Value.City), GoName: "City", Type: reflect.TypeFor[Field[string]]()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s := sampleRegister{}
+	if !Link(&s) {
+		t.Fatal("Link failed")
+	}
+
+	if s.ID.Name() != "id" || s.Address.Value.City.FullName("") != "address.city" {
+		t.Errorf("Unexpected names %q and %q", s.ID.Name(), s.Address.Value.City.FullName(""))
+	}
+
+	sch, ok := SchemaOf[sampleRegister]()
+	if !ok || len(sch.Fields) != 3 || sch.Fields[2].GoName != "City" {
+		t.Errorf("Unexpected schema %+v", sch)
+	}
+
+	// the type of the address, not given, is the one of the member
+	if sch.Fields[1].Type != reflect.TypeFor[Field[sampleRegisterInner]]() {
+		t.Errorf("Expected the type of the member, got %v", sch.Fields[1].Type)
+	}
+	s.Address.Value.City.Value = "x"
+	if m, err := ToMap(&s, "json"); err != nil || m["address"].(map[string]any)["city"] != "x" {
+		t.Errorf("Unexpected map %v, %v", m, err)
+	}
+}
+
+func TestRegisterSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []SchemaField
+	}{
+		{"EmptyPath", []SchemaField{{GoName: "ID"}}},
+		{"Duplicate", []SchemaField{{Path: []string{"a"}}, {Path: []string{"a"}}}},
+		{"DuplicateWire", []SchemaField{{Path: []string{"a"}, WirePath: []string{"x"}}, {Path: []string{"b"}, WirePath: []string{"x"}}}},
+		{"DuplicateWireDefault", []SchemaField{{Path: []string{"a"}}, {Path: []string{"b"}, WirePath: []string{"a"}}}},
+		{"Orphan", []SchemaField{{Path: []string{"a"}}, {Path: []string{"b", "c"}}}},
+		{"TooDeep", []SchemaField{{Path: []string{"a", "b", "c"}}}},
+		{"OutOfBounds", []SchemaField{{Path: []string{"a"}, Offset: unsafe.Sizeof(sampleRegister{})}}},
+		{"HeaderOutOfBounds", []SchemaField{{Path: []string{"a"}, Offset: unsafe.Sizeof(sampleRegister{}) - 1}}},
+		{"WrongType", []SchemaField{{Path: []string{"id"}, Offset: unsafe.Offsetof(sampleRegister{}.ID), Type: reflect.TypeFor[Field[string]]()}}},
+		{"WrongOffset", []SchemaField{{Path: []string{"id"}, Offset: unsafe.Offsetof(sampleRegister{}.Address), Type: reflect.TypeFor[Field[int]]()}}},
+		{"NoField", []SchemaField{{Path: []string{"id"}, Offset: unsafe.Offsetof(sampleRegister{}.ID.parentPath)}}},
+		{"NotField", []SchemaField{{Path: []string{"id"}, Offset: unsafe.Offsetof(sampleRegister{}.ID), Type: reflect.TypeFor[int]()}}},
+	}
+
+	for _, tt := range tests {
+		if err := RegisterSchema[sampleRegister](Schema{Fields: tt.fields}); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%s: Expected ErrInvalidSchema, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestSchemaOf_MatchesLoadLink(t *testing.T) {
+	sch, ok := SchemaOf[SampleEmbedStruct]()
+	if !ok {
+		t.Fatal("Expected SampleEmbedStruct to be registered")
+	}
+
+	// installing the schema read back must produce the same links
+	type copyOfEmbed SampleEmbedStruct
+	if err := RegisterSchema[copyOfEmbed](sch); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	a, b := SampleEmbedStruct{}, copyOfEmbed{}
+	Link(&a)
+	Link(&b)
+	if a.B.Value.J.FullName("") != b.B.Value.J.FullName("") || b.B.Value.J.FullName("") != "y.J" {
+		t.Errorf("Expected equal links, got %q and %q", a.B.Value.J.FullName(""), b.B.Value.J.FullName(""))
+	}
+}
+
+type listSchemasSample struct {
+	A Field[int] `json:"a" db:"col_a"`
+	B Field[int] `json:"b" db:"col_b"`
+}
+
+func TestListSchemas(t *testing.T) {
+	LoadLink[listSchemasSample]("db")
+	LoadLink[listSchemasSample]("json")
+
+	var found []SchemaInfo
+	for _, info := range ListSchemas() {
+		if info.Type == reflect.TypeFor[listSchemasSample]() {
+			found = append(found, info)
+		}
+	}
+
+	if len(found) != 2 {
+		t.Fatalf("Expected 2 schemas, got %+v", found)
+	}
+	if found[0].TagKey != "db" || found[0].Active || found[1].TagKey != "json" || !found[1].Active {
+		t.Errorf("Unexpected schemas %+v", found)
+	}
+	if found[0].TypeName != "named.listSchemasSample" || found[0].Fields != 2 {
+		t.Errorf("Unexpected schema %+v", found[0])
+	}
+}
+
+func TestFieldOf(t *testing.T) {
+	if err := LoadLink[sampleRegister]("json"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s := sampleRegister{}
+	if _, ok := FieldOf[sampleRegister](&s.ID); ok {
+		t.Error("Expected an unlinked field not to be found")
+	}
+
+	Link(&s)
+	if f, ok := FieldOf[sampleRegister](&s.Address.Value.City); !ok || f.GoName != "City" || len(f.Path) != 2 {
+		t.Errorf("Unexpected field %+v (%v)", f, ok)
+	}
+	if _, ok := FieldOf[SampleSimple](&s.ID); ok {
+		t.Error("Expected a field of another type not to be found")
+	}
+	if _, ok := FieldOf[sampleRegister](&s); ok {
+		t.Error("Expected a struct not to be found")
+	}
+}
@@ -0,0 +1,143 @@
+// Package spannerutil builds Cloud Spanner mutation arguments from types
+// registered with named.LoadLink using the "spanner" tag key, without
+// depending on the Spanner client:
+//
+//	table, columns, values, err := spannerutil.Mutation(&singer)
+//	m := spanner.InsertOrUpdate(table, columns, values)
+//
+// Spanner tables cannot have STRUCT columns: fields holding nested fields are
+// left out of Columns and rejected by Mutation. Their names remain useful to
+// build queries, e.g. reading the fields of STRUCT query parameters (see
+// StructFields), and are generated as constants by generate-named with the
+// StructFields option:
+//
+//	// GENERATE-NAMED TagKey:spanner,StructFields:true
+//	q := "SELECT SingerId FROM Singers WHERE City = @address." + SingerStruct_Address_City
+package spannerutil
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/alvarolm/named"
+)
+
+// TagKey is the tag key used by the Spanner client.
+const TagKey = "spanner"
+
+// ErrStructColumn is returned by Mutation for fields holding nested fields,
+// which are not Spanner columns.
+var ErrStructColumn = errors.New("spannerutil: Spanner tables cannot have STRUCT columns")
+
+// TableNamer can be implemented by a type to set its table name.
+type TableNamer interface {
+	TableName() string
+}
+
+// TableName returns the table of T: the result of its TableName method if it
+// implements TableNamer, otherwise its type name (Spanner tables are usually
+// named like Go types, e.g. Singers).
+func TableName[T any]() string {
+	var zero T
+	if tn, ok := any(&zero).(TableNamer); ok {
+		return tn.TableName()
+	}
+	return reflect.TypeOf(zero).Name()
+}
+
+// column is a top level field of a schema.
+type column struct {
+	name   string
+	index  int  // position in the schema
+	nested bool // holds nested fields, not storable in a Spanner column
+}
+
+func columnsOf[T any]() ([]column, error) {
+	s, ok := named.SchemaOf[T]()
+	if !ok {
+		return nil, named.ErrSchemaNotLoaded
+	}
+
+	var columns []column
+	for i, f := range s.Fields {
+		if len(f.WirePath) == 1 {
+			nested := i+1 < len(s.Fields) && len(s.Fields[i+1].WirePath) > 1
+			columns = append(columns, column{name: f.WirePath[0], index: i, nested: nested})
+		}
+	}
+	return columns, nil
+}
+
+// Columns returns the column names of T (its top level fields not holding
+// nested fields) in schema order, nil if T was not registered with
+// named.LoadLink.
+func Columns[T any]() []string {
+	columns, err := columnsOf[T]()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, c := range columns {
+		if !c.nested {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// StructFields returns the names of the nested fields of the field of T named
+// column, as fields of a STRUCT, nil if column does not hold nested fields.
+func StructFields[T any](column string) []string {
+	s, ok := named.SchemaOf[T]()
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, f := range s.Fields {
+		if len(f.WirePath) == 2 && f.WirePath[0] == column {
+			names = append(names, f.WirePath[1])
+		}
+	}
+	return names
+}
+
+// Mutation returns the table, columns and values of s to be given to
+// spanner.Insert, spanner.Update, spanner.InsertOrUpdate or spanner.Replace,
+// in schema order. Only the columns in only are returned when given (the primary key columns
+// must be included for updates); unknown columns are rejected with an error
+// wrapping named.ErrUnknownPath. Fields holding nested fields are rejected with
+// an error wrapping ErrStructColumn, unless left out by only.
+func Mutation[T any](s *T, only ...string) (string, []string, []any, error) {
+	columns, err := columnsOf[T]()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	for _, name := range only {
+		if !slices.ContainsFunc(columns, func(c column) bool { return c.name == name }) {
+			return "", nil, nil, &named.FieldError{Path: strings.Split(name, named.DefaultFullNameSeparator), Err: named.ErrUnknownPath}
+		}
+	}
+
+	fieldValues := named.FieldValues(s)
+
+	var (
+		names  []string
+		values []any
+	)
+	for _, c := range columns {
+		if len(only) > 0 && !slices.Contains(only, c.name) {
+			continue
+		}
+		if c.nested {
+			return "", nil, nil, &named.FieldError{Path: []string{c.name}, Err: ErrStructColumn}
+		}
+		names = append(names, c.name)
+		values = append(values, reflect.ValueOf(fieldValues[c.index]).Elem().Interface())
+	}
+	return TableName[T](), names, values, nil
+}
@@ -0,0 +1,93 @@
+package spannerutil
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/alvarolm/named"
+)
+
+type Location struct {
+	City    named.Field[string] `spanner:"City"`
+	Country named.Field[string] `spanner:"Country"`
+}
+
+type Singers struct {
+	SingerID named.Field[int64]    `spanner:"SingerId"`
+	Name     named.Field[string]   `spanner:"FullName"`
+	Location named.Field[Location] `spanner:"Location"`
+}
+
+type album struct {
+	AlbumID named.Field[int64] `spanner:"AlbumId"`
+}
+
+func (album) TableName() string { return "Albums" }
+
+func init() {
+	named.LoadLink[Singers](TagKey)
+	named.LoadLink[album](TagKey)
+}
+
+func TestMutation(t *testing.T) {
+	s := Singers{}
+	s.SingerID.Value = 1
+	s.Name.Value = "Marc"
+
+	table, columns, values, err := Mutation(&s, "SingerId", "FullName")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if table != "Singers" || !slices.Equal(columns, []string{"SingerId", "FullName"}) {
+		t.Errorf("Unexpected table %q and columns %v", table, columns)
+	}
+	if !reflect.DeepEqual(values, []any{int64(1), "Marc"}) {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	// Spanner tables cannot have STRUCT columns
+	var fieldErr *named.FieldError
+	if _, _, _, err := Mutation(&s); !errors.Is(err, ErrStructColumn) || !errors.As(err, &fieldErr) || fieldErr.Path[0] != "Location" {
+		t.Errorf("Expected ErrStructColumn for Location, got %v", err)
+	}
+	if _, _, _, err := Mutation(&s, "Location"); !errors.Is(err, ErrStructColumn) {
+		t.Errorf("Expected ErrStructColumn, got %v", err)
+	}
+
+	a := album{}
+	a.AlbumID.Value = 2
+	if table, columns, values, err := Mutation(&a); err != nil || table != "Albums" || !slices.Equal(columns, []string{"AlbumId"}) || !reflect.DeepEqual(values, []any{int64(2)}) {
+		t.Errorf("Unexpected table %q, columns %v, values %v, error %v", table, columns, values, err)
+	}
+
+	_, columns, values, err = Mutation(&s, "FullName", "SingerId")
+	if err != nil || !slices.Equal(columns, []string{"SingerId", "FullName"}) || len(values) != 2 {
+		t.Errorf("Unexpected columns %v, values %v, error %v", columns, values, err)
+	}
+
+	if _, _, _, err := Mutation(&s, "City"); !errors.Is(err, named.ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+
+	type unknown struct{}
+	if _, _, _, err := Mutation(&unknown{}); !errors.Is(err, named.ErrSchemaNotLoaded) {
+		t.Errorf("Expected ErrSchemaNotLoaded, got %v", err)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	if TableName[album]() != "Albums" || !slices.Equal(Columns[album](), []string{"AlbumId"}) {
+		t.Errorf("Unexpected table %q and columns %v", TableName[album](), Columns[album]())
+	}
+	if columns := Columns[Singers](); !slices.Equal(columns, []string{"SingerId", "FullName"}) {
+		t.Errorf("Expected the STRUCT field to be left out, got %v", columns)
+	}
+	if fields := StructFields[Singers]("Location"); !slices.Equal(fields, []string{"City", "Country"}) {
+		t.Errorf("Unexpected STRUCT fields %v", fields)
+	}
+	if StructFields[Singers]("FullName") != nil {
+		t.Error("Expected no STRUCT fields for a scalar column")
+	}
+}
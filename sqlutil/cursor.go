@@ -0,0 +1,159 @@
+package sqlutil
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/alvarolm/named"
+)
+
+// ErrInvalidCursor is returned for malformed, tampered or foreign cursors.
+var ErrInvalidCursor = errors.New("sqlutil: invalid cursor")
+
+// Cursor is a keyset pagination position: the values of the sort columns of
+// the last row of a page, by column name.
+type Cursor map[string]any
+
+// CursorOf returns the cursor of s (typically the last row of a page) for
+// columns, columns of T. Unknown columns are rejected with ErrUnknownColumn.
+func CursorOf[T any](s *T, columns ...string) (Cursor, error) {
+	all := Columns[T]()
+	if all == nil {
+		return nil, named.ErrSchemaNotLoaded
+	}
+	values := named.FieldValues(s)
+
+	c := make(Cursor, len(columns))
+	for _, column := range columns {
+		i := slices.Index(all, column)
+		if i == -1 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, column)
+		}
+		c[column] = reflect.ValueOf(values[i]).Elem().Interface()
+	}
+	return c, nil
+}
+
+// EncodeCursor encodes c as an opaque URL safe token, signed with key
+// (HMAC-SHA256) unless key is empty.
+func EncodeCursor(c Cursor, key []byte) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(data)
+	if len(key) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(sign(data, key))
+	}
+	return token, nil
+}
+
+func sign(data, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// DecodeCursor decodes a token built with EncodeCursor, verifying its
+// signature with key (unless key is empty) and that every column belongs to T.
+// Integers are decoded as int64, other numbers as float64.
+// Returns an error wrapping ErrInvalidCursor otherwise.
+func DecodeCursor[T any](token string, key []byte) (Cursor, error) {
+	columns, err := columnSet[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, signature, signed := strings.Cut(token, ".")
+	if signed != (len(key) > 0) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if signed {
+		mac, err := base64.RawURLEncoding.DecodeString(signature)
+		if err != nil || !hmac.Equal(mac, sign(data, key)) {
+			return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+		}
+	}
+
+	var c Cursor
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	for column, v := range c {
+		if !columns[column] {
+			return nil, fmt.Errorf("%w: %w: %q", ErrInvalidCursor, ErrUnknownColumn, column)
+		}
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				c[column] = i
+			} else if f, err := n.Float64(); err == nil {
+				c[column] = f
+			}
+		}
+	}
+	return c, nil
+}
+
+// After renders the keyset condition selecting the rows following c when
+// sorted ascending by columns, e.g. "(created_at, id) > (?, ?)", and its
+// arguments. Use Before for a descending order.
+// Columns missing from the cursor are rejected with ErrInvalidCursor.
+func After[T any](c Cursor, columns []string, opts ...Option) (string, []any, error) {
+	return keyset[T](c, columns, ">", opts)
+}
+
+// Before renders the keyset condition selecting the rows preceding c when
+// sorted ascending by columns (the following ones in descending order).
+func Before[T any](c Cursor, columns []string, opts ...Option) (string, []any, error) {
+	return keyset[T](c, columns, "<", opts)
+}
+
+func keyset[T any](c Cursor, columns []string, op string, opts []Option) (string, []any, error) {
+	known, err := columnSet[T]()
+	if err != nil {
+		return "", nil, err
+	}
+	if len(columns) == 0 {
+		return "", nil, fmt.Errorf("%w: no columns", ErrInvalidCursor)
+	}
+
+	cfg := newConfig(opts)
+
+	var (
+		placeholders = make([]string, len(columns))
+		args         []any
+	)
+	for i, column := range columns {
+		if !known[column] {
+			return "", nil, fmt.Errorf("%w: %q", ErrUnknownColumn, column)
+		}
+		v, ok := c[column]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: missing %q", ErrInvalidCursor, column)
+		}
+		placeholders[i] = cfg.bind(&args, v)
+	}
+
+	if len(columns) == 1 {
+		return columns[0] + " " + op + " " + placeholders[0], args, nil
+	}
+	return "(" + strings.Join(columns, ", ") + ") " + op + " (" + strings.Join(placeholders, ", ") + ")", args, nil
+}
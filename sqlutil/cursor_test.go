@@ -0,0 +1,83 @@
+package sqlutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	key := []byte("secret")
+
+	u := user{}
+	u.ID.Value = 42
+	u.Name.Value = "bob"
+
+	c, err := CursorOf(&u, "name", "user_id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	token, err := EncodeCursor(c, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded, err := DecodeCursor[user](token, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := (Cursor{"name": "bob", "user_id": int64(42)}); !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Expected %v, got %v", expected, decoded)
+	}
+
+	where, args, err := After[user](decoded, []string{"name", "user_id"}, WithPlaceholder(Dollar))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if where != "(name, user_id) > ($1, $2)" || !reflect.DeepEqual(args, []any{"bob", int64(42)}) {
+		t.Errorf("Unexpected condition %q %v", where, args)
+	}
+
+	if where, _, _ := Before[user](decoded, []string{"user_id"}); where != "user_id < ?" {
+		t.Errorf("Unexpected condition %q", where)
+	}
+}
+
+func TestCursor_Errors(t *testing.T) {
+	key := []byte("secret")
+
+	token, _ := EncodeCursor(Cursor{"age": 1}, key)
+	unsigned, _ := EncodeCursor(Cursor{"age": 1}, nil)
+	foreign, _ := EncodeCursor(Cursor{"password": "x"}, key)
+
+	for _, tt := range []struct {
+		token string
+		key   []byte
+	}{
+		{token, []byte("other")},
+		{token[:len(token)-2], key},
+		{unsigned, key},
+		{token, nil},
+		{foreign, key},
+		{"!!", nil},
+	} {
+		if _, err := DecodeCursor[user](tt.token, tt.key); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q): Expected ErrInvalidCursor, got %v", tt.token, err)
+		}
+	}
+
+	if _, err := DecodeCursor[user](unsigned, nil); err != nil {
+		t.Errorf("Unexpected error for an unsigned cursor: %v", err)
+	}
+
+	if _, _, err := After[user](Cursor{"age": 1}, []string{"age", "name"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for a missing column, got %v", err)
+	}
+	if _, _, err := After[user](Cursor{"x": 1}, []string{"x"}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+	if _, err := CursorOf(&user{}, "x"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Package sqlutil builds SQL fragments from the field names of types
+// registered with named.LoadLink (typically using the "db" tag key), rejecting
+// any column that is not part of the schema so user input never reaches the
+// query text.
+package sqlutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alvarolm/named"
+)
+
+// ErrUnknownColumn is returned for columns that are not a field of the type.
+var ErrUnknownColumn = errors.New("sqlutil: unknown column")
+
+// ErrInvalidOperator is returned for unsupported condition operators.
+var ErrInvalidOperator = errors.New("sqlutil: invalid operator")
+
+// Placeholder selects how bind parameters are rendered.
+type Placeholder uint8
+
+const (
+	// Question renders ? (MySQL, SQLite)
+	Question Placeholder = iota
+	// Dollar renders $1, $2, ... (PostgreSQL)
+	Dollar
+)
+
+type config struct {
+	placeholder Placeholder
+	argOffset   int
+}
+
+// Option configures the rendering of a fragment.
+type Option func(*config)
+
+// WithPlaceholder selects the bind parameter style, Question by default.
+func WithPlaceholder(p Placeholder) Option {
+	return func(c *config) {
+		c.placeholder = p
+	}
+}
+
+// WithArgOffset numbers Dollar placeholders after n already bound arguments,
+// to combine the fragment with others.
+func WithArgOffset(n int) Option {
+	return func(c *config) {
+		c.argOffset = n
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// bind appends v to args and returns its placeholder.
+func (c *config) bind(args *[]any, v any) string {
+	*args = append(*args, v)
+	if c.placeholder == Dollar {
+		return "$" + strconv.Itoa(c.argOffset+len(*args))
+	}
+	return "?"
+}
+
+// Columns returns the column names of T in schema order, nil if T was not
+// registered with named.LoadLink.
+// Columns are named after the tag key, ignoring the names set with the named tag.
+func Columns[T any]() []string {
+	s, ok := named.SchemaOf[T]()
+	if !ok {
+		return nil
+	}
+
+	columns := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		columns[i] = strings.Join(f.WirePath, named.DefaultFullNameSeparator)
+	}
+	return columns
+}
+
+// columnSet returns the set of column names of T.
+func columnSet[T any]() (map[string]bool, error) {
+	paths := Columns[T]()
+	if paths == nil {
+		return nil, named.ErrSchemaNotLoaded
+	}
+
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p] = true
+	}
+	return set, nil
+}
+
+var whereOperators = []string{"=", "!=", "<>", "<", "<=", ">", ">=", "LIKE", "NOT LIKE", "IN", "NOT IN", "IS", "IS NOT"}
+
+// Where renders the conditions in conds, joined with AND, as a parameterized
+// SQL expression (without the WHERE keyword) and its arguments.
+//
+// Keys are a column name optionally followed by an operator ("age >=", "name LIKE"),
+// "=" when omitted. A nil value renders IS NULL (IS NOT NULL for "!="), and a slice
+// value with IN / NOT IN renders one placeholder per element.
+// Conditions are rendered sorted by key so the output is deterministic.
+// Unknown columns are rejected with ErrUnknownColumn.
+func Where[T any](conds map[string]any, opts ...Option) (string, []any, error) {
+	columns, err := columnSet[T]()
+	if err != nil {
+		return "", nil, err
+	}
+
+	c := newConfig(opts)
+
+	keys := make([]string, 0, len(conds))
+	for k := range conds {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var (
+		b    strings.Builder
+		args []any
+	)
+
+	for i, key := range keys {
+		column, op, _ := strings.Cut(strings.TrimSpace(key), " ")
+		op = strings.ToUpper(strings.Join(strings.Fields(op), " "))
+		if op == "" {
+			op = "="
+		}
+
+		if !columns[column] {
+			return "", nil, fmt.Errorf("%w: %q", ErrUnknownColumn, column)
+		}
+		if !slices.Contains(whereOperators, op) {
+			return "", nil, fmt.Errorf("%w: %q", ErrInvalidOperator, op)
+		}
+
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+
+		value := conds[key]
+
+		switch {
+		case value == nil && (op == "=" || op == "IS"):
+			b.WriteString(column + " IS NULL")
+		case value == nil && (op == "!=" || op == "<>" || op == "IS NOT"):
+			b.WriteString(column + " IS NOT NULL")
+		case op == "IN" || op == "NOT IN":
+			rv := reflect.ValueOf(value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return "", nil, fmt.Errorf("%w: %s requires a slice value for %q", ErrInvalidOperator, op, column)
+			}
+			if rv.Len() == 0 {
+				// nothing is IN an empty set
+				if op == "IN" {
+					b.WriteString("1=0")
+				} else {
+					b.WriteString("1=1")
+				}
+				continue
+			}
+			b.WriteString(column + " " + op + " (")
+			for j := range rv.Len() {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(c.bind(&args, rv.Index(j).Interface()))
+			}
+			b.WriteByte(')')
+		default:
+			b.WriteString(column + " " + op + " " + c.bind(&args, value))
+		}
+	}
+
+	return b.String(), args, nil
+}
+
+// FieldRef is implemented by linked named.Field and named.FieldSlice members.
+type FieldRef interface {
+	Path() []string
+}
+
+// OrderBy renders the ORDER BY expression (without the ORDER BY keywords) of
+// a sort parameter such as "-created_at,name": comma separated column names
+// of T, each optionally prefixed with "-" for a descending order ("+" or no
+// prefix for ascending). When allowed is given, only the columns of those
+// fields (members of a linked T, e.g. &u.CreatedAt) can be used.
+// An empty input renders an empty string. Unknown or not allowed columns are
+// rejected with ErrUnknownColumn, so the input never reaches the query text.
+func OrderBy[T any](input string, allowed ...FieldRef) (string, error) {
+	s, ok := named.SchemaOf[T]()
+	if !ok {
+		return "", named.ErrSchemaNotLoaded
+	}
+
+	columns := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		column := strings.Join(f.WirePath, named.DefaultFullNameSeparator)
+		columns[column] = len(allowed) == 0 || slices.ContainsFunc(allowed, func(ref FieldRef) bool {
+			return slices.Equal(ref.Path(), f.Path)
+		})
+	}
+
+	if strings.TrimSpace(input) == "" {
+		return "", nil
+	}
+
+	var b strings.Builder
+	for i, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+
+		dir := " ASC"
+		switch {
+		case strings.HasPrefix(item, "-"):
+			dir = " DESC"
+			item = item[1:]
+		case strings.HasPrefix(item, "+"):
+			item = item[1:]
+		}
+
+		if !columns[item] {
+			return "", fmt.Errorf("%w: %q", ErrUnknownColumn, item)
+		}
+
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(item + dir)
+	}
+	return b.String(), nil
+}
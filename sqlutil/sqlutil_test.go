@@ -0,0 +1,123 @@
+package sqlutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/alvarolm/named"
+)
+
+type user struct {
+	ID     named.Field[int]    `db:"user_id"`
+	Name   named.Field[string] `db:"name"`
+	Age    named.Field[int]    `db:"age"`
+	Active named.Field[bool]   `db:"is_active"`
+	Secret named.Field[string] `db:"-"`
+}
+
+func init() {
+	named.LoadLink[user]("db")
+}
+
+func TestColumns(t *testing.T) {
+	expected := []string{"user_id", "name", "age", "is_active"}
+	if got := Columns[user](); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		conds        map[string]any
+		opts         []Option
+		expected     string
+		expectedArgs []any
+	}{
+		{
+			map[string]any{"is_active": true, "age >=": 18},
+			nil,
+			"age >= ? AND is_active = ?",
+			[]any{18, true},
+		},
+		{
+			map[string]any{"name like": "a%", "user_id IN": []int{1, 2}},
+			[]Option{WithPlaceholder(Dollar), WithArgOffset(1)},
+			"name LIKE $2 AND user_id IN ($3, $4)",
+			[]any{"a%", 1, 2},
+		},
+		{
+			map[string]any{"name": nil, "age !=": nil, "user_id NOT IN": []int{}},
+			nil,
+			"age IS NOT NULL AND name IS NULL AND 1=1",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		clause, args, err := Where[user](tt.conds, tt.opts...)
+		if err != nil {
+			t.Fatalf("Where(%v): Unexpected error: %v", tt.conds, err)
+		}
+		if clause != tt.expected {
+			t.Errorf("Where(%v): Expected %q, got %q", tt.conds, tt.expected, clause)
+		}
+		if !reflect.DeepEqual(args, tt.expectedArgs) {
+			t.Errorf("Where(%v): Expected args %v, got %v", tt.conds, tt.expectedArgs, args)
+		}
+	}
+}
+
+func TestWhere_Errors(t *testing.T) {
+	tests := []struct {
+		conds map[string]any
+		err   error
+	}{
+		{map[string]any{"password": "x"}, ErrUnknownColumn},
+		{map[string]any{"age; DROP TABLE users": 1}, ErrUnknownColumn},
+		{map[string]any{"age OR 1=1": 1}, ErrInvalidOperator},
+		{map[string]any{"age IN": 1}, ErrInvalidOperator},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := Where[user](tt.conds); !errors.Is(err, tt.err) {
+			t.Errorf("Where(%v): Expected %v, got %v", tt.conds, tt.err, err)
+		}
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"name", "name ASC"},
+		{"-age, +name,user_id", "age DESC, name ASC, user_id ASC"},
+	}
+
+	for _, tt := range tests {
+		got, err := OrderBy[user](tt.input)
+		if err != nil {
+			t.Fatalf("OrderBy(%q): Unexpected error: %v", tt.input, err)
+		}
+		if got != tt.expected {
+			t.Errorf("OrderBy(%q): Expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	for _, input := range []string{"name;DROP TABLE users", "-", "name,", "Secret", "name DESC"} {
+		if _, err := OrderBy[user](input); !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("OrderBy(%q): Expected ErrUnknownColumn, got %v", input, err)
+		}
+	}
+
+	u := user{}
+	named.Link(&u)
+	if got, err := OrderBy[user]("-age", &u.Age, &u.Name); err != nil || got != "age DESC" {
+		t.Errorf("Unexpected result %q, %v", got, err)
+	}
+	if _, err := OrderBy[user]("user_id", &u.Age); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn for a column not allowed, got %v", err)
+	}
+}
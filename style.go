@@ -0,0 +1,74 @@
+package named
+
+import "strings"
+
+// FullNameStyle selects how FullNameAs renders a hierarchical path.
+type FullNameStyle uint8
+
+const (
+	// StyleDefault uses the default style of the field's schema (see WithFullNameStyle),
+	// StyleDotted if none was set.
+	StyleDefault FullNameStyle = iota
+	// StyleDotted renders y.a
+	StyleDotted
+	// StyleBracketed renders y[a]
+	StyleBracketed
+	// StyleSQL renders "y"."a", quoting each element as an SQL identifier.
+	StyleSQL
+	// StyleSnake renders y_a
+	StyleSnake
+)
+
+func fieldFullNameStyleOp(pathPtr, parentPathPtr *[]string, style FullNameStyle) string {
+	if pathPtr == nil {
+		return ""
+	}
+
+	if style == StyleDefault {
+		if sch := pathSchema(pathPtr); sch != nil {
+			style = sch.style
+		}
+	}
+
+	switch style {
+	case StyleBracketed:
+		return formatBracketed(getCombinedPath(pathPtr, parentPathPtr))
+	case StyleSQL:
+		return formatSQL(getCombinedPath(pathPtr, parentPathPtr))
+	case StyleSnake:
+		return fieldFullNameOp(pathPtr, parentPathPtr, "_")
+	default:
+		return fieldFullNameOp(pathPtr, parentPathPtr, ".")
+	}
+}
+
+func formatBracketed(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	n := stringJoinRawSize(path, "") + 2*(len(path)-1)
+
+	var b strings.Builder
+	b.Grow(n)
+	b.WriteString(path[0])
+	for _, elem := range path[1:] {
+		b.WriteByte('[')
+		b.WriteString(elem)
+		b.WriteByte(']')
+	}
+	return b.String()
+}
+
+func formatSQL(path []string) string {
+	var b strings.Builder
+	for i, elem := range path {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteByte('"')
+		b.WriteString(strings.ReplaceAll(elem, `"`, `""`))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
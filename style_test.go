@@ -0,0 +1,158 @@
+package named
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestField_FullNameAs(t *testing.T) {
+	type Inner struct {
+		A Field[int] `json:"a"`
+	}
+	type Outer struct {
+		Y Field[Inner] `json:"y"`
+	}
+
+	LoadLink[Outer]("json")
+
+	s := Outer{}
+	Link(&s)
+
+	tests := []struct {
+		style    FullNameStyle
+		expected string
+	}{
+		{StyleDefault, "y.a"},
+		{StyleDotted, "y.a"},
+		{StyleBracketed, "y[a]"},
+		{StyleSQL, `"y"."a"`},
+		{StyleSnake, "y_a"},
+	}
+
+	for _, tt := range tests {
+		if got := s.Y.Value.A.FullNameAs(tt.style); got != tt.expected {
+			t.Errorf("FullNameAs(%d): Expected %q, got %q", tt.style, tt.expected, got)
+		}
+	}
+
+	t.Run("SchemaDefault", func(t *testing.T) {
+		type Styled struct {
+			Y Field[Inner] `json:"y"`
+		}
+
+		if err := LoadLink[Styled]("json", WithFullNameStyle(StyleBracketed)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		s := Styled{}
+		Link(&s)
+
+		if got := s.Y.Value.A.FullNameAs(StyleDefault); got != "y[a]" {
+			t.Errorf("Expected schema default 'y[a]', got %q", got)
+		}
+		if got := s.Y.Value.A.FullNameAs(StyleSnake); got != "y_a" {
+			t.Errorf("Expected per call style 'y_a', got %q", got)
+		}
+	})
+
+	t.Run("WithParentPath", func(t *testing.T) {
+		s := Inner{}
+		parent := []string{"root"}
+		LoadLink[Inner]("json")
+		LinkWithPath(&s, &parent)
+
+		if got := s.A.FullNameAs(StyleBracketed); got != "root[a]" {
+			t.Errorf("Expected 'root[a]', got %q", got)
+		}
+	})
+}
+
+func TestField_FullName_DefaultSeparator(t *testing.T) {
+	type Inner struct {
+		A Field[int] `json:"a"`
+	}
+	type Global struct {
+		Y Field[Inner] `json:"y"`
+	}
+	type PerType struct {
+		Y Field[Inner] `json:"y"`
+	}
+
+	LoadLink[Global]("json")
+	LoadLink[PerType]("json", WithSeparator("/"))
+
+	g := Global{}
+	Link(&g)
+	p := PerType{}
+	Link(&p)
+
+	if got := p.Y.Value.A.FullName(""); got != "y/a" {
+		t.Errorf("Expected per type separator 'y/a', got %q", got)
+	}
+	if got := p.Y.Value.A.FullName("-"); got != "y-a" {
+		t.Errorf("Expected explicit separator 'y-a', got %q", got)
+	}
+
+	SetDefaultSeparator("::")
+	defer SetDefaultSeparator("")
+
+	if got := g.Y.Value.A.FullName(""); got != "y::a" {
+		t.Errorf("Expected global separator 'y::a', got %q", got)
+	}
+	if got := p.Y.Value.A.FullName(""); got != "y/a" {
+		t.Errorf("Expected per type separator to win over the global one, got %q", got)
+	}
+}
+
+func TestPathHelpers_Separator(t *testing.T) {
+	type Inner struct {
+		A Field[int] `json:"a"`
+		B Field[int] `json:"b"`
+	}
+	type Slashed struct {
+		ID Field[int]   `json:"id"`
+		Y  Field[Inner] `json:"y"`
+	}
+
+	LoadLink[Slashed]("json", WithSeparator("/"))
+
+	if got := FieldPaths[Slashed](); !slices.Equal(got, []string{"id", "y", "y/a", "y/b"}) {
+		t.Errorf("Expected paths joined with the separator of the type, got %v", got)
+	}
+
+	s := Slashed{}
+	s.Y.Value.A.Value = 1
+	s.Y.Value.B.Value = 2
+
+	data, err := MarshalOnly(&s, "y/a")
+	if err != nil || string(data) != `{"y":{"a":1}}` {
+		t.Errorf("Expected only y/a, got %s, %v", data, err)
+	}
+	if _, err := MarshalOnly(&s, "y.a"); !errors.Is(err, ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath for a dotted path, got %v", err)
+	}
+	if key, err := CacheKey(&s, "y/b"); err != nil || key != "slashed:y/b=2" {
+		t.Errorf("Expected 'slashed:y/b=2', got %q, %v", key, err)
+	}
+	if p := Project(&s, "y/b"); p.Y.Value.B.Value != 2 || p.Y.Value.A.Value != 0 {
+		t.Errorf("Expected only y/b to be projected, got %+v", p)
+	}
+
+	d := Slashed{}
+	Link(&d)
+	present, err := DecodePatch(strings.NewReader(`{"y":{"a":3}}`), &d)
+	if err != nil || !slices.Equal(present, []string{"y", "y/a"}) {
+		t.Fatalf("Expected present paths [y y/a], got %v, %v", present, err)
+	}
+	if set := Presence(&d); !set.Has("y/a") || !set.HasField(&d.Y.Value.A) || set.HasField(&d.Y.Value.B) {
+		t.Errorf("Expected presence keyed by y/a, got %v", set)
+	}
+
+	old := Slashed{}
+	entries := AuditChanges("bob", &old, &s)
+	if len(entries) == 0 || entries[0].Path != "y/a" {
+		t.Errorf("Expected audit paths joined with the separator of the type, got %+v", entries)
+	}
+}
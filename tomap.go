@@ -0,0 +1,33 @@
+package named
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ToMap returns the Field members of s as a map keyed by their names for
+// tagKey (e.g. "json" for responses, "db" for persistence). Fields holding
+// nested fields become nested maps, other fields hold their Value.
+// T must be registered with LoadLink for tagKey.
+func ToMap[T any](s *T, tagKey string) (map[string]any, error) {
+	sch, ok := loadTagSchema[T](tagKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: tag key %q", ErrSchemaNotLoaded, tagKey)
+	}
+	return sch.toMap(unsafe.Pointer(s), 0, len(sch.fields), 0), nil
+}
+
+func (sch *schema) toMap(ptr unsafe.Pointer, start, end, depth int) map[string]any {
+	m := make(map[string]any)
+	for i := start; i < end; i = sch.subtreeEnd(i) {
+		field := &sch.fields[i]
+		name := field.wirePath[depth]
+
+		if sub := sch.subtreeEnd(i); sub > i+1 {
+			m[name] = sch.toMap(ptr, i+1, sub, depth+1)
+			continue
+		}
+		m[name] = field.value(ptr).Interface()
+	}
+	return m
+}
@@ -0,0 +1,36 @@
+package named
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	s := sampleConvert{}
+	s.ID.Value = 1
+	s.Password.Value = "x"
+	s.Address.Value.City.Value = "y"
+
+	tests := []struct {
+		tagKey   string
+		expected map[string]any
+	}{
+		{"json", map[string]any{"id": 1, "password": "x", "address": map[string]any{"city": "y"}}},
+		{"db", map[string]any{"user_id": 1, "addr": map[string]any{"city_name": "y"}}},
+	}
+
+	for _, tt := range tests {
+		m, err := ToMap(&s, tt.tagKey)
+		if err != nil {
+			t.Fatalf("ToMap(%q): Unexpected error: %v", tt.tagKey, err)
+		}
+		if !reflect.DeepEqual(m, tt.expected) {
+			t.Errorf("ToMap(%q): Expected %v, got %v", tt.tagKey, tt.expected, m)
+		}
+	}
+
+	if _, err := ToMap(&s, "yaml"); !errors.Is(err, ErrSchemaNotLoaded) {
+		t.Errorf("Expected ErrSchemaNotLoaded, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package named
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// VerifyLinked returns the Go selectors (e.g. "B.Value.A") of the exported
+// Field members of s whose path is still nil, typically because s was not
+// linked or its schema does not match the current struct definition.
+// Fields skipped on purpose with the tag name "-" (for the tag key T was
+// registered with, or the named tag) are not reported. Returns nil when every field is linked.
+func VerifyLinked[T any](s *T) []string {
+	tVal := reflect.TypeOf(s).Elem()
+	if tVal.Kind() != reflect.Struct {
+		return nil
+	}
+
+	tagKey := ""
+	if sch, ok := loadSchema[T](); ok {
+		tagKey = sch.TagKey
+	}
+
+	var missing []string
+	verifyLinked(tVal, unsafe.Pointer(s), tagKey, "", &missing)
+	return missing
+}
+
+func verifyLinked(tVal reflect.Type, ptr unsafe.Pointer, tagKey, prefix string, missing *[]string) {
+	for i := 0; i < tVal.NumField(); i++ {
+		field := tVal.Field(i)
+
+		if !field.IsExported() || !isFieldType(field.Type) {
+			continue
+		}
+
+		if tagKey != "" && strings.Split(field.Tag.Get(tagKey), ",")[0] == "-" ||
+			strings.Split(field.Tag.Get(NamedTagKey), ",")[0] == "-" {
+			continue
+		}
+
+		fieldPtr := unsafe.Add(ptr, field.Offset)
+		if (*fieldHeader)(fieldPtr).path == nil {
+			*missing = append(*missing, prefix+field.Name)
+		}
+
+		if field.Type.NumField() >= 3 {
+			valueField := field.Type.Field(2) // Value is at index 2
+			if valueField.Name == "Value" && valueField.Type.Kind() == reflect.Struct {
+				verifyLinked(valueField.Type, unsafe.Add(fieldPtr, valueField.Offset), tagKey, prefix+field.Name+".Value.", missing)
+			}
+		}
+	}
+}
+
+// FieldName describes a field by its Go names and name, as the <Struct>FieldName
+// types listed by the All methods generated by generate-named with the Listing
+// option, which convert to it.
+type FieldName struct {
+	GoName string // Go names of the field and its parents, e.g. "Address.City"
+	Name   string // dotted path, e.g. "address.city"
+}
+
+// FieldInfo describes a field listed by the <Struct>FieldInfos functions
+// generated by generate-named with the FieldInfos option, e.g. for form
+// builders or admin UIs.
+type FieldInfo struct {
+	GoName  string // as in FieldName
+	Name    string
+	Type    string // Go type as declared, e.g. "*Address" or "[]string"
+	Pointer bool   // the type is a pointer
+	Slice   bool   // the type is a slice, or a pointer to a slice
+}
+
+// ErrGeneratedMismatch is returned by VerifyGenerated for the accessors
+// disagreeing with the runtime schema.
+var ErrGeneratedMismatch = errors.New("named: generated accessor does not match the schema")
+
+// VerifyGenerated checks the accessors generated by generate-named for T (e.g.
+// the UserNamed variable) against the runtime schema of T: every top level
+// field must have an accessor (a method named after its Go name, or a member
+// for nested accessors) returning its name, and every accessor must match a field.
+// The accessors of the other exported members of T (plain members, not
+// Field ones) must return the name of their named tag, or else of their tag
+// key, unless untagged, but they are not required.
+// The unexported fields (see WithIncludeUnexported) are not checked, their
+// accessors being out of reach of reflection.
+// Returns FieldErrors wrapping ErrGeneratedMismatch, identified by Go name.
+func VerifyGenerated[T any](accessors any) error {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return ErrSchemaNotLoaded
+	}
+
+	names := make(map[string]string) // Go name -> name
+	var goNames []string             // in schema order
+	for i := range sch.fields {
+		if field := &sch.fields[i]; len(*field.pathPtr) == 1 && token.IsExported(field.goName) {
+			names[field.goName] = (*field.pathPtr)[0]
+			goNames = append(goNames, field.goName)
+		}
+	}
+	plain := plainNames(sch.goType, sch.TagKey)
+
+	var errs FieldErrors
+	check := func(goName, got string) {
+		name, ok := names[goName]
+		if !ok {
+			if name, ok = plain[goName]; ok && name == "" {
+				return // untagged, named by the options of the generator
+			}
+		}
+		switch {
+		case !ok:
+			errs = append(errs, &FieldError{Path: []string{goName}, Err: fmt.Errorf("%w: no such field", ErrGeneratedMismatch)})
+		case got != name:
+			errs = append(errs, &FieldError{Path: []string{goName}, Err: fmt.Errorf("%w: returns %q instead of %q", ErrGeneratedMismatch, got, name)})
+		}
+		delete(names, goName)
+	}
+
+	v := reflect.ValueOf(accessors)
+	for i := 0; i < v.NumMethod(); i++ {
+		if fn, ok := v.Method(i).Interface().(func() string); ok {
+			check(v.Type().Method(i).Name, fn())
+		}
+	}
+
+	// nested accessors are members with a String method returning their path
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			if member := v.Type().Field(i); member.IsExported() {
+				if s, ok := v.Field(i).Interface().(fmt.Stringer); ok {
+					check(member.Name, s.String())
+				}
+			}
+		}
+	}
+
+	for _, goName := range goNames {
+		if name, ok := names[goName]; ok {
+			errs = append(errs, &FieldError{Path: []string{goName}, Err: fmt.Errorf("%w: no accessor for %q", ErrGeneratedMismatch, name)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// plainNames returns the names of the exported top level members of t that
+// are not Field members, by Go name: the name of their named tag, or else of
+// their tagKey tag, empty if untagged. Members skipped with "-" are left out.
+func plainNames(t reflect.Type, tagKey string) map[string]string {
+	names := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Anonymous || isFieldType(field.Type) {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get(NamedTagKey), ",")[0]
+		if name == "" {
+			name = strings.Split(field.Tag.Get(tagKey), ",")[0]
+		}
+		if name != "-" {
+			names[field.Name] = name
+		}
+	}
+	return names
+}
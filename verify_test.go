@@ -0,0 +1,91 @@
+package named
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyLinked(t *testing.T) {
+	s := SampleEmbedStruct{}
+
+	missing := VerifyLinked(&s)
+	if len(missing) == 0 || missing[0] != "A" || missing[1] != "B" || missing[2] != "B.Value.A" {
+		t.Errorf("Expected unlinked fields to be reported, got %v", missing)
+	}
+
+	// L is skipped with json:"-" and m is unexported
+	for _, m := range missing {
+		if strings.HasSuffix(m, "L") || strings.HasSuffix(m, "m") {
+			t.Errorf("Expected %s not to be reported", m)
+		}
+	}
+
+	Link(&s)
+
+	if missing := VerifyLinked(&s); missing != nil {
+		t.Errorf("Expected no unlinked fields after Link, got %v", missing)
+	}
+}
+
+type verifyGenerated struct {
+	Email Field[string] `json:"email"`
+	Age   Field[int]    `json:"age"`
+	Phone Field[string] `json:"phone"`
+}
+
+type verifyGeneratedNamed struct {
+	Phone verifyGeneratedNamedPhone
+}
+
+type verifyGeneratedNamedPhone struct{}
+
+func (verifyGeneratedNamedPhone) String() string { return "mobile" }
+
+func (verifyGeneratedNamed) Email() string { return "email" }
+func (verifyGeneratedNamed) Age() string   { return "years" }
+
+func TestVerifyGenerated(t *testing.T) {
+	LoadLink[verifyGenerated]("json")
+
+	err := VerifyGenerated[verifyGenerated](verifyGeneratedNamed{})
+	if !errors.Is(err, ErrGeneratedMismatch) {
+		t.Fatalf("Expected ErrGeneratedMismatch, got %v", err)
+	}
+	if errs := err.(FieldErrors); len(errs) != 2 || errs[0].Path[0] != "Age" || errs[1].Path[0] != "Phone" ||
+		!strings.Contains(errs[1].Error(), `"mobile"`) {
+		t.Errorf("Unexpected errors %v", err)
+	}
+
+	if err := VerifyGenerated[SampleSimple](struct{}{}); err == nil {
+		t.Error("Expected missing accessors to be reported")
+	}
+}
+
+type verifyMixed struct {
+	Email    Field[string] `json:"email"`
+	Name     string        `json:"name"`
+	Nickname string        `json:"nickname" named:"nick"`
+	Notes    string
+	Secret   string `json:"-"`
+}
+
+type verifyMixedNamed struct{}
+
+func (verifyMixedNamed) Email() string    { return "email" }
+func (verifyMixedNamed) Name() string     { return "name" }
+func (verifyMixedNamed) Nickname() string { return "nickname" }
+func (verifyMixedNamed) Notes() string    { return "Notes" }
+func (verifyMixedNamed) Secret() string   { return "secret" }
+
+func TestVerifyGenerated_PlainMembers(t *testing.T) {
+	LoadLink[verifyMixed]("json")
+
+	// Name and Notes match, Nickname is named by its named tag and Secret is skipped
+	err := VerifyGenerated[verifyMixed](verifyMixedNamed{})
+	errs, ok := err.(FieldErrors)
+	if !ok || len(errs) != 2 || errs[0].Path[0] != "Nickname" || !strings.Contains(errs[0].Error(), `"nick"`) ||
+		errs[1].Path[0] != "Secret" || !strings.Contains(errs[1].Error(), "no such field") {
+		t.Errorf("Unexpected errors %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package named
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownView is returned by MarshalView for views not defined with DefineView.
+var ErrUnknownView = errors.New("named: unknown view")
+
+// views holds the views defined with DefineView, keyed by type ID, so they are
+// kept when the schema of the type is built again (LoadLink, RegisterSchema).
+var views = make(map[uintptr]map[string][]string)
+
+// DefineView registers a named, reusable set of field paths of T (e.g. "public")
+// to be used with MarshalView. Defining an existing view replaces it.
+// T must be registered with LoadLink; paths are validated against its schema.
+// Views are kept when T is registered again, paths unknown to the new schema
+// being ignored as by MarshalOnly.
+// not async safe, should be called along with LoadLink.
+func DefineView[T any](name string, paths ...string) error {
+	sch, ok := loadSchema[T]()
+	if !ok {
+		return ErrSchemaNotLoaded
+	}
+
+	if err := sch.checkPaths(paths); err != nil {
+		return fmt.Errorf("view %q: %w", name, err)
+	}
+
+	typeID := typeIDOf[T]()
+	if views[typeID] == nil {
+		views[typeID] = make(map[string][]string)
+	}
+	views[typeID][name] = append([]string(nil), paths...)
+
+	return nil
+}
+
+// MarshalView returns the JSON encoding of the fields of s in the view name,
+// like MarshalOnly does with the view's paths.
+func MarshalView[T any](s *T, name string) ([]byte, error) {
+	if _, ok := loadSchema[T](); !ok {
+		return nil, ErrSchemaNotLoaded
+	}
+
+	paths, ok := views[typeIDOf[T]()][name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownView, name)
+	}
+
+	return marshalSelected(s, paths, true)
+}
@@ -0,0 +1,56 @@
+package named
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMarshalView(t *testing.T) {
+	if err := DefineView[sampleMarshal]("public", "name", "address.city"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := MarshalView(newSampleMarshal(), "public")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"name":"bob","address":{"city":"x"}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	if _, err := MarshalView(newSampleMarshal(), "admin"); !errors.Is(err, ErrUnknownView) {
+		t.Errorf("Expected ErrUnknownView, got %v", err)
+	}
+
+	if err := DefineView[sampleMarshal]("broken", "password"); !errors.Is(err, ErrUnknownPath) {
+		t.Errorf("Expected ErrUnknownPath, got %v", err)
+	}
+}
+
+type sampleView struct {
+	ID   Field[int]    `json:"id"`
+	Name Field[string] `json:"name"`
+}
+
+func TestMarshalView_Reload(t *testing.T) {
+	LoadLink[sampleView]("json")
+	if err := DefineView[sampleView]("public", "name"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the view outlives the schema built again
+	LoadLink[sampleView]("json")
+
+	s := &sampleView{}
+	s.ID.Value = 1
+	s.Name.Value = "bob"
+	data, err := MarshalView(s, "public")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := `{"name":"bob"}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
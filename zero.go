@@ -0,0 +1,30 @@
+package named
+
+// zeroCheckers holds the functions registered with RegisterZeroChecker, keyed by type ID.
+var zeroCheckers = make(map[uintptr]any)
+
+// RegisterZeroChecker registers fn to decide whether values of type T are empty.
+// It is consulted by NoValue and IsZero (and so by the json omitzero option) of
+// Field[T] and FieldSlice[T, E], taking precedence over an IsZero method of T.
+// Useful for domain types you don't control (UUIDs, decimals, protobuf wrappers).
+// A nil fn removes the checker for T.
+// not async safe, should be called before any NoValue / IsZero calls.
+func RegisterZeroChecker[T any](fn func(T) bool) {
+	if fn == nil {
+		delete(zeroCheckers, typeIDOf[T]())
+		return
+	}
+	zeroCheckers[typeIDOf[T]()] = fn
+}
+
+// zeroChecker returns the checker registered for type T.
+func zeroChecker[T any]() (func(T) bool, bool) {
+	if len(zeroCheckers) == 0 {
+		return nil, false
+	}
+	fn, ok := zeroCheckers[typeIDOf[T]()]
+	if !ok {
+		return nil, false
+	}
+	return fn.(func(T) bool), true
+}
@@ -0,0 +1,53 @@
+package named
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type sampleUUID [16]byte
+
+type sampleDecimal struct {
+	units int64
+	exp   int32
+}
+
+func TestRegisterZeroChecker(t *testing.T) {
+	var nilUUID sampleUUID
+	for i := range nilUUID {
+		nilUUID[i] = 0xff // "max" UUID used as a sentinel for empty
+	}
+
+	RegisterZeroChecker(func(u sampleUUID) bool { return u == nilUUID })
+	RegisterZeroChecker(func(d sampleDecimal) bool { return d.units == 0 })
+	defer RegisterZeroChecker[sampleUUID](nil)
+	defer RegisterZeroChecker[sampleDecimal](nil)
+
+	if f := (Field[sampleUUID]{Value: nilUUID}); !f.NoValue() {
+		t.Error("Expected sentinel UUID to be empty")
+	}
+	if f := (Field[sampleUUID]{}); f.NoValue() {
+		t.Error("Expected zero UUID not to be empty for the registered checker")
+	}
+	if f := (Field[sampleDecimal]{Value: sampleDecimal{exp: 2}}); !f.NoValue() {
+		t.Error("Expected decimal without units to be empty")
+	}
+
+	type sample struct {
+		D Field[sampleDecimal] `json:"d,omitzero"`
+		N Field[int]           `json:"n"`
+	}
+
+	data, err := json.Marshal(sample{D: Field[sampleDecimal]{Value: sampleDecimal{exp: 3}}})
+	if err != nil {
+		t.Fatalf("Unexpected error during marshaling: %v", err)
+	}
+	if string(data) != `{"n":0}` {
+		t.Errorf("Expected omitzero to use the registered checker, got %s", data)
+	}
+
+	RegisterZeroChecker[sampleDecimal](nil)
+	if f := (Field[sampleDecimal]{Value: sampleDecimal{exp: 2}}); f.NoValue() {
+		t.Error("Expected checker removal to restore the default comparison")
+	}
+}